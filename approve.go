@@ -0,0 +1,198 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/key"
+	"github.com/welthee/dobermann/transactor"
+)
+
+type CollectStrategy string
+
+var (
+	// CollectStrategyTransfer transfers the tokens from the source accounts to the destination
+	CollectStrategyTransfer CollectStrategy = "transfer"
+	// CollectStrategyApprove only approves the destination to spend the source account tokens,
+	// the tokens are moved later with Pull
+	CollectStrategyApprove CollectStrategy = "approve"
+)
+
+// createCollectTx creates the ERC-20 tx of the configured CollectStrategy
+func (c evmCollector) createCollectTx(ctx context.Context, params transactor.TxParams) (*types.Transaction, error) {
+	if c.strategy == CollectStrategyApprove {
+		return c.transactor.CreateERC20ApproveTx(ctx, params)
+	}
+	return c.transactor.CreateERC20Tx(ctx, params)
+}
+
+func (c evmCollector) Pull(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount) []Result {
+	var results = make([]Result, 0)
+	if len(accounts) == 0 {
+		log.Ctx(ctx).Debug().Msg("no accounts to pull")
+		return results
+	}
+
+	// the node minimum gas price is read once for the transactions of the batch
+	ctx = transactor.WithNodeGasPriceCache(ctx)
+	b := newBatch()
+	destinationErr := validateKeyProvider(destinationAccount.KeyProvider)
+	if destinationErr != nil {
+		log.Ctx(ctx).Warn().Err(destinationErr).Msg("invalid destination account")
+	}
+	defer key.Release(destinationAccount.KeyProvider)
+	screening := c.newRunScreening()
+	var destinationScreenErr error
+	if destinationErr == nil {
+		destinationScreenErr = screening.check(ctx, *destinationAccount.KeyProvider.GetAddress(), ScreeningRoleDestination)
+		if destinationScreenErr != nil {
+			log.Ctx(ctx).Error().Err(destinationScreenErr).Msg("destination screening failed, aborting the pull")
+		}
+	}
+	startBlock := c.blockNumber(ctx)
+
+	for _, account := range accounts {
+		if destinationErr != nil || validateKeyProvider(account.KeyProvider) != nil {
+			results = append(results, handleError(ctx, account, PhaseValidation, ErrNilKeyProvider))
+			continue
+		}
+		if destinationScreenErr != nil {
+			results = append(results, screeningResult(ctx, account, destinationScreenErr))
+			continue
+		}
+		if err := validateAmount(account); err != nil {
+			results = append(results, handleError(ctx, account, PhaseValidation, err))
+			continue
+		}
+		if isSelfCollection(account, destinationAccount) {
+			results = append(results, getResult(ctx, account, StatusSkip, ReasonSelfCollection))
+			continue
+		}
+		if ctx.Err() != nil {
+			results = append(results, getResult(ctx, account, StatusInterrupted, ReasonInterrupted))
+			continue
+		}
+		if err := screening.checkSource(ctx, account); err != nil {
+			results = append(results, screeningResult(ctx, account, err))
+			continue
+		}
+		results = append(results, c.pull(ctx, b, account, destinationAccount))
+	}
+
+	c.setRun(ctx, results, destinationAccount, startBlock)
+	return results
+}
+
+// pull transfers the approved tokens of the account with a transferFrom sent by the destination
+func (c evmCollector) pull(ctx context.Context, b *batch, account SourceAccount, destinationAccount DestinationAccount) (result Result) {
+	holderAddress := tokenHolder(account)
+	destinationAddress := destinationAccount.KeyProvider.GetAddress()
+
+	allowance, err := c.transactor.Allowance(ctx, *holderAddress, *destinationAddress, account.Token)
+	if err != nil {
+		return handleError(ctx, account, PhaseBalanceCheck, err)
+	}
+	if allowance.Sign() == 0 {
+		return getResult(ctx, account, StatusSkip, ReasonZeroAllowance)
+	}
+	tokenBalance, err := c.getTokenBalance(ctx, holderAddress, account)
+	if err != nil {
+		return handleError(ctx, account, PhaseBalanceCheck, err)
+	}
+	if tokenBalance.Sign() == 0 {
+		return getResult(ctx, account, StatusSkip, ReasonZeroBalance)
+	}
+
+	amount := allowance
+	if tokenBalance.Cmp(amount) < 0 {
+		amount = tokenBalance
+	}
+	if account.Amount != "" {
+		requestedAmount, err := parseAmount(account.Amount)
+		if err != nil {
+			return handleError(ctx, account, PhaseBalanceCheck, err)
+		}
+		if amount.Cmp(requestedAmount) < 0 {
+			return handleError(ctx, account, PhaseBalanceCheck, ErrInsufficientAllowance)
+		}
+		amount = requestedAmount
+	}
+
+	gasTipCapValue, gasFeeCapValue, err := c.transactor.GetGasCapValues(ctx)
+	if err != nil {
+		return handleError(ctx, account, PhaseGasFetch, err)
+	}
+
+	params := transactor.TxParams{
+		TokenAddr:         account.Token,
+		SenderKeyProvider: destinationAccount.KeyProvider,
+		ReceiverAddress:   destinationAddress,
+		Owner:             holderAddress,
+		Amount:            amount.String(),
+		GasTipCapValue:    gasTipCapValue,
+		GasFeeCapValue:    gasFeeCapValue,
+	}
+	// the permit collections pull while the destination funds the other accounts of a concurrent Collect
+	release := b.destinationNonce.allocate(&params)
+	tx, err := c.transactor.CreateERC20TransferFromTx(ctx, params)
+	if err != nil {
+		release(nil)
+		return c.handleTransferError(ctx, b, account, PhaseSweepBuild, err)
+	}
+	estimatedFee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap()).String()
+	if c.dryRun {
+		release(nil)
+		result = getResult(ctx, account, StatusSimulated, ReasonDryRun)
+		result.ResolvedAmount = amount.String()
+		result.EstimatedFee = estimatedFee
+		return result
+	}
+	defer func() {
+		result.TxHash = tx.Hash().Hex()
+		result.EstimatedFee = estimatedFee
+	}()
+
+	err = c.transactor.Transfer(ctx, tx)
+	if err != nil {
+		release(nil)
+		return c.handleTransferError(ctx, b, account, PhaseSweepSend, err)
+	}
+	release(tx)
+
+	timeoutCtx, cancelFunc := context.WithTimeout(ctx, c.confirmationTimeout)
+	defer cancelFunc()
+	receipt, err := c.transactor.VerifyTx(timeoutCtx, tx.Hash().Hex())
+	if err != nil {
+		return handleError(ctx, account, PhaseSweepWait, err)
+	}
+	if succeeded(receipt) {
+		receipt, err = c.awaitConfirmations(ctx, tx.Hash(), receipt)
+		if err != nil {
+			return handleError(ctx, account, PhaseSweepWait, err)
+		}
+	}
+	if !succeeded(receipt) {
+		return getResult(ctx, account, StatusPending, ReasonNotMined)
+	}
+
+	mined := c.receiptBlock(ctx, b, receipt)
+	if c.ledger != nil {
+		err = c.appendLedger(ctx, b, account, *holderAddress, *destinationAddress, amount.String(), tx.Hash().Hex(), mined)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("tx", tx.Hash().Hex()).Msg("ledger write failed")
+			if c.ledgerFailurePolicy == LedgerFailurePolicyFail {
+				return handleError(ctx, account, PhaseLedgerWrite, err)
+			}
+		}
+	}
+
+	result = getResult(ctx, account, StatusSuccess, ReasonNone)
+	result.CollectedAmount = amount.String()
+	result.BlockNumber = mined.number
+	result.BlockTime = mined.time
+	result.GasUsed = receipt.GasUsed
+	result.Fee, result.FeeDerived = c.paidFeeString(ctx, tx, receipt)
+	return result
+}
@@ -0,0 +1,27 @@
+package dobermann
+
+import (
+	"context"
+	"fmt"
+)
+
+// CollectAsync collects the accounts like Collect in the background, sending the result of each account on the
+// returned channel as soon as it is done, in the order the accounts complete. Once the context is done no other
+// account is started, the remaining ones are sent as StatusInterrupted, and the channel is closed after the last
+// result. The results do not have the EndBlock of the run.
+func (c evmCollector) CollectAsync(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount) (<-chan Result, error) {
+	err := validateKeyProvider(destinationAccount.KeyProvider)
+	if err != nil {
+		return nil, fmt.Errorf("destination: %w", err)
+	}
+
+	// buffered for all the accounts, so that the run never waits for a slow reader
+	results := make(chan Result, len(accounts))
+	go func() {
+		defer close(results)
+		c.collectRun(ctx, nil, destinationAccount, accounts, func(result Result) {
+			results <- result
+		})
+	}()
+	return results, nil
+}
@@ -0,0 +1,115 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/welthee/dobermann/key"
+	"github.com/welthee/dobermann/transactor"
+)
+
+// releasedTransactor a transactor whose balance reads hold zero tokens, each of them answered once the release
+// channel of its account is closed, or failing with the error of their context when it is cancelled first
+type releasedTransactor struct {
+	transactor.Transactor
+	started chan common.Address
+	release map[common.Address]chan struct{}
+}
+
+func (t *releasedTransactor) BalanceOf(ctx context.Context, accountAddr common.Address, erc20Address string) (*big.Int, error) {
+	t.started <- accountAddr
+	select {
+	case <-t.release[accountAddr]:
+		return big.NewInt(0), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestCollectAsync(t *testing.T) {
+	tests := []struct {
+		name string
+		// released the accounts completed one after the other, each once the result of the previous one was received
+		released []int
+		// cancel the run once the released accounts completed
+		cancel bool
+	}{
+		{name: "completion order", released: []int{2, 0, 1}},
+		{name: "cancelled", released: []int{1}, cancel: true},
+		{name: "cancelled before any completion", cancel: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			accounts := make([]SourceAccount, 3)
+			tr := &releasedTransactor{started: make(chan common.Address, len(accounts)),
+				release: make(map[common.Address]chan struct{})}
+			for i := range accounts {
+				accounts[i] = SourceAccount{KeyProvider: newTestKeyProvider(t), Token: testToken}
+				tr.release[*accounts[i].KeyProvider.GetAddress()] = make(chan struct{})
+			}
+			c := evmCollector{transactor: tr, client: headClient{}, clock: realClock{}, signerType: key.SignerTypeLondon,
+				maxConcurrent: len(accounts), confirmationTimeout: 10 * time.Millisecond}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			results, err := c.CollectAsync(ctx, DestinationAccount{KeyProvider: newTestKeyProvider(t)}, accounts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range accounts {
+				<-tr.started
+			}
+
+			for _, i := range test.released {
+				close(tr.release[*accounts[i].KeyProvider.GetAddress()])
+				result := receiveResult(t, results)
+				if result.SourceAccount.KeyProvider != accounts[i].KeyProvider || result.Status != StatusSkip {
+					t.Fatalf("result of %s %s, want account %d %s", result.SourceAccount.KeyProvider.GetAddress(),
+						result.Status, i, StatusSkip)
+				}
+			}
+			if test.cancel {
+				cancel()
+			}
+			// the accounts still running are interrupted once the confirmation timeout passed
+			for range accounts[len(test.released):] {
+				if result := receiveResult(t, results); result.Status != StatusInterrupted {
+					t.Fatalf("status %s, want %s", result.Status, StatusInterrupted)
+				}
+			}
+			select {
+			case result, ok := <-results:
+				if ok {
+					t.Fatalf("result %s after the last account", result.Status)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("results not closed after the last account")
+			}
+		})
+	}
+}
+
+func TestCollectAsyncInvalidDestination(t *testing.T) {
+	c := evmCollector{clock: realClock{}}
+	results, err := c.CollectAsync(context.Background(), DestinationAccount{}, nil)
+	if err == nil || results != nil {
+		t.Fatalf("results %v error %v, want no results and an error", results, err)
+	}
+}
+
+func receiveResult(t *testing.T, results <-chan Result) Result {
+	t.Helper()
+	select {
+	case result, ok := <-results:
+		if !ok {
+			t.Fatal("results closed before the last account")
+		}
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result")
+	}
+	return Result{}
+}
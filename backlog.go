@@ -0,0 +1,49 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SourceBacklogPolicy what happens to an account whose source has unrelated transactions pending, e.g. sent by a
+// user interacting with a deposit address, which its transfer would queue behind
+type SourceBacklogPolicy string
+
+const (
+	// SourceBacklogIgnore the backlog is not checked, the default
+	SourceBacklogIgnore SourceBacklogPolicy = ""
+	// SourceBacklogSkip the account is not funded and ends with StatusDeferred and ReasonSourceBusy, to be
+	// retried once the pending transactions are mined
+	SourceBacklogSkip SourceBacklogPolicy = "skip"
+	// SourceBacklogProceed the account is collected with its transfer queued behind the pending transactions,
+	// its receipt being waited for the ConfirmationTimeout once more per pending transaction
+	SourceBacklogProceed SourceBacklogPolicy = "proceed"
+)
+
+// checkBacklog reads the transactions pending for the source of the account before it is funded. It returns the
+// nonce the transfers of the account are sent under, nil for the one of the nonce provider, with the depth of the
+// backlog, or false with the final result of the account when it is not to be collected.
+func (c evmCollector) checkBacklog(ctx context.Context, account SourceAccount) (*big.Int, uint64, Result, bool) {
+	if c.sourceBacklogPolicy == SourceBacklogIgnore {
+		return nil, 0, Result{}, true
+	}
+	latest, backlog, err := c.transactor.NonceBacklog(ctx, *account.KeyProvider.GetAddress())
+	if err != nil {
+		return nil, 0, handleError(ctx, account, PhaseValidation, err), false
+	}
+	if backlog == 0 {
+		return nil, 0, Result{}, true
+	}
+	if c.sourceBacklogPolicy == SourceBacklogSkip {
+		result := getResult(ctx, account, StatusDeferred, ReasonSourceBusy)
+		result.SourceBacklog = backlog
+		return nil, backlog, result, false
+	}
+	log.Ctx(ctx).Warn().
+		Str("account", addressHex(account)).
+		Uint64("backlog", backlog).
+		Msg("source has pending transactions, queueing the transfer behind them")
+	return new(big.Int).SetUint64(latest + backlog), backlog, Result{}, true
+}
@@ -0,0 +1,60 @@
+package dobermann
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+)
+
+// zeroBalances reads the token balances of the scheduled accounts with BalancesOf, one Multicall3 call per token,
+// and returns the indexes of the accounts holding none. Nothing is read when no Multicall3Address is configured,
+// nor for the tokens whose balances could not be read, their accounts then read their balance when collected.
+func (c evmCollector) zeroBalances(ctx context.Context, scheduled []scheduledAccount) map[int]bool {
+	zero := make(map[int]bool)
+	if !c.multicall {
+		return zero
+	}
+
+	holders := make(map[string][]common.Address)
+	indexes := make(map[string][]int)
+	tokens := make([]string, 0)
+	for _, s := range scheduled {
+		account := s.account
+		// a zero Amount is reported as such, not as a zero balance
+		if validateKeyProvider(account.KeyProvider) != nil || isNative(account) || len(account.Tokens) > 0 ||
+			isZeroAmount(account.Amount) {
+			continue
+		}
+		token := strings.ToLower(account.Token)
+		if _, ok := holders[token]; !ok {
+			tokens = append(tokens, token)
+		}
+		holders[token] = append(holders[token], *tokenHolder(account))
+		indexes[token] = append(indexes[token], s.index)
+	}
+
+	for _, token := range tokens {
+		balances, err := c.transactor.BalancesOf(ctx, holders[token], token)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("token", token).Msg("batch balance read failed")
+			continue
+		}
+		for i, balance := range balances {
+			if balance.Sign() == 0 {
+				zero[indexes[token][i]] = true
+			}
+		}
+	}
+	return zero
+}
+
+// isZeroAmount reports whether the amount is set, valid and zero
+func isZeroAmount(amount string) bool {
+	if amount == "" {
+		return false
+	}
+	value, err := parseAmount(amount)
+	return err == nil && value.Sign() == 0
+}
@@ -0,0 +1,108 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/welthee/dobermann/transactor"
+)
+
+const otherTestToken = "0x00000000000000000000000000000000000000bb"
+
+// batchBalancesTransactor a transactor whose accounts hold the given balances, the reads of the failing token fail.
+// It records the accounts read for each token.
+type batchBalancesTransactor struct {
+	transactor.Transactor
+	balances map[common.Address]int64
+	failing  string
+	reads    map[string]int
+}
+
+func (t *batchBalancesTransactor) BalancesOf(ctx context.Context, accounts []common.Address, erc20Address string) ([]*big.Int, error) {
+	t.reads[erc20Address] += len(accounts)
+	if strings.EqualFold(erc20Address, t.failing) {
+		return nil, errors.New("multicall failed")
+	}
+	balances := make([]*big.Int, 0, len(accounts))
+	for _, account := range accounts {
+		balances = append(balances, big.NewInt(t.balances[account]))
+	}
+	return balances, nil
+}
+
+func TestZeroBalances(t *testing.T) {
+	tests := []struct {
+		name      string
+		multicall bool
+		failing   string
+		// accounts changes the scheduled accounts, the odd ones holding no tokens
+		accounts func(scheduled []scheduledAccount)
+		want     []int
+		// reads the balances read for each token
+		reads map[string]int
+	}{
+		{name: "multicall disabled", reads: map[string]int{}},
+		{name: "zero balances", multicall: true, want: []int{1, 3}, reads: map[string]int{testToken: 4}},
+		{name: "tokens read apart", multicall: true, want: []int{1, 3},
+			accounts: func(scheduled []scheduledAccount) {
+				scheduled[2].account.Token = otherTestToken
+				scheduled[3].account.Token = otherTestToken
+			},
+			reads: map[string]int{testToken: 2, otherTestToken: 2}},
+		{name: "failing token", multicall: true, failing: otherTestToken, want: []int{1},
+			accounts: func(scheduled []scheduledAccount) {
+				scheduled[2].account.Token = otherTestToken
+				scheduled[3].account.Token = otherTestToken
+			},
+			reads: map[string]int{testToken: 2, otherTestToken: 2}},
+		{name: "accounts not read", multicall: true, want: []int{3},
+			accounts: func(scheduled []scheduledAccount) {
+				scheduled[0].account.Token = ""
+				scheduled[1].account.Amount = "0"
+				scheduled[2].account.Tokens = []string{otherTestToken}
+			},
+			reads: map[string]int{testToken: 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheduled := scheduleTestGroup(t, 4)
+			tr := &batchBalancesTransactor{balances: make(map[common.Address]int64), failing: test.failing,
+				reads: make(map[string]int)}
+			for i, s := range scheduled {
+				tr.balances[*s.account.KeyProvider.GetAddress()] = int64((i + 1) % 2 * 100)
+			}
+			if test.accounts != nil {
+				test.accounts(scheduled)
+			}
+			c := evmCollector{transactor: tr, multicall: test.multicall}
+
+			zero := c.zeroBalances(context.Background(), scheduled)
+			got := make([]int, 0, len(zero))
+			for index := range zero {
+				got = append(got, index)
+			}
+			sort.Ints(got)
+			if len(got) != len(test.want) {
+				t.Fatalf("zero balances %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Fatalf("zero balances %v, want %v", got, test.want)
+				}
+			}
+			if len(tr.reads) != len(test.reads) {
+				t.Fatalf("reads %v, want %v", tr.reads, test.reads)
+			}
+			for token, want := range test.reads {
+				if tr.reads[token] != want {
+					t.Fatalf("reads %v, want %v", tr.reads, test.reads)
+				}
+			}
+		})
+	}
+}
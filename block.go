@@ -0,0 +1,82 @@
+package dobermann
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// minedBlock the block a transaction was mined in, zero when it could not be read
+type minedBlock struct {
+	number uint64
+	time   time.Time
+}
+
+// blockTimes caches the timestamps of the blocks across the accounts of a batch
+type blockTimes struct {
+	mu    sync.Mutex
+	times map[uint64]time.Time
+}
+
+func newBlockTimes() *blockTimes {
+	return &blockTimes{times: make(map[uint64]time.Time)}
+}
+
+func (t *blockTimes) get(number uint64) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	blockTime, ok := t.times[number]
+	return blockTime, ok
+}
+
+func (t *blockTimes) set(number uint64, blockTime time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.times[number] = blockTime
+}
+
+// minedBlock returns the block the mined transaction is in, with its timestamp. The failures are only logged,
+// leaving the block number or the time empty, as the transaction itself was mined.
+func (c evmCollector) minedBlock(ctx context.Context, b *batch, txHash common.Hash) minedBlock {
+	receipt, err := c.transactor.GetTxReceipt(ctx, txHash.Hex())
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("tx", txHash.Hex()).Msg("failed to get the block of the transaction")
+		return minedBlock{}
+	}
+	return c.receiptBlock(ctx, b, receipt)
+}
+
+// receiptBlock returns the block of the receipt with its timestamp, leaving the time empty when it can not be read
+func (c evmCollector) receiptBlock(ctx context.Context, b *batch, receipt *types.Receipt) minedBlock {
+	mined := minedBlock{number: receipt.BlockNumber.Uint64()}
+
+	blockTime, ok := b.blockTimes.get(mined.number)
+	if !ok {
+		header, err := c.client.HeaderByNumber(ctx, receipt.BlockNumber)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Uint64("block", mined.number).Msg("failed to get the block time")
+			return mined
+		}
+		blockTime = time.Unix(int64(header.Time), 0).UTC()
+		b.blockTimes.set(mined.number, blockTime)
+	}
+	mined.time = blockTime
+	return mined
+}
+
+// succeeded whether the transaction of the receipt was executed successfully
+func succeeded(receipt *types.Receipt) bool {
+	return receipt != nil && receipt.Status == types.ReceiptStatusSuccessful
+}
+
+// timePointer returns nil for the zero time, so that it is omitted from JSON
+func timePointer(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,93 @@
+package dobermann
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/welthee/dobermann/transactor"
+)
+
+// maxFeeGwei the highest fee accepted by the fee fields of the configuration, far above the fees seen on the
+// supported chains, so that a misplaced digit is refused instead of being paid
+const maxFeeGwei = 100_000
+
+var (
+	// ErrInvalidAmount the amount is not a non-negative decimal integer
+	ErrInvalidAmount = transactor.ErrInvalidAmount
+	// ErrAmountOverflow the amount does not fit in 256 bits, so it can not be transferred
+	ErrAmountOverflow = transactor.ErrUint256Overflow
+	// ErrFeeOutOfRange a fee of the configuration is above the sane range of maxFeeGwei
+	ErrFeeOutOfRange = errors.New("fee out of range")
+)
+
+var maxFeeWei = new(big.Int).Mul(big.NewInt(maxFeeGwei), big.NewInt(1e9))
+
+// checkAmount returns an error when the optional wei value of the configuration is negative or over uint256
+func checkAmount(name string, value *big.Int) error {
+	if value == nil {
+		return nil
+	}
+	err := transactor.CheckUint256(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// checkFee returns an error when the optional wei fee of the configuration is negative or over maxFeeGwei
+func checkFee(name string, value *big.Int) error {
+	err := checkAmount(name, value)
+	if err != nil {
+		return err
+	}
+	if value != nil && value.Cmp(maxFeeWei) > 0 {
+		return fmt.Errorf("%w: %s %s gwei over %d gwei", ErrFeeOutOfRange, name, FormatUnits(value, 9), maxFeeGwei)
+	}
+	return nil
+}
+
+// validateConfigValues checks the wei values of the configuration before the collector is created
+func validateConfigValues(config EVMCollectorConfig, gasTipCap *big.Int, maxGasFeeCap *big.Int) error {
+	fees := []struct {
+		name  string
+		value *big.Int
+	}{
+		{"gas tip cap", gasTipCap},
+		{"max gas fee cap", maxGasFeeCap},
+		{"fee window max fee", config.FeeWindow.MaxFee},
+		{"plan tolerance max fee cap increase", config.PlanTolerance.MaxFeeCapIncrease},
+	}
+	for _, fee := range fees {
+		err := checkFee(fee.name, fee.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	amounts := []struct {
+		name  string
+		value *big.Int
+	}{
+		{"funding buffer", config.FundingBuffer},
+		{"minimum funding amount", config.MinimumFundingAmount},
+		{"min reclaim amount", config.MinReclaimAmount},
+		{"cost ordering budget", config.CostOrdering.Budget},
+	}
+	for _, amount := range amounts {
+		err := checkAmount(amount.name, amount.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAmount checks the Amount of the SourceAccount before any chain call, empty collects the whole balance
+func validateAmount(account SourceAccount) error {
+	if account.Amount == "" {
+		return nil
+	}
+	_, err := parseAmount(account.Amount)
+	return err
+}
@@ -0,0 +1,121 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/transactor"
+)
+
+const (
+	defaultBundleMaxBlocks    = 3
+	defaultBundlePollInterval = 2 * time.Second
+)
+
+// errBundleExpired the bundle was not included in any of the blocks it was submitted for
+var errBundleExpired = errors.New("bundle expired")
+
+// Bundle submits the funding and the sweep of an account together, so that the funding is never mined
+// without the sweep, e.g. through eth_sendBundle with transactor.NewRPCBundleSubmitter
+type Bundle struct {
+	// Submitter disables the bundles when nil
+	Submitter transactor.BundleSubmitter
+	// MaxBlocks the number of next blocks the bundle is submitted for, 3 when zero. The account is funded and
+	// swept one transaction after the other once they passed without the bundle being included.
+	MaxBlocks int
+	// PollInterval between the inclusion checks, two seconds when zero, with a random jitter of up to 20%
+	PollInterval time.Duration
+}
+
+func (b Bundle) maxBlocks() uint64 {
+	if b.MaxBlocks <= 0 {
+		return defaultBundleMaxBlocks
+	}
+	return uint64(b.MaxBlocks)
+}
+
+func (b Bundle) pollInterval() time.Duration {
+	if b.PollInterval <= 0 {
+		return defaultBundlePollInterval
+	}
+	return b.PollInterval
+}
+
+// fundBundled funds and sweeps the prepared account with a single bundle. The account is left unfunded, to be
+// funded and swept one transaction after the other, when the bundle is not supported or expired, and it is only
+// funded when the funding was mined without the sweep.
+func (c evmCollector) fundBundled(ctx context.Context, b *batch, col *collection, destinationAccount DestinationAccount) (Phase, error) {
+	sweepMined := false
+	nativTx, phase, err := c.submitFunding(ctx, b, c.fundingParams(col, destinationAccount), func(nativTx *types.Transaction) error {
+		fundingMined, mined, err := c.submitBundle(ctx, nativTx, col.erc20Tx)
+		if err != nil {
+			return err
+		}
+		if !fundingMined {
+			return errBundleExpired
+		}
+		sweepMined = mined
+		return nil
+	})
+	if err != nil {
+		if phase == PhaseFundingBuild || errors.Is(err, transactor.ErrBroadcastVetoed) || ctx.Err() != nil {
+			return phase, err
+		}
+		log.Ctx(ctx).Warn().Err(err).Str("account", addressHex(col.account)).
+			Msg("bundle not included, funding and sweeping sequentially")
+		return "", nil
+	}
+
+	col.funded = true
+	col.bundled = sweepMined
+	col.fundingTxHash = nativTx.Hash()
+	if !sweepMined {
+		log.Ctx(ctx).Warn().Str("account", addressHex(col.account)).Str("tx", nativTx.Hash().Hex()).
+			Msg("funding mined without the sweep, sweeping on its own")
+	}
+	return "", nil
+}
+
+// submitBundle submits the funding and the sweep for each of the next blocks and waits until both are mined
+// or the last of the blocks passed, returning whether each of them was mined
+func (c evmCollector) submitBundle(ctx context.Context, fundingTx *types.Transaction, sweepTx *types.Transaction) (bool, bool, error) {
+	current, err := c.client.BlockNumber(ctx)
+	if err != nil {
+		return false, false, err
+	}
+	lastBlock := current + c.bundle.maxBlocks()
+	txs := []*types.Transaction{fundingTx, sweepTx}
+	for block := current + 1; block <= lastBlock; block++ {
+		err = c.transactor.TransferBundle(ctx, txs, block)
+		if err != nil {
+			return false, false, err
+		}
+	}
+
+	for {
+		fundingMined := c.isMined(ctx, fundingTx)
+		sweepMined := c.isMined(ctx, sweepTx)
+		if fundingMined && sweepMined {
+			return true, true, nil
+		}
+		latest, err := c.client.BlockNumber(ctx)
+		if err == nil && latest > lastBlock {
+			// a receipt may have been indexed after the check above
+			return c.isMined(ctx, fundingTx), c.isMined(ctx, sweepTx), nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, false, ctx.Err()
+		case <-c.clock.After(c.jitter.apply(c.bundle.pollInterval())):
+		}
+	}
+}
+
+// isMined whether the receipt of the transaction can be read, a failing read is retried at the next check
+func (c evmCollector) isMined(ctx context.Context, tx *types.Transaction) bool {
+	receipt, err := c.transactor.GetTxReceipt(ctx, tx.Hash().Hex())
+	return err == nil && receipt != nil
+}
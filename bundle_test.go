@@ -0,0 +1,152 @@
+package dobermann
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/welthee/dobermann/client"
+	"github.com/welthee/dobermann/dobermanntest"
+	"github.com/welthee/dobermann/transactor"
+)
+
+// advancingChain a chain moving to the next block each time its block number is read, so that the blocks a
+// bundle was submitted for pass while its inclusion is checked
+type advancingChain struct {
+	*dobermanntest.Chain
+	mu     sync.Mutex
+	passed uint64
+}
+
+func (c *advancingChain) BlockNumber(ctx context.Context) (uint64, error) {
+	head, err := c.Chain.BlockNumber(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.passed++
+	return head + c.passed, err
+}
+
+// bundleBuilder an eth_sendBundle endpoint mining the first included transactions of the first bundle it receives
+type bundleBuilder struct {
+	chain    *advancingChain
+	included int
+
+	mu      sync.Mutex
+	bundles int
+}
+
+// submitted returns the number of bundles received
+func (b *bundleBuilder) submitted() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.bundles
+}
+
+func (b *bundleBuilder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params []struct {
+			Txs []hexutil.Bytes `json:"txs"`
+		} `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Method != "eth_sendBundle" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	b.mu.Lock()
+	b.bundles++
+	first := b.bundles == 1
+	b.mu.Unlock()
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": map[string]string{"bundleHash": "0x01"}}
+	for i, raw := range request.Params[0].Txs {
+		if !first || i >= b.included {
+			break
+		}
+		tx := new(types.Transaction)
+		err := tx.UnmarshalBinary(raw)
+		if err == nil {
+			err = b.chain.SendTransaction(r.Context(), tx)
+		}
+		if err != nil {
+			response = map[string]interface{}{"jsonrpc": "2.0", "id": request.ID,
+				"error": map[string]interface{}{"code": -32000, "message": err.Error()}}
+			break
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func TestCollectBundled(t *testing.T) {
+	tests := []struct {
+		name string
+		// included the number of transactions of the bundle mined by the builder, the funding then the sweep
+		included int
+		bundled  bool
+		// sends the transactions sent to the node one after the other
+		sends int
+	}{
+		{name: "included", included: 2, bundled: true},
+		{name: "not included", included: 0, sends: 2},
+		{name: "funding included without the sweep", included: 1, sends: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			destination := newTestKeyProvider(t)
+			source := newTestKeyProvider(t)
+			chain := &advancingChain{Chain: dobermanntest.NewChain(big.NewInt(1))}
+			chain.SetBalance(*destination.GetAddress(), big.NewInt(1e18))
+			chain.SetTokenBalance(common.HexToAddress(testToken), *source.GetAddress(), big.NewInt(100))
+			builder := &bundleBuilder{chain: chain, included: test.included}
+			endpoint := httptest.NewServer(builder)
+			defer endpoint.Close()
+			submitter, err := transactor.NewRPCBundleSubmitter(endpoint.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			counting := client.NewCountingHook()
+			collector, err := NewEVMCollector(EVMCollectorConfig{
+				Client:              chain,
+				RPCHook:             counting.Hook(),
+				GasTracker:          dobermanntest.NewGasTracker(30, 90),
+				NonceProviderType:   NonceProviderTypeNetwork,
+				ReceiptPollInterval: time.Millisecond,
+				Bundle:              Bundle{Submitter: submitter, MaxBlocks: 2, PollInterval: time.Millisecond},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer collector.Close()
+
+			results := collector.Collect(context.Background(), DestinationAccount{KeyProvider: destination},
+				[]SourceAccount{{KeyProvider: source, Token: testToken}})
+			if results[0].Status != StatusSuccess || results[0].Bundled != test.bundled {
+				t.Fatalf("status %s bundled %v, want %s bundled %v (%s)", results[0].Status, results[0].Bundled,
+					StatusSuccess, test.bundled, results[0].Reason)
+			}
+			// the bundle is submitted for each of the next blocks
+			if builder.submitted() != 2 {
+				t.Fatalf("%d bundles submitted, want 2", builder.submitted())
+			}
+			// the transactions left out of the bundle are sent to the node, the same ones being mined only once
+			if got := counting.Count("eth_sendRawTransaction"); got != test.sends {
+				t.Fatalf("%d transactions sent to the node, want %d", got, test.sends)
+			}
+			if got := len(chain.Sent()); got != 2 {
+				t.Fatalf("%d transactions mined, want the funding and the sweep", got)
+			}
+			if chain.TokenBalance(common.HexToAddress(testToken), *destination.GetAddress()).Int64() != 100 {
+				t.Fatal("tokens not swept to the destination")
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package dobermann
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ChainStall detects a chain which stopped producing blocks, e.g. during an outage of the network, so the receipt
+// waits fail right away with transactor.ErrChainStalled instead of each of them burning its ConfirmationTimeout,
+// and the run pauses until the blocks resume. A node which can not be read, or whose endpoints are all unhealthy,
+// is not taken for a stall.
+type ChainStall struct {
+	// Threshold how long the head may not change while the node answers before the chain is stalled,
+	// disabled when zero
+	Threshold time.Duration
+	// PollInterval how often the head is read, a quarter of the Threshold when zero
+	PollInterval time.Duration
+	// MaxPause the longest the run waits for the blocks to resume before an account is started, the accounts
+	// are then skipped with ReasonChainStalled until the blocks resume
+	MaxPause time.Duration
+	// OnStall is invoked when the run pauses, and when it resumes or gives up waiting
+	OnStall func(ctx context.Context, event ChainStallEvent)
+}
+
+// ChainStallEvent the state of a pause of the run waiting for the chain to produce blocks again
+type ChainStallEvent struct {
+	// Head the last block seen before the stall
+	Head uint64 `json:"head"`
+	// Since when the Head was first seen
+	Since time.Time `json:"since"`
+	// Paused since the pause started, zero when it starts
+	Paused time.Duration `json:"paused"`
+	// Resumed the blocks resumed, false when the pause starts or reached the MaxPause
+	Resumed bool `json:"resumed"`
+}
+
+// awaitChainHead pauses while the chain is stalled, returning false when the pause reached the MaxPause
+// or the context is done before the blocks resumed
+func (c evmCollector) awaitChainHead(ctx context.Context) bool {
+	state := c.headWatchdog.State()
+	if !state.Stalled {
+		return true
+	}
+	started := c.clock.Now()
+	event := ChainStallEvent{Head: state.Head, Since: state.Since}
+	log.Ctx(ctx).Warn().Uint64("head", state.Head).Time("since", state.Since).Msg("chain stalled, pausing the run")
+	c.onStall(ctx, event)
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-c.headWatchdog.Resumed():
+		event.Resumed = true
+		log.Ctx(ctx).Info().Uint64("head", c.headWatchdog.State().Head).Msg("chain resumed, resuming the run")
+	case <-c.clock.After(c.chainStall.MaxPause):
+		log.Ctx(ctx).Warn().Uint64("head", state.Head).Msg("chain still stalled, skipping the accounts")
+	}
+	event.Paused = c.clock.Now().Sub(started)
+	c.onStall(ctx, event)
+	return event.Resumed
+}
+
+func (c evmCollector) onStall(ctx context.Context, event ChainStallEvent) {
+	if c.chainStall.OnStall != nil {
+		c.chainStall.OnStall(ctx, event)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultBroadcastTimeout = 5 * time.Second
+
+type broadcastClient struct {
+	Client
+	secondaries []Client
+	timeout     time.Duration
+}
+
+// WithSecondaryBroadcast utility method to wrap a Client so every transaction accepted by it is also
+// sent to the secondary clients, improving its propagation. The secondary broadcasts are made in the
+// background with the given timeout, five seconds when zero, and their failures are only logged.
+// The client is returned unchanged when there are no secondary clients.
+func WithSecondaryBroadcast(client Client, secondaries []Client, timeout time.Duration) Client {
+	if len(secondaries) == 0 {
+		return client
+	}
+	if timeout <= 0 {
+		timeout = defaultBroadcastTimeout
+	}
+	return broadcastClient{
+		Client:      client,
+		secondaries: secondaries,
+		timeout:     timeout,
+	}
+}
+
+func (b broadcastClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	err := b.Client.SendTransaction(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for i, secondary := range b.secondaries {
+		go b.broadcast(i, secondary, tx)
+	}
+	return nil
+}
+
+// broadcast sends the transaction to the secondary client, which may already know it
+func (b broadcastClient) broadcast(index int, secondary Client, tx *types.Transaction) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	err := ClassifySendError(secondary.SendTransaction(ctx, tx))
+	if err != nil && !errors.Is(err, ErrAlreadyKnown) {
+		log.Warn().Err(err).
+			Int("endpoint", index).
+			Str("tx", tx.Hash().Hex()).
+			Msg("secondary broadcast failed")
+		return
+	}
+	log.Debug().
+		Int("endpoint", index).
+		Str("tx", tx.Hash().Hex()).
+		Msg("secondary broadcast done")
+}
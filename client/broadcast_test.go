@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newRefusingNode returns a node answering every transaction with the error
+func newRefusingNode(t *testing.T, err stubError) *stubNode {
+	return newStubNode(t, map[string]stubMethod{
+		"eth_sendRawTransaction": func(params []json.RawMessage) (interface{}, error) {
+			return nil, err
+		},
+	})
+}
+
+// waitCount waits for the node to receive the calls of the method, the broadcasts being made in the background
+func waitCount(t *testing.T, node *stubNode, method string, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for node.count(method) < want && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := node.count(method); got != want {
+		t.Fatalf("%d %s calls, want %d", got, method, want)
+	}
+}
+
+func TestSecondaryBroadcast(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   func(t *testing.T) *stubNode
+		wantErr   bool
+		broadcast int
+	}{
+		{name: "accepted", primary: func(t *testing.T) *stubNode { return newBlockNode(t, 1) }, broadcast: 1},
+		{name: "refused", wantErr: true, primary: func(t *testing.T) *stubNode {
+			return newRefusingNode(t, stubError{Code: -32000, Message: "nonce too low: next nonce 1, tx nonce 0"})
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			primary := test.primary(t)
+			accepting := newBlockNode(t, 1)
+			known := newRefusingNode(t, stubError{Code: -32000, Message: "already known"})
+			failing := newRefusingNode(t, stubError{Code: -32000, Message: "internal error"})
+			down := newBlockNode(t, 1)
+			down.Close()
+			secondaries := []*stubNode{accepting, known, failing}
+			c := WithSecondaryBroadcast(primary.dial(t),
+				[]Client{accepting.dial(t), known.dial(t), failing.dial(t), down.dial(t)}, time.Second)
+			tx, _ := newSignedTx(t, 0)
+
+			err := c.SendTransaction(context.Background(), tx)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error %v, want error %v", err, test.wantErr)
+			}
+			if primary.count("eth_sendRawTransaction") != 1 {
+				t.Fatalf("%d sends to the primary node, want 1", primary.count("eth_sendRawTransaction"))
+			}
+			// every secondary node is sent the transaction once, whether it knows it already or fails
+			for _, secondary := range secondaries {
+				waitCount(t, secondary, "eth_sendRawTransaction", test.broadcast)
+			}
+		})
+	}
+}
+
+func TestSecondaryBroadcastNone(t *testing.T) {
+	c := headOnlyClient{}
+	if got := WithSecondaryBroadcast(c, nil, 0); got != Client(c) {
+		t.Fatal("client wrapped without secondary clients")
+	}
+}
+
+func TestClassifySendError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "geth nonce", err: errors.New("nonce too low: next nonce 5, tx nonce 4"), want: ErrNonceTooLow},
+		{name: "nethermind nonce", err: errors.New("OldNonce"), want: ErrNonceTooLow},
+		{name: "geth known", err: errors.New("already known"), want: ErrAlreadyKnown},
+		{name: "openethereum known", err: errors.New("Transaction with the same hash was already imported."), want: ErrAlreadyKnown},
+		{name: "underpriced", err: errors.New("replacement transaction underpriced"), want: ErrReplacementUnderpriced},
+		{name: "funds", err: fmt.Errorf("send: %w", errors.New("insufficient funds for gas * price + value")), want: ErrInsufficientFunds},
+		{name: "other", err: errors.New("internal error")},
+		{name: "none"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ClassifySendError(test.err)
+			if test.want == nil {
+				if got != test.err {
+					t.Fatalf("ClassifySendError(%v) = %v, want the error unchanged", test.err, got)
+				}
+				return
+			}
+			if !errors.Is(got, test.want) || !errors.Is(got, test.err) || got.Error() != test.err.Error() {
+				t.Fatalf("ClassifySendError(%v) = %v, want %v keeping the message", test.err, got, test.want)
+			}
+		})
+	}
+}
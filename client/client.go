@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Client defines the blockchain node methods used by dobermann, it is implemented by *ethclient.Client
+type Client interface {
+	// ChainID retrieves the current chain ID for transaction replay protection.
+	ChainID(ctx context.Context) (*big.Int, error)
+	// BalanceAt returns the wei balance of the given account at the given block, latest when nil.
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	// NonceAt returns the account nonce of the given account at the given block, latest when nil.
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	// PendingNonceAt returns the account nonce of the given account in the pending state.
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	// CodeAt returns the contract code of the given account at the given block, latest when nil.
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	// CallContract executes a message call transaction without creating a transaction on the blockchain.
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	// SuggestGasPrice retrieves the currently suggested gas price.
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	// SuggestGasTipCap retrieves the currently suggested gas tip cap.
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	// EstimateGas returns the gas needed to execute the given message call.
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	// SendTransaction injects a signed transaction into the pending pool for execution.
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	// TransactionReceipt returns the receipt of a mined transaction, ethereum.NotFound when not yet mined.
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	// BlockNumber returns the most recent block number.
+	BlockNumber(ctx context.Context) (uint64, error)
+	// HeaderByNumber returns the header of the block with the given number, latest when nil.
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+var _ Client = (*ethclient.Client)(nil)
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"errors"
+	"strings"
+)
+
+// The errors a node returns when it refuses a transaction, ClassifySendError wraps the error of the node with the
+// matching one
+var (
+	// ErrNonceTooLow another transaction of the sender with the same nonce was already mined
+	ErrNonceTooLow = errors.New("nonce too low")
+	// ErrAlreadyKnown the same transaction is already in the pool of the node
+	ErrAlreadyKnown = errors.New("already known")
+	// ErrReplacementUnderpriced a pending transaction has the same nonce and the fees are not high enough to replace it
+	ErrReplacementUnderpriced = errors.New("replacement transaction underpriced")
+	// ErrInsufficientFunds the sender can not pay the gas and the value of the transaction
+	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
+)
+
+// sendErrorMessages the lower case fragments of the messages of geth, bor, erigon, nethermind, openethereum
+// and the hosted providers, e.g. "nonce too low: next nonce 5, tx nonce 4" from Alchemy
+var sendErrorMessages = []struct {
+	err       error
+	fragments []string
+}{
+	{ErrNonceTooLow, []string{"nonce too low", "nonce is too low", "oldnonce"}},
+	{ErrAlreadyKnown, []string{"already known", "known transaction", "alreadyknown", "already imported"}},
+	{ErrReplacementUnderpriced, []string{"replacement transaction underpriced", "replacement fee too low", "too low to replace"}},
+	{ErrInsufficientFunds, []string{"insufficient funds", "insufficientfunds"}},
+}
+
+// sendError keeps the message of the node while matching the classified error with errors.Is
+type sendError struct {
+	kind error
+	err  error
+}
+
+func (e sendError) Error() string {
+	return e.err.Error()
+}
+
+func (e sendError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// ClassifySendError wraps the error of a sent transaction with the error matching its message,
+// it returns the other errors unchanged
+func ClassifySendError(err error) error {
+	if err == nil {
+		return nil
+	}
+	message := strings.ToLower(err.Error())
+	for _, known := range sendErrorMessages {
+		for _, fragment := range known.fragments {
+			if strings.Contains(message, fragment) {
+				return sendError{kind: known.err, err: err}
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultUnhealthyPeriod = 30 * time.Second
+
+var ErrNoEndpoints = errors.New("no rpc endpoints configured")
+
+type endpoint struct {
+	client         Client
+	mu             sync.Mutex
+	unhealthyUntil time.Time
+}
+
+func (e *endpoint) isHealthy(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.unhealthyUntil)
+}
+
+func (e *endpoint) markUnhealthy(until time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.unhealthyUntil = until
+}
+
+type failoverClient struct {
+	endpoints       []*endpoint
+	next            uint64
+	unhealthyPeriod time.Duration
+}
+
+// NewFailoverClient utility method to create a Client which retries a call against the next
+// client when one fails with a connection-level error. Clients failing are considered unhealthy
+// for a period and are tried last. Reads are distributed round-robin, while transactions are
+// always sent to the first healthy client in the given order.
+func NewFailoverClient(clients []Client) (Client, error) {
+	if len(clients) == 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	endpoints := make([]*endpoint, 0, len(clients))
+	for _, c := range clients {
+		endpoints = append(endpoints, &endpoint{client: c})
+	}
+	return &failoverClient{
+		endpoints:       endpoints,
+		unhealthyPeriod: defaultUnhealthyPeriod,
+	}, nil
+}
+
+// order returns the endpoints in the order they should be tried, healthy ones first
+func (f *failoverClient) order(roundRobin bool) []*endpoint {
+	start := 0
+	if roundRobin {
+		start = int(atomic.AddUint64(&f.next, 1) % uint64(len(f.endpoints)))
+	}
+
+	now := time.Now()
+	healthy := make([]*endpoint, 0, len(f.endpoints))
+	unhealthy := make([]*endpoint, 0)
+	for i := range f.endpoints {
+		e := f.endpoints[(start+i)%len(f.endpoints)]
+		if e.isHealthy(now) {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func call[T any](ctx context.Context, f *failoverClient, roundRobin bool, fn func(Client) (T, error)) (T, error) {
+	var result T
+	var err error
+	for _, e := range f.order(roundRobin) {
+		result, err = fn(e.client)
+		if err == nil || !isConnectionError(ctx, err) {
+			return result, err
+		}
+		log.Ctx(ctx).Warn().Err(err).Msg("rpc endpoint failed, trying next one")
+		e.markUnhealthy(time.Now().Add(f.unhealthyPeriod))
+	}
+	return result, err
+}
+
+// isConnectionError reports whether err was caused by the endpoint not being reachable,
+// as opposed to an error returned by the node itself or to the call being cancelled
+func isConnectionError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ethereum.NotFound) || errors.Is(err, ErrPendingTransactionsUnsupported) {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	return true
+}
+
+func (f *failoverClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return call(ctx, f, true, func(c Client) (*big.Int, error) {
+		return c.ChainID(ctx)
+	})
+}
+
+func (f *failoverClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return call(ctx, f, true, func(c Client) (*big.Int, error) {
+		return c.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (f *failoverClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return call(ctx, f, true, func(c Client) (uint64, error) {
+		return c.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+func (f *failoverClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return call(ctx, f, true, func(c Client) (uint64, error) {
+		return c.PendingNonceAt(ctx, account)
+	})
+}
+
+func (f *failoverClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, f, true, func(c Client) ([]byte, error) {
+		return c.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+func (f *failoverClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, f, true, func(c Client) ([]byte, error) {
+		return c.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+func (f *failoverClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return call(ctx, f, true, func(c Client) (*big.Int, error) {
+		return c.SuggestGasPrice(ctx)
+	})
+}
+
+func (f *failoverClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return call(ctx, f, true, func(c Client) (*big.Int, error) {
+		return c.SuggestGasTipCap(ctx)
+	})
+}
+
+func (f *failoverClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return call(ctx, f, true, func(c Client) (uint64, error) {
+		return c.EstimateGas(ctx, msg)
+	})
+}
+
+func (f *failoverClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	_, err := call(ctx, f, false, func(c Client) (struct{}, error) {
+		return struct{}{}, c.SendTransaction(ctx, tx)
+	})
+	return err
+}
+
+func (f *failoverClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return call(ctx, f, true, func(c Client) (uint64, error) {
+		return c.BlockNumber(ctx)
+	})
+}
+
+func (f *failoverClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return call(ctx, f, true, func(c Client) (*types.Receipt, error) {
+		return c.TransactionReceipt(ctx, txHash)
+	})
+}
+
+func (f *failoverClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return call(ctx, f, true, func(c Client) (*types.Header, error) {
+		return c.HeaderByNumber(ctx, number)
+	})
+}
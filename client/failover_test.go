@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// newBlockNode returns a node at the block, accepting every transaction
+func newBlockNode(t *testing.T, block uint64) *stubNode {
+	return newStubNode(t, map[string]stubMethod{
+		"eth_blockNumber": func(params []json.RawMessage) (interface{}, error) {
+			return hexutil.Uint64(block), nil
+		},
+		"eth_sendRawTransaction": func(params []json.RawMessage) (interface{}, error) {
+			var data hexutil.Bytes
+			err := json.Unmarshal(params[0], &data)
+			if err != nil {
+				return nil, err
+			}
+			tx := new(types.Transaction)
+			err = tx.UnmarshalBinary(data)
+			if err != nil {
+				return nil, err
+			}
+			return tx.Hash(), nil
+		},
+	})
+}
+
+func newTestFailoverClient(t *testing.T, nodes ...*stubNode) *failoverClient {
+	t.Helper()
+	clients := make([]Client, 0, len(nodes))
+	for _, node := range nodes {
+		clients = append(clients, node.dial(t))
+	}
+	c, err := NewFailoverClient(clients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.(*failoverClient)
+}
+
+func TestFailover(t *testing.T) {
+	down, up := newBlockNode(t, 1), newBlockNode(t, 2)
+	down.Close()
+	f := newTestFailoverClient(t, down, up)
+	tx, _ := newSignedTx(t, 0)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "read", call: func() error {
+			block, err := f.BlockNumber(context.Background())
+			if err == nil && block != 2 {
+				return fmt.Errorf("block %d, want 2", block)
+			}
+			return err
+		}},
+		{name: "send", call: func() error {
+			return f.SendTransaction(context.Background(), tx)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.call(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	if up.count("eth_blockNumber") != 1 || up.count("eth_sendRawTransaction") != 1 {
+		t.Fatalf("%d reads and %d sends answered by the reachable node, want 1 and 1",
+			up.count("eth_blockNumber"), up.count("eth_sendRawTransaction"))
+	}
+	if !f.Healthy() {
+		t.Fatal("unhealthy with a reachable node")
+	}
+}
+
+func TestFailoverHealth(t *testing.T) {
+	first, second := newBlockNode(t, 1), newBlockNode(t, 2)
+	f := newTestFailoverClient(t, first, second)
+	f.unhealthyPeriod = 50 * time.Millisecond
+	tx, _ := newSignedTx(t, 0)
+
+	// the first node, marked unhealthy like after a connection error, is tried last until its unhealthy period passed
+	f.endpoints[0].markUnhealthy(time.Now().Add(f.unhealthyPeriod))
+	if err := f.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	if first.count("eth_sendRawTransaction") != 0 || second.count("eth_sendRawTransaction") != 1 {
+		t.Fatal("transaction sent to the unhealthy node")
+	}
+	time.Sleep(f.unhealthyPeriod)
+	if err := f.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	if first.count("eth_sendRawTransaction") != 1 {
+		t.Fatal("transaction not sent to the first node once healthy again")
+	}
+
+	// all the nodes unreachable
+	first.Close()
+	second.Close()
+	f.unhealthyPeriod = time.Minute
+	if _, err := f.BlockNumber(context.Background()); err == nil {
+		t.Fatal("no error without reachable nodes")
+	}
+	if f.Healthy() {
+		t.Fatal("healthy without reachable nodes")
+	}
+}
+
+func TestFailoverRoundRobin(t *testing.T) {
+	first, second := newBlockNode(t, 1), newBlockNode(t, 2)
+	f := newTestFailoverClient(t, first, second)
+	tx, _ := newSignedTx(t, 0)
+
+	for i := 0; i < 4; i++ {
+		if _, err := f.BlockNumber(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.SendTransaction(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the reads are spread over the nodes, the transactions all sent to the first one
+	if first.count("eth_blockNumber") != 2 || second.count("eth_blockNumber") != 2 {
+		t.Fatalf("reads %d and %d, want 2 and 2", first.count("eth_blockNumber"), second.count("eth_blockNumber"))
+	}
+	if first.count("eth_sendRawTransaction") != 4 || second.count("eth_sendRawTransaction") != 0 {
+		t.Fatalf("sends %d and %d, want 4 and 0", first.count("eth_sendRawTransaction"), second.count("eth_sendRawTransaction"))
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	node := newStubNode(t, map[string]stubMethod{
+		"eth_blockNumber": func(params []json.RawMessage) (interface{}, error) {
+			return nil, stubError{Code: -32000, Message: "header not found"}
+		},
+	})
+	_, nodeErr := node.dial(t).BlockNumber(context.Background())
+	down := newStubNode(t, nil)
+	down.Close()
+	_, connectionErr := down.dial(t).BlockNumber(context.Background())
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{name: "unreachable", ctx: context.Background(), err: connectionErr, want: true},
+		{name: "node error", ctx: context.Background(), err: nodeErr},
+		{name: "not found", ctx: context.Background(), err: ethereum.NotFound},
+		{name: "pool not readable", ctx: context.Background(), err: ErrPendingTransactionsUnsupported},
+		{name: "cancelled context", ctx: cancelled, err: connectionErr},
+		{name: "cancelled call", ctx: context.Background(), err: fmt.Errorf("call: %w", context.Canceled)},
+		{name: "call timeout", ctx: context.Background(), err: fmt.Errorf("call: %w", context.DeadlineExceeded)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isConnectionError(test.ctx, test.err); got != test.want {
+				t.Fatalf("isConnectionError(%v) = %v, want %v", test.err, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package client
+
+import "time"
+
+// HealthReporter can be implemented by a Client tracking the health of its endpoints, like the failover client
+// which considers an endpoint unhealthy for a while once it failed with a connection-level error
+type HealthReporter interface {
+	// Healthy reports whether at least one endpoint is considered reachable
+	Healthy() bool
+}
+
+// Healthy reports whether the client considers an endpoint reachable, true when it does not track their health
+func Healthy(client Client) bool {
+	if reporter, ok := client.(HealthReporter); ok {
+		return reporter.Healthy()
+	}
+	return true
+}
+
+func (f *failoverClient) Healthy() bool {
+	now := time.Now()
+	for _, e := range f.endpoints {
+		if e.isHealthy(now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r retryClient) Healthy() bool {
+	return Healthy(r.Client)
+}
+
+func (b broadcastClient) Healthy() bool {
+	return Healthy(b.Client)
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// CallInfo describes a single JSON-RPC call made to the node
+type CallInfo struct {
+	// Method the JSON-RPC method name, e.g. eth_getBalance
+	Method   string
+	Duration time.Duration
+	Err      error
+	// ParamsSize the size in bytes of the JSON encoded params, only set when sizes are enabled
+	ParamsSize int
+	// ResponseSize the size in bytes of the JSON encoded response, only set when sizes are enabled
+	ResponseSize int
+}
+
+// Hook is invoked after every call made by a Client created with WithHook
+type Hook func(ctx context.Context, info CallInfo)
+
+type hookClient struct {
+	client       Client
+	hook         Hook
+	includeSizes bool
+}
+
+// WithHook utility method to wrap a Client so the hook is invoked after every call.
+// Params and response values are never passed to the hook, only their encoded sizes when
+// includeSizes is set. The client is returned unchanged when hook is nil.
+func WithHook(client Client, hook Hook, includeSizes bool) Client {
+	if hook == nil {
+		return client
+	}
+	return hookClient{
+		client:       client,
+		hook:         hook,
+		includeSizes: includeSizes,
+	}
+}
+
+// NewLogHook utility method to create a Hook which logs every call at debug level
+func NewLogHook() Hook {
+	return func(ctx context.Context, info CallInfo) {
+		log.Ctx(ctx).Debug().Err(info.Err).
+			Str("method", info.Method).
+			Dur("duration", info.Duration).
+			Int("paramsSize", info.ParamsSize).
+			Int("responseSize", info.ResponseSize).
+			Msg("rpc call")
+	}
+}
+
+// CountingHook counts the calls made per method
+type CountingHook struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewCountingHook utility method to create a CountingHook
+func NewCountingHook() *CountingHook {
+	return &CountingHook{counts: make(map[string]int)}
+}
+
+// Hook returns the Hook to be passed to WithHook
+func (h *CountingHook) Hook() Hook {
+	return func(ctx context.Context, info CallInfo) {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.counts[info.Method]++
+	}
+}
+
+// Count returns the number of calls made for the given method
+func (h *CountingHook) Count(method string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.counts[method]
+}
+
+// Total returns the number of calls made for all methods
+func (h *CountingHook) Total() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	total := 0
+	for _, count := range h.counts {
+		total += count
+	}
+	return total
+}
+
+func observe[T any](ctx context.Context, h hookClient, method string, params []interface{}, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	result, err := fn()
+	info := CallInfo{
+		Method:   method,
+		Duration: time.Since(start),
+		Err:      err,
+	}
+	if h.includeSizes {
+		info.ParamsSize = encodedSize(params)
+		if err == nil {
+			info.ResponseSize = encodedSize(result)
+		}
+	}
+	h.hook(ctx, info)
+	return result, err
+}
+
+func encodedSize(v interface{}) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+func (h hookClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return observe(ctx, h, "eth_chainId", nil, func() (*big.Int, error) {
+		return h.client.ChainID(ctx)
+	})
+}
+
+func (h hookClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return observe(ctx, h, "eth_getBalance", []interface{}{account, blockNumber}, func() (*big.Int, error) {
+		return h.client.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (h hookClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return observe(ctx, h, "eth_getTransactionCount", []interface{}{account, blockNumber}, func() (uint64, error) {
+		return h.client.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+func (h hookClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return observe(ctx, h, "eth_getTransactionCount", []interface{}{account, "pending"}, func() (uint64, error) {
+		return h.client.PendingNonceAt(ctx, account)
+	})
+}
+
+func (h hookClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return observe(ctx, h, "eth_getCode", []interface{}{account, blockNumber}, func() ([]byte, error) {
+		return h.client.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+func (h hookClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return observe(ctx, h, "eth_call", []interface{}{msg, blockNumber}, func() ([]byte, error) {
+		return h.client.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+func (h hookClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return observe(ctx, h, "eth_gasPrice", nil, func() (*big.Int, error) {
+		return h.client.SuggestGasPrice(ctx)
+	})
+}
+
+func (h hookClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return observe(ctx, h, "eth_maxPriorityFeePerGas", nil, func() (*big.Int, error) {
+		return h.client.SuggestGasTipCap(ctx)
+	})
+}
+
+func (h hookClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return observe(ctx, h, "eth_estimateGas", []interface{}{msg}, func() (uint64, error) {
+		return h.client.EstimateGas(ctx, msg)
+	})
+}
+
+func (h hookClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	_, err := observe(ctx, h, "eth_sendRawTransaction", []interface{}{tx}, func() (struct{}, error) {
+		return struct{}{}, h.client.SendTransaction(ctx, tx)
+	})
+	return err
+}
+
+func (h hookClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return observe(ctx, h, "eth_blockNumber", nil, func() (uint64, error) {
+		return h.client.BlockNumber(ctx)
+	})
+}
+
+func (h hookClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return observe(ctx, h, "eth_getTransactionReceipt", []interface{}{txHash}, func() (*types.Receipt, error) {
+		return h.client.TransactionReceipt(ctx, txHash)
+	})
+}
+
+func (h hookClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return observe(ctx, h, "eth_getBlockByNumber", []interface{}{number}, func() (*types.Header, error) {
+		return h.client.HeaderByNumber(ctx, number)
+	})
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+// headOnlyClient a node answering the block number right away, so that only the wrapping is measured
+type headOnlyClient struct {
+	Client
+}
+
+func (headOnlyClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return 1, nil
+}
+
+func TestWithHookNil(t *testing.T) {
+	c := headOnlyClient{}
+	// without a hook the calls are made on the client itself, with no wrapper in between
+	if WithHook(c, nil, true) != Client(c) {
+		t.Fatal("client wrapped without a hook")
+	}
+}
+
+func BenchmarkWithHook(b *testing.B) {
+	counting := NewCountingHook()
+	benchmarks := []struct {
+		name         string
+		hook         Hook
+		includeSizes bool
+	}{
+		{name: "no hook"},
+		{name: "counting hook", hook: counting.Hook()},
+		{name: "counting hook with sizes", hook: counting.Hook(), includeSizes: true},
+	}
+	ctx := context.Background()
+	for _, benchmark := range benchmarks {
+		b.Run(benchmark.name, func(b *testing.B) {
+			c := WithHook(headOnlyClient{}, benchmark.hook, benchmark.includeSizes)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = c.BlockNumber(ctx)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// methodNotFound the JSON-RPC error code of the endpoints not serving a method
+const methodNotFound = -32601
+
+// ErrPendingTransactionsUnsupported the client can not read the transaction pool of the node
+var ErrPendingTransactionsUnsupported = errors.New("pending transactions not readable")
+
+// PendingTransactionReader can be implemented by a Client reading the transaction pool of the node
+type PendingTransactionReader interface {
+	// PendingTransaction returns the transaction of the sender with the nonce waiting in the pool of the node,
+	// ethereum.NotFound when there is none
+	PendingTransaction(ctx context.Context, from common.Address, nonce uint64) (*types.Transaction, error)
+}
+
+// PendingTransaction returns the transaction of the sender with the nonce waiting in the pool of the node, read with
+// txpool_contentFrom as served by geth, bor and erigon. It fails with ethereum.NotFound when the pool has no such
+// transaction and with ErrPendingTransactionsUnsupported when the client can not read the pool.
+func PendingTransaction(ctx context.Context, client Client, from common.Address, nonce uint64) (*types.Transaction, error) {
+	switch c := client.(type) {
+	case PendingTransactionReader:
+		return c.PendingTransaction(ctx, from, nonce)
+	case *ethclient.Client:
+		return txpoolTransaction(ctx, c.Client(), from, nonce)
+	}
+	return nil, ErrPendingTransactionsUnsupported
+}
+
+// txpoolContent the pending and queued transactions of a sender keyed by their decimal nonce
+type txpoolContent struct {
+	Pending map[string]*types.Transaction `json:"pending"`
+	Queued  map[string]*types.Transaction `json:"queued"`
+}
+
+func txpoolTransaction(ctx context.Context, c *rpc.Client, from common.Address, nonce uint64) (*types.Transaction, error) {
+	var content txpoolContent
+	err := c.CallContext(ctx, &content, "txpool_contentFrom", from)
+	if err != nil {
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFound {
+			return nil, ErrPendingTransactionsUnsupported
+		}
+		return nil, err
+	}
+	key := strconv.FormatUint(nonce, 10)
+	if tx, ok := content.Pending[key]; ok && tx != nil {
+		return tx, nil
+	}
+	if tx, ok := content.Queued[key]; ok && tx != nil {
+		return tx, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (f *failoverClient) PendingTransaction(ctx context.Context, from common.Address, nonce uint64) (*types.Transaction, error) {
+	return call(ctx, f, false, func(c Client) (*types.Transaction, error) {
+		return PendingTransaction(ctx, c, from, nonce)
+	})
+}
+
+func (r retryClient) PendingTransaction(ctx context.Context, from common.Address, nonce uint64) (*types.Transaction, error) {
+	return retryValue(ctx, r.policy, func(ctx context.Context) (*types.Transaction, error) {
+		return PendingTransaction(ctx, r.Client, from, nonce)
+	})
+}
+
+func (b broadcastClient) PendingTransaction(ctx context.Context, from common.Address, nonce uint64) (*types.Transaction, error) {
+	return PendingTransaction(ctx, b.Client, from, nonce)
+}
+
+func (h hookClient) PendingTransaction(ctx context.Context, from common.Address, nonce uint64) (*types.Transaction, error) {
+	return observe(ctx, h, "txpool_contentFrom", []interface{}{from}, func() (*types.Transaction, error) {
+		return PendingTransaction(ctx, h.client, from, nonce)
+	})
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/welthee/dobermann/retry"
+)
+
+func newSignedTx(t *testing.T, nonce uint64) (*types.Transaction, common.Address) {
+	t.Helper()
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tx, err := types.SignNewTx(privateKey, types.NewLondonSigner(big.NewInt(1)), &types.DynamicFeeTx{ChainID: big.NewInt(1),
+		Nonce: nonce, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21_000, To: &to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx, crypto.PubkeyToAddress(privateKey.PublicKey)
+}
+
+func TestPendingTransaction(t *testing.T) {
+	pending, from := newSignedTx(t, 7)
+	queued, _ := newSignedTx(t, 9)
+	node := newStubNode(t, map[string]stubMethod{
+		"txpool_contentFrom": func(params []json.RawMessage) (interface{}, error) {
+			return map[string]map[string]*types.Transaction{
+				"pending": {"7": pending},
+				"queued":  {"9": queued},
+			}, nil
+		},
+	})
+	// the wrappers of the collector read the pool of the node they wrap
+	c := WithRetry(WithHook(node.dial(t), NewCountingHook().Hook(), false), retry.Policy{MaxAttempts: 2})
+
+	tests := []struct {
+		nonce uint64
+		want  *types.Transaction
+	}{
+		{nonce: 7, want: pending},
+		{nonce: 9, want: queued},
+		{nonce: 8},
+	}
+	for _, test := range tests {
+		tx, err := PendingTransaction(context.Background(), c, from, test.nonce)
+		if test.want == nil {
+			if !errors.Is(err, ethereum.NotFound) {
+				t.Fatalf("nonce %d: error %v, want %v", test.nonce, err, ethereum.NotFound)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx.Hash() != test.want.Hash() {
+			t.Fatalf("nonce %d: tx %s, want %s", test.nonce, tx.Hash(), test.want.Hash())
+		}
+	}
+}
+
+func TestPendingTransactionUnsupported(t *testing.T) {
+	// the node does not serve its pool
+	node := newStubNode(t, nil)
+	_, err := PendingTransaction(context.Background(), node.dial(t), common.Address{}, 0)
+	if !errors.Is(err, ErrPendingTransactionsUnsupported) {
+		t.Fatalf("error %v, want %v", err, ErrPendingTransactionsUnsupported)
+	}
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/welthee/dobermann/retry"
+)
+
+type retryClient struct {
+	Client
+	policy retry.Policy
+}
+
+// WithRetry utility method to wrap a Client so its read-only requests are retried with the policy, by default
+// only the transient errors as classified by retry.IsTransient. Transactions are never sent again.
+// The client is returned unchanged when the policy makes a single attempt.
+func WithRetry(client Client, policy retry.Policy) Client {
+	if policy.MaxAttempts <= 1 {
+		return client
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = retry.IsTransient
+	}
+	return retryClient{
+		Client: client,
+		policy: policy,
+	}
+}
+
+// retryValue retries fn with the policy of the client, returning its last result
+func retryValue[T any](ctx context.Context, policy retry.Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	err := retry.Do(ctx, policy, func(ctx context.Context) error {
+		var err error
+		value, err = fn(ctx)
+		return err
+	})
+	return value, err
+}
+
+func (r retryClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return retryValue(ctx, r.policy, r.Client.ChainID)
+}
+
+func (r retryClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return retryValue(ctx, r.policy, func(ctx context.Context) (*big.Int, error) {
+		return r.Client.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (r retryClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return retryValue(ctx, r.policy, func(ctx context.Context) (uint64, error) {
+		return r.Client.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+func (r retryClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return retryValue(ctx, r.policy, func(ctx context.Context) (uint64, error) {
+		return r.Client.PendingNonceAt(ctx, account)
+	})
+}
+
+func (r retryClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return retryValue(ctx, r.policy, func(ctx context.Context) ([]byte, error) {
+		return r.Client.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+func (r retryClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return retryValue(ctx, r.policy, func(ctx context.Context) ([]byte, error) {
+		return r.Client.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+func (r retryClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return retryValue(ctx, r.policy, r.Client.SuggestGasPrice)
+}
+
+func (r retryClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return retryValue(ctx, r.policy, r.Client.SuggestGasTipCap)
+}
+
+func (r retryClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return retryValue(ctx, r.policy, func(ctx context.Context) (uint64, error) {
+		return r.Client.EstimateGas(ctx, msg)
+	})
+}
+
+func (r retryClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return retryValue(ctx, r.policy, func(ctx context.Context) (*types.Receipt, error) {
+		return r.Client.TransactionReceipt(ctx, txHash)
+	})
+}
+
+func (r retryClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return retryValue(ctx, r.policy, r.Client.BlockNumber)
+}
+
+func (r retryClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return retryValue(ctx, r.policy, func(ctx context.Context) (*types.Header, error) {
+		return r.Client.HeaderByNumber(ctx, number)
+	})
+}
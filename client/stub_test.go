@@ -0,0 +1,90 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// stubError the JSON-RPC error a stub method answers with
+type stubError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e stubError) Error() string {
+	return e.Message
+}
+
+// stubMethod answers a JSON-RPC call with its result or a stubError
+type stubMethod func(params []json.RawMessage) (interface{}, error)
+
+// stubNode a JSON-RPC endpoint answering the calls with its methods, counting them. Unknown methods fail with the
+// method not found error.
+type stubNode struct {
+	*httptest.Server
+	mu      sync.Mutex
+	methods map[string]stubMethod
+	calls   map[string]int
+}
+
+func newStubNode(t *testing.T, methods map[string]stubMethod) *stubNode {
+	t.Helper()
+	node := &stubNode{methods: methods, calls: make(map[string]int)}
+	node.Server = httptest.NewServer(http.HandlerFunc(node.serve))
+	t.Cleanup(node.Close)
+	return node
+}
+
+func (n *stubNode) serve(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	n.mu.Lock()
+	n.calls[request.Method]++
+	method, ok := n.methods[request.Method]
+	n.mu.Unlock()
+
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": request.ID}
+	if !ok {
+		response["error"] = stubError{Code: methodNotFound, Message: "the method " + request.Method + " does not exist"}
+	} else if result, err := method(request.Params); err != nil {
+		stubErr, ok := err.(stubError)
+		if !ok {
+			stubErr = stubError{Code: -32000, Message: err.Error()}
+		}
+		response["error"] = stubErr
+	} else {
+		response["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// count returns the number of calls of the method
+func (n *stubNode) count(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls[method]
+}
+
+// dial returns the client of the endpoint
+func (n *stubNode) dial(t *testing.T) *ethclient.Client {
+	t.Helper()
+	c, err := ethclient.Dial(n.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
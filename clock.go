@@ -0,0 +1,52 @@
+package dobermann
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Clock abstracts the passing of time so waiting can be driven in tests
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// jitterFraction the largest part of a wait which is randomly added or removed
+const jitterFraction = 0.2
+
+// jitter randomizes the waits of the collector so that collectors started together do not poll in lockstep.
+// The random sequence is seeded, so a run can be reproduced with the same seed.
+type jitter struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// newJitter creates a jitter with the given seed, a random one when nil
+func newJitter(seed *int64) *jitter {
+	value := time.Now().UnixNano()
+	if seed != nil {
+		value = *seed
+	}
+	log.Info().Int64("seed", value).Msg("jitter seed")
+	return &jitter{rand: rand.New(rand.NewSource(value))}
+}
+
+// apply returns the duration randomly changed by up to jitterFraction of it
+func (j *jitter) apply(d time.Duration) time.Duration {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return d + time.Duration((j.rand.Float64()*2-1)*jitterFraction*float64(d))
+}
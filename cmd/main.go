@@ -1,30 +1,148 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/rs/zerolog/log"
 	"github.com/welthee/dobermann"
+	"github.com/welthee/dobermann/key"
+	kmskey "github.com/welthee/dobermann/key/kms"
 	"github.com/welthee/dobermann/key/pk"
+	pkkms "github.com/welthee/dobermann/key/pk/kms"
+	"github.com/welthee/dobermann/transactor"
 )
 
 const (
 	gasTrackerUrl = "https://gasstation-mumbai.matic.today/v2"
 	blockchainUrl = "https://polygon-mumbai.infura.io/v3/18b346558fb545a586b9a7af4a1bab19"
+
+	drainTimeout        = 30 * time.Second
+	exitCodeInterrupted = 130
 )
 
 func main() {
+	emitPlanHash := flag.Bool("emit-plan-hash", false, "write the collection plan to the plan file and print its hash without collecting")
+	planHash := flag.String("plan-hash", "", "collect only if the approved plan from the plan file matches this hash")
+	verifyPlan := flag.Bool("verify-plan", false, "print how the chain state drifted from the plan in the plan file without collecting")
+	planFile := flag.String("plan-file", "plan.json", "file where the collection plan is written to or read from")
+	reportFile := flag.String("report", "report.json", "file where the collection report is written to")
+	reproducibleReport := flag.Bool("reproducible-report", false, "write the report in its canonical form, with sorted keys, block time offsets and a content hash")
+	destinationKmsKeyId := flag.String("destination-kms-key-id", "", "KMS key ID of the destination, instead of entering its private key")
+	sourceKms := flag.Bool("source-kms", false, "enter KMS key IDs for the source accounts instead of private keys")
+	nonceProvider := flag.String("nonce-provider", string(dobermann.NonceProviderTypeNetwork), "nonce provider type, network or fixed")
+	keysFile := flag.String("keys-file", "keys.json", "JSON array of the encrypted keys checked by audit-keys")
+	kmsKeyId := flag.String("kms-key-id", "", "KMS key ID the keys checked by audit-keys are encrypted with")
+	dryRun := flag.Bool("dry-run", false, "check the balances and estimate the fees of the accounts without sending any transaction")
+	deniedAddresses := flag.String("denied-addresses", "", "file of the addresses, one per line, no tokens are moved from or to")
+	sourceBacklog := flag.String("source-backlog", "", "what happens to the sources with pending transactions, skip or proceed, not checked when empty")
+	quarantineFile := flag.String("quarantine-file", "", "file keeping the accounts failing in a row across runs, the quarantine is disabled when empty")
+	feeSpeed := flag.String("fee-speed", string(transactor.FeeSpeedSafeLow), "gas tracker tier the fees are taken from, safeLow, standard or fast")
+	noNodeFeeFallback := flag.Bool("no-node-fee-fallback", false, "fail instead of taking the fees from the node when the gas tracker is unavailable")
+	bundleUrl := flag.String("bundle-url", "", "eth_sendBundle endpoint the funding and the sweep of each account are submitted to together")
+	confirmations := flag.Uint64("confirmations", 0, "blocks the block of a collection has to be behind the head before the account is successful")
+	multicall := flag.Bool("multicall", false, "read the token balances of all the accounts upfront with the Multicall3 contract")
+	stallThreshold := flag.Duration("stall-threshold", 0, "how long the chain may produce no block before the receipt waits give up, disabled when zero")
+	flag.Parse()
+
+	// the audit does not access the blockchain, so it runs before the collector is created
+	if flag.Arg(0) == "audit-keys" {
+		err := auditKeys(*keysFile, *kmsKeyId)
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		return
+	}
+	if flag.Arg(0) == "quarantine" {
+		err := manageQuarantine(*quarantineFile, flag.Args()[1:])
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		return
+	}
 
+	nonceProviderType, err := dobermann.ParseNonceProviderType(*nonceProvider)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
 	config := dobermann.EVMCollectorConfig{
-		BlockchainUrl:     blockchainUrl,
-		GasTrackerUrl:     gasTrackerUrl,
-		NonceProviderType: dobermann.NonceProviderTypeNetwork,
-		LoggerLevel:       "debug",
+		BlockchainUrl:          blockchainUrl,
+		GasTrackerUrl:          gasTrackerUrl,
+		NonceProviderType:      nonceProviderType,
+		DryRun:                 *dryRun,
+		FeeSpeed:               transactor.FeeSpeed(*feeSpeed),
+		DisableNodeFeeFallback: *noNodeFeeFallback,
+		Confirmations:          *confirmations,
+		ChainStall:             dobermann.ChainStall{Threshold: *stallThreshold, MaxPause: 2 * *stallThreshold},
+		LoggerLevel:            "debug",
+	}
+	if *multicall {
+		address := transactor.Multicall3Address
+		config.Multicall3Address = &address
+	}
+	config.SourceBacklogPolicy = dobermann.SourceBacklogPolicy(*sourceBacklog)
+	if *deniedAddresses != "" {
+		config.Screening.Screener, err = dobermann.NewFileScreener(*deniedAddresses)
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+	}
+	if *quarantineFile != "" {
+		config.Quarantine.Store = dobermann.NewFileQuarantineStore(*quarantineFile)
+	}
+	if *bundleUrl != "" {
+		config.Bundle.Submitter, err = transactor.NewRPCBundleSubmitter(*bundleUrl)
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+	}
+	// the results of the completed accounts are kept for the report, in case the collection does not drain in time,
+	// while the daemon reports each of its runs once done
+	completed := &completedResults{}
+	if flag.Arg(0) != "daemon" {
+		config.AfterCollect = completed.add
 	}
 	collector, err := dobermann.NewEVMCollector(config)
 	if err != nil {
 		log.Fatal().Err(err).Msg("")
 	}
+	defer collector.Close()
+
+	if flag.Arg(0) == "fees" {
+		err = printFees(collector)
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		return
+	}
+	if flag.Arg(0) == "info" {
+		err = printInfo(collector)
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		return
+	}
+
+	var kmsClient *kms.Client
+	if *destinationKmsKeyId != "" || *sourceKms {
+		kmsClient, err = newKmsClient()
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+	}
 
 	fmt.Printf("Enter source accounts number: ")
 	var accountsNo int
@@ -37,14 +155,19 @@ func main() {
 	sourceAccounts := make([]dobermann.SourceAccount, 0)
 
 	for i := 0; i < accountsNo; i++ {
-		fmt.Printf("Enter source private key: ")
-		var key string
+		var keyProvider key.Provider
+		if *sourceKms {
+			fmt.Printf("Enter source KMS key ID: ")
+			var keyId string
 
-		_, err = fmt.Scanln(&key)
-		if err != nil {
-			log.Fatal().Err(err).Msg("")
+			_, err = fmt.Scanln(&keyId)
+			if err != nil {
+				log.Fatal().Err(err).Msg("")
+			}
+			keyProvider, err = kmskey.NewKmsKeyProvider(kmsClient, keyId, collector.GetChainId(context.TODO()))
+		} else {
+			keyProvider, err = readPrivateKeyProvider("Enter source private key: ", collector)
 		}
-		keyProvider, err := pk.NewPrivateKeyProvider(key, collector.GetChainId(context.TODO()))
 		if err != nil {
 			log.Fatal().Err(err).Msg("")
 		}
@@ -71,15 +194,13 @@ func main() {
 		sourceAccounts = append(sourceAccounts, sourceAccount)
 	}
 
-	fmt.Printf("Enter destination private key: ")
-	var key string
-
-	_, err = fmt.Scanln(&key)
-	if err != nil {
-		log.Fatal().Err(err).Msg("")
+	var collectionKeyProvider key.Provider
+	if *destinationKmsKeyId != "" {
+		collectionKeyProvider, err = kmskey.NewKmsKeyProvider(kmsClient, *destinationKmsKeyId,
+			collector.GetChainId(context.TODO()))
+	} else {
+		collectionKeyProvider, err = readPrivateKeyProvider("Enter destination private key: ", collector)
 	}
-	collectionKeyProvider, err := pk.NewPrivateKeyProvider(key,
-		collector.GetChainId(context.TODO()))
 	if err != nil {
 		log.Fatal().Err(err).Msg("")
 	}
@@ -88,16 +209,430 @@ func main() {
 		KeyProvider: collectionKeyProvider,
 	}
 
-	result := collector.Collect(context.TODO(), collectionKey, sourceAccounts)
-	if len(result) == 0 {
-		panic("panic")
+	reportOptions := dobermann.ReportOptions{Reproducible: *reproducibleReport}
+	if flag.Arg(0) == "daemon" {
+		err = runDaemon(collector, collectionKey, sourceAccounts, *reportFile, reportOptions, flag.Args()[1:])
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		return
 	}
 
-	for _, r := range result {
-		log.Info().Interface("result", r.Status).Msg("got")
-		if string(r.Status) == "" {
-			panic("panic")
+	if *emitPlanHash {
+		plan, err := collector.Plan(context.TODO(), collectionKey, sourceAccounts)
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		err = os.WriteFile(*planFile, data, 0o644)
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		hash, err := plan.Hash()
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
 		}
+		fmt.Println(hash)
+		return
 	}
 
+	if *verifyPlan {
+		err = printPlanDrift(collector, collectionKey, sourceAccounts, *planFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		return
+	}
+
+	var approved *dobermann.Plan
+	if *planHash != "" {
+		data, err := os.ReadFile(*planFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		approved = &dobermann.Plan{}
+		err = json.Unmarshal(data, approved)
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+	}
+
+	run := interruptibleRun{
+		collector:     collector,
+		accounts:      sourceAccounts,
+		completed:     completed,
+		drainTimeout:  drainTimeout,
+		reportFile:    *reportFile,
+		reportOptions: reportOptions,
+		exit:          os.Exit,
+	}
+	code := run.run(notifySignals(), func(ctx context.Context) ([]dobermann.Result, error) {
+		if approved == nil {
+			return collector.Collect(ctx, collectionKey, sourceAccounts), nil
+		}
+		return collector.CollectPlan(ctx, collectionKey, sourceAccounts, *approved, *planHash)
+	})
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
+// interruptibleRun a collection of the tool, cancelled on the first SIGINT or SIGTERM
+type interruptibleRun struct {
+	collector dobermann.Collector
+	accounts  []dobermann.SourceAccount
+	// completed the results of the AfterCollect hook, reported when the collection does not drain in time
+	completed     *completedResults
+	drainTimeout  time.Duration
+	reportFile    string
+	reportOptions dobermann.ReportOptions
+	// exit ends the process on the second signal
+	exit func(code int)
+}
+
+// run collects with collect until it returns or, once interrupted, for at most the drain timeout, then writes the
+// report and returns the exit code of the tool. When the collection does not drain the collector is closed, so that
+// the collections still waiting for their receipts give up, and the accounts not completed are reported as
+// StatusInterrupted.
+func (r interruptibleRun) run(signals <-chan os.Signal, collect func(ctx context.Context) ([]dobermann.Result, error)) int {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupted, stop := handleSignals(signals, cancel, r.exit)
+	defer stop()
+
+	type collected struct {
+		results []dobermann.Result
+		err     error
+	}
+	done := make(chan collected, 1)
+	go func() {
+		results, err := collect(ctx)
+		done <- collected{results: results, err: err}
+	}()
+
+	var outcome collected
+	select {
+	case outcome = <-done:
+	case <-ctx.Done():
+		select {
+		case outcome = <-done:
+		case <-time.After(r.drainTimeout):
+			log.Warn().Msg("collection did not drain in time")
+			outcome.results = r.completed.results(r.accounts)
+			err := r.collector.Close()
+			if err != nil {
+				log.Warn().Err(err).Msg("failed to close the collector")
+			}
+		}
+	}
+	result := outcome.results
+	if outcome.err != nil {
+		log.Error().Err(outcome.err).Msg("collection failed")
+		result = failedResults(r.accounts, outcome.err)
+	}
+
+	err := writeReport(r.reportFile, result, r.collector.Info(), r.reportOptions)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to write report")
+	}
+
+	for _, res := range result {
+		log.Info().Interface("result", res.Status).Msg("got")
+	}
+
+	switch {
+	case interrupted.Load():
+		return exitCodeInterrupted
+	case outcome.err != nil:
+		return 1
+	}
+	return 0
+}
+
+// completedResults the results of the accounts completed so far, reported when the collection does not drain
+type completedResults struct {
+	mu   sync.Mutex
+	list []dobermann.Result
+}
+
+// add is the AfterCollect hook recording the result
+func (c *completedResults) add(ctx context.Context, result dobermann.Result) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.list = append(c.list, result)
+	return nil
+}
+
+// results returns the result of each account, StatusInterrupted for the ones not completed. The results are
+// matched to the accounts by source address and token, the Amount of an approved plan replacing the entered one.
+func (c *completedResults) results(accounts []dobermann.SourceAccount) []dobermann.Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	used := make([]bool, len(c.list))
+	results := make([]dobermann.Result, 0, len(accounts))
+	for _, account := range accounts {
+		result := dobermann.Result{
+			Status:        dobermann.StatusInterrupted,
+			Reason:        dobermann.ReasonInterrupted,
+			Message:       dobermann.ReasonInterrupted.Description(),
+			SourceAccount: account,
+		}
+		for i, completed := range c.list {
+			if !used[i] && sameAccount(completed.SourceAccount, account) {
+				used[i] = true
+				result = completed
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// sameAccount checks if both accounts collect the same token of the same source
+func sameAccount(a dobermann.SourceAccount, b dobermann.SourceAccount) bool {
+	return *a.KeyProvider.GetAddress() == *b.KeyProvider.GetAddress() && strings.EqualFold(a.Token, b.Token)
+}
+
+// failedResults returns a failed result for each account, the collection having failed before collecting them
+func failedResults(accounts []dobermann.SourceAccount, err error) []dobermann.Result {
+	reason := dobermann.ReasonNone
+	if errors.Is(err, dobermann.ErrPlanDrift) {
+		reason = dobermann.ReasonPlanDrift
+	}
+	results := make([]dobermann.Result, 0, len(accounts))
+	for _, account := range accounts {
+		results = append(results, dobermann.Result{
+			Status:        dobermann.StatusFail,
+			Reason:        reason,
+			Phase:         dobermann.PhaseValidation,
+			Message:       err.Error(),
+			Err:           err,
+			SourceAccount: account,
+		})
+	}
+	return results
+}
+
+// newKmsClient creates a KMS client from the default AWS configuration, e.g. the AWS_REGION
+// and credentials environment variables
+func newKmsClient() (*kms.Client, error) {
+	awsConfig, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	return kms.NewFromConfig(awsConfig), nil
+}
+
+// readPrivateKeyProvider prompts for a private key and creates its key provider
+func readPrivateKeyProvider(prompt string, collector dobermann.Collector) (key.Provider, error) {
+	fmt.Print(prompt)
+	var privateKey string
+
+	_, err := fmt.Scanln(&privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return pk.NewPrivateKeyProvider(privateKey, collector.GetChainId(context.TODO()))
+}
+
+// notifySignals returns the channel receiving the SIGINT and SIGTERM of the process
+func notifySignals() <-chan os.Signal {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	return signals
+}
+
+// handleSignals cancels the collection on the first signal and exits immediately on the second one,
+// until stop is called
+func handleSignals(signals <-chan os.Signal, cancel context.CancelFunc, exit func(code int)) (interrupted *atomic.Bool, stop func()) {
+	interrupted = &atomic.Bool{}
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+		case <-stopped:
+			return
+		}
+		interrupted.Store(true)
+		log.Warn().Msg("interrupted, waiting for in-flight collections to drain")
+		cancel()
+
+		select {
+		case <-signals:
+		case <-stopped:
+			return
+		}
+		log.Warn().Msg("interrupted again, exiting immediately")
+		exit(exitCodeInterrupted)
+	}()
+	var once sync.Once
+	return interrupted, func() {
+		once.Do(func() { close(stopped) })
+	}
+}
+
+// runDaemon collects the entered accounts on the schedule until interrupted, writing the report of each run
+func runDaemon(collector dobermann.Collector, destination dobermann.DestinationAccount,
+	accounts []dobermann.SourceAccount, reportFile string, reportOptions dobermann.ReportOptions, args []string) error {
+	flags := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	scheduleValue := flags.String("schedule", "", "interval, e.g. 15m, or cron expression, e.g. \"0 3 * * *\", of the runs")
+	overlap := flags.String("overlap", string(dobermann.OverlapSkip), "what happens to a run due while the previous one is running, skip or queue")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	schedule, err := dobermann.ParseSchedule(*scheduleValue)
+	if err != nil {
+		return err
+	}
+
+	fetcher := dobermann.AccountFetcherFunc(func(ctx context.Context) (dobermann.DestinationAccount, []dobermann.SourceAccount, error) {
+		return destination, accounts, nil
+	})
+	scheduler, err := dobermann.NewScheduledCollector(collector, fetcher, schedule,
+		dobermann.WithOverlapPolicy(dobermann.OverlapPolicy(*overlap)),
+		dobermann.WithRunHandler(func(ctx context.Context, run dobermann.ScheduledRun, results []dobermann.Result) {
+			err := writeReport(reportFile, results, collector.Info(), reportOptions)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to write report")
+			}
+		}))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, stop := handleSignals(notifySignals(), cancel, os.Exit)
+	defer stop()
+	err = scheduler.Start(ctx)
+	if err != nil {
+		return err
+	}
+	<-ctx.Done()
+	scheduler.Stop()
+	return nil
+}
+
+// printFees prints the current fee quote as JSON
+func printFees(collector dobermann.Collector) error {
+	quote, err := collector.CurrentFees(context.TODO())
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(quote, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// printPlanDrift prints as JSON how the chain state drifted from the plan of the file
+func printPlanDrift(collector dobermann.Collector, destination dobermann.DestinationAccount, accounts []dobermann.SourceAccount, planFile string) error {
+	data, err := os.ReadFile(planFile)
+	if err != nil {
+		return err
+	}
+	var plan dobermann.Plan
+	err = json.Unmarshal(data, &plan)
+	if err != nil {
+		return err
+	}
+	report, err := collector.PlanVerify(context.TODO(), destination, accounts, plan)
+	if err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func printInfo(collector dobermann.Collector) error {
+	data, err := json.MarshalIndent(collector.Info(), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// auditKeys prints as JSON whether each encrypted key of the file decrypts to its expected address,
+// never printing the keys
+func auditKeys(path string, kmsKeyId string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var specs []pkkms.KeySpec
+	err = json.Unmarshal(data, &specs)
+	if err != nil {
+		return err
+	}
+	kmsClient, err := newKmsClient()
+	if err != nil {
+		return err
+	}
+
+	results := pkkms.AuditKeys(context.TODO(), pkkms.NewKmsDecrypter(kmsClient, kmsKeyId), specs, pkkms.AuditOptions{})
+	output, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
+
+// manageQuarantine runs "quarantine list", printing the entries as JSON, or
+// "quarantine clear <address> [token]", removing the entries of the address
+func manageQuarantine(path string, args []string) error {
+	if path == "" {
+		return errors.New("quarantine file not set")
+	}
+	store := dobermann.NewFileQuarantineStore(path)
+	if len(args) == 0 {
+		return errors.New("usage: quarantine list | quarantine clear <address> [token]")
+	}
+	switch args[0] {
+	case "list":
+		entries, err := store.List(context.TODO())
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	case "clear":
+		if len(args) < 2 {
+			return errors.New("usage: quarantine clear <address> [token]")
+		}
+		token := ""
+		if len(args) > 2 {
+			token = args[2]
+		}
+		return store.Clear(context.TODO(), args[1], token)
+	default:
+		return fmt.Errorf("unknown quarantine command %s", args[0])
+	}
+}
+
+func writeReport(path string, result []dobermann.Result, info dobermann.CollectorInfo, options dobermann.ReportOptions) error {
+	report := dobermann.NewRunReport(result)
+	report.Collector = &info
+	var data bytes.Buffer
+	err := dobermann.WriteReport(&data, report, options)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data.Bytes(), 0o644)
 }
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/welthee/dobermann"
+	"github.com/welthee/dobermann/dobermanntest"
+	"github.com/welthee/dobermann/key"
+	"github.com/welthee/dobermann/key/pk"
+)
+
+const testToken = "0x00000000000000000000000000000000000000aa"
+
+// pendingChain a chain where the transactions of the sender are never mined, announced on waited once their
+// receipt is first asked for
+type pendingChain struct {
+	*dobermanntest.Chain
+	sender common.Address
+
+	mu      sync.Mutex
+	pending map[common.Hash]bool
+	waited  chan struct{}
+	once    sync.Once
+}
+
+func (c *pendingChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+	if from != c.sender {
+		return c.Chain.SendTransaction(ctx, tx)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[tx.Hash()] = true
+	return nil
+}
+
+func (c *pendingChain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	pending := c.pending[txHash]
+	c.mu.Unlock()
+	if pending {
+		c.once.Do(func() { close(c.waited) })
+		return nil, ethereum.NotFound
+	}
+	return c.Chain.TransactionReceipt(ctx, txHash)
+}
+
+func newTestKeyProvider(t *testing.T, chainID *big.Int) key.Provider {
+	t.Helper()
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err := pk.NewPrivateKeyProvider(common.Bytes2Hex(crypto.FromECDSA(privateKey)), chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestInterruptibleRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals int
+		exits   []int
+	}{
+		{name: "interrupted", signals: 1},
+		{name: "interrupted twice", signals: 2, exits: []int{exitCodeInterrupted}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chainID := big.NewInt(137)
+			destination := newTestKeyProvider(t, chainID)
+			accounts := make([]dobermann.SourceAccount, 3)
+			for i := range accounts {
+				accounts[i] = dobermann.SourceAccount{KeyProvider: newTestKeyProvider(t, chainID), Token: testToken}
+			}
+			// the first account is collected, the sweep of the second one stays pending and the third one waits
+			chain := &pendingChain{Chain: dobermanntest.NewChain(chainID), sender: *accounts[1].KeyProvider.GetAddress(),
+				pending: make(map[common.Hash]bool), waited: make(chan struct{})}
+			chain.SetBalance(*destination.GetAddress(), big.NewInt(1e18))
+			for _, account := range accounts {
+				chain.SetTokenBalance(common.HexToAddress(testToken), *account.KeyProvider.GetAddress(), big.NewInt(100))
+			}
+
+			completed := &completedResults{}
+			collector, err := dobermann.NewEVMCollector(dobermann.EVMCollectorConfig{
+				Client:              chain,
+				GasTracker:          dobermanntest.NewGasTracker(30, 90),
+				NonceProviderType:   dobermann.NonceProviderTypeNetwork,
+				ReceiptPollInterval: 5 * time.Millisecond,
+				ConfirmationTimeout: time.Minute,
+				AfterCollect:        completed.add,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer collector.Close()
+
+			var mu sync.Mutex
+			var exits []int
+			exited := make(chan struct{}, 1)
+			run := interruptibleRun{
+				collector:    collector,
+				accounts:     accounts,
+				completed:    completed,
+				drainTimeout: 200 * time.Millisecond,
+				reportFile:   filepath.Join(t.TempDir(), "report.json"),
+				exit: func(code int) {
+					mu.Lock()
+					defer mu.Unlock()
+					exits = append(exits, code)
+					exited <- struct{}{}
+				},
+			}
+
+			signals := notifySignals()
+			sent := make(chan struct{})
+			go func(count int) {
+				defer close(sent)
+				<-chain.waited
+				for i := 0; i < count; i++ {
+					// the signals are sent to the test process itself
+					err := syscall.Kill(os.Getpid(), syscall.SIGINT)
+					if err != nil {
+						t.Error(err)
+					}
+					if i < count-1 {
+						// the first signal is received before the second one is sent
+						time.Sleep(20 * time.Millisecond)
+					}
+				}
+			}(test.signals)
+			returned := make(chan struct{})
+			code := run.run(signals, func(ctx context.Context) ([]dobermann.Result, error) {
+				defer close(returned)
+				return collector.Collect(ctx, dobermann.DestinationAccount{KeyProvider: destination}, accounts), nil
+			})
+			<-sent
+			// the collector closed after the drain timeout stops the collection waiting for its receipt
+			select {
+			case <-returned:
+			case <-time.After(5 * time.Second):
+				t.Fatal("collection still running after the drain timeout")
+			}
+			if len(test.exits) > 0 {
+				<-exited
+			}
+
+			if code != exitCodeInterrupted {
+				t.Fatalf("exit code %d, want %d", code, exitCodeInterrupted)
+			}
+			mu.Lock()
+			if len(exits) != len(test.exits) || (len(exits) > 0 && exits[0] != test.exits[0]) {
+				t.Fatalf("exits %v, want %v", exits, test.exits)
+			}
+			mu.Unlock()
+			data, err := os.ReadFile(run.reportFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var report dobermann.RunReport
+			err = json.Unmarshal(data, &report)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []dobermann.Status{dobermann.StatusSuccess, dobermann.StatusInterrupted, dobermann.StatusInterrupted}
+			if len(report.Results) != len(want) {
+				t.Fatalf("%d results, want %d", len(report.Results), len(want))
+			}
+			for i, entry := range report.Results {
+				if entry.Status != want[i] || entry.Account != accounts[i].KeyProvider.GetAddress().Hex() {
+					t.Fatalf("result %d: %s %s, want %s %s", i, entry.Account, entry.Status,
+						accounts[i].KeyProvider.GetAddress().Hex(), want[i])
+				}
+			}
+		})
+	}
+}
@@ -2,55 +2,245 @@ package dobermann
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/client"
 	"github.com/welthee/dobermann/key"
 	"github.com/welthee/dobermann/nonce"
+	"github.com/welthee/dobermann/retry"
 	"github.com/welthee/dobermann/transactor"
 	"math/big"
 	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	nonceTooLow                       = "nonce too low"
-	alreadyKnown                      = "already known"
-	replacementTransactionUnderpriced = "replacement transaction underpriced"
-	minLogLevel                       = zerolog.Disabled
+	minLogLevel         = zerolog.Disabled
+	maxFundingTxTagSize = 32
+	// defaultConfirmationTimeout how long a sent transaction is waited for before the account is left pending
+	defaultConfirmationTimeout = 2 * time.Minute
+	// defaultFeeQuoteTTL how long CurrentFees returns the same quote
+	defaultFeeQuoteTTL = 10 * time.Second
 )
 
+const (
+	StatusFail                   Status            = "fail"
+	StatusSuccess                Status            = "success"
+	StatusPending                Status            = "pending"
+	StatusSkip                   Status            = "skip"
+	StatusTokenPaused            Status            = "token_paused"
+	StatusInterrupted            Status            = "interrupted"
+	StatusVetoed                 Status            = "vetoed"
+	StatusDeferred               Status            = "deferred"
+	StatusFundingReverted        Status            = "funding_reverted"
+	StatusDestinationNotEligible Status            = "destination_not_eligible"
+	StatusQuarantined            Status            = "quarantined"
+	StatusSimulated              Status            = "simulated"
+	StatusCancelled              Status            = "cancelled"
+	StatusDenied                 Status            = "denied"
+	NonceProviderTypeFixed       NonceProviderType = "fixed"
+	NonceProviderTypeNetwork     NonceProviderType = "network"
+)
+
+// statuses all the Status values, in the order they were introduced
+var statuses = []Status{
+	StatusFail, StatusSuccess, StatusPending, StatusSkip, StatusTokenPaused, StatusInterrupted,
+	StatusVetoed, StatusDeferred, StatusFundingReverted, StatusDestinationNotEligible, StatusQuarantined,
+	StatusSimulated, StatusCancelled, StatusDenied,
+}
+
 var (
-	StatusFail               Status            = "fail"
-	StatusSuccess            Status            = "success"
-	StatusPending            Status            = "pending"
-	StatusSkip               Status            = "skip"
-	NonceProviderTypeFixed   NonceProviderType = "fixed"
-	NonceProviderTypeNetwork NonceProviderType = "network"
+	ErrNilKeyProvider = errors.New("key provider not set")
+	// ErrUnknownStatus the value is not one of the Status values
+	ErrUnknownStatus = errors.New("unknown status")
+	// ErrUnknownNonceProviderType the value is not one of the NonceProviderType values
+	ErrUnknownNonceProviderType = errors.New("unknown nonce provider type")
+	// ErrInsufficientBalance the source account holds less tokens than the requested amount
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrInsufficientAllowance the source account approved less tokens than the requested amount
+	ErrInsufficientAllowance = errors.New("insufficient allowance")
+	// ErrFundingReverted the funding transaction was mined but reverted
+	ErrFundingReverted = errors.New("funding transaction reverted")
+	// ErrTokensWithoutToken the source account lists further Tokens without a Token
+	ErrTokensWithoutToken = errors.New("tokens set without a token")
 )
 
 // Collector provides method to collect ERC-20 tokens in a specific account from other given accounts
 type Collector interface {
 	Collect(ctx context.Context, collectionAcount DestinationAccount, accounts []SourceAccount) []Result
+	// CollectWithController collects like Collect, the collection of single source accounts can be cancelled
+	// through the controller while the run goes on
+	CollectWithController(ctx context.Context, controller *RunController, destinationAccount DestinationAccount, accounts []SourceAccount) []Result
 	GetChainId(ctx context.Context) *big.Int
+	// Refresh re-validates the node connection and re-reads the chain ID and the gas tracker,
+	// without recreating the collector
+	Refresh(ctx context.Context) error
+	// Plan resolves the amounts which would be collected and the current gas fees without sending any transaction
+	Plan(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount) (*Plan, error)
+	// CollectPlan collects only when the approved plan matches the expected hash and the plan
+	// regenerated from the current chain state is within the configured PlanTolerance
+	CollectPlan(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount, approved Plan, expectedHash string) ([]Result, error)
+	// CollectAsync collects like Collect, sending the result of each account on the channel as soon as it is done
+	// and closing the channel after the last one
+	CollectAsync(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount) (<-chan Result, error)
+	// CollectStream collects the accounts of the source chunk by chunk, writing the results of each chunk to the
+	// sink and returning only the summary of the run
+	CollectStream(ctx context.Context, destinationAccount DestinationAccount, source AccountSource, sink ResultSink) (RunReport, error)
+	// PlanVerify reports how the balances, the token metadata and the destination eligibility drifted from the plan
+	PlanVerify(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount, plan Plan) (*DriftReport, error)
+	// Pull transfers the tokens the source accounts approved to the destination, see CollectStrategyApprove
+	Pull(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount) []Result
+	// VerifyRun compares, per token, the balance increase of the destination between the start and the end
+	// block of the run with the amounts the report shows as collected
+	VerifyRun(ctx context.Context, report RunReport) (ReconciliationReport, error)
+	// CurrentFees returns the fees currently suggested by the gas tracker, without collecting
+	CurrentFees(ctx context.Context) (*FeeQuote, error)
+	// Info returns the effective configuration of the collector, with the endpoints redacted
+	Info() CollectorInfo
+	// Close releases the resources of the collector, e.g. the shared receipt watcher
+	Close() error
 }
 
 type Status string
 type NonceProviderType string
 
+// ParseStatus returns the Status of the value, ignoring its case and accepting dashes for underscores,
+// e.g. "Token-Paused" is StatusTokenPaused
+func ParseStatus(value string) (Status, error) {
+	normalized := normalizeEnum(value)
+	for _, status := range statuses {
+		if string(status) == normalized {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownStatus, value)
+}
+
+// ParseNonceProviderType returns the NonceProviderType of the value ignoring its case, e.g. "Network"
+func ParseNonceProviderType(value string) (NonceProviderType, error) {
+	switch NonceProviderType(normalizeEnum(value)) {
+	case NonceProviderTypeFixed:
+		return NonceProviderTypeFixed, nil
+	case NonceProviderTypeNetwork:
+		return NonceProviderTypeNetwork, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownNonceProviderType, value)
+	}
+}
+
+// normalizeEnum lowercases the value and replaces dashes and spaces with underscores
+func normalizeEnum(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.NewReplacer("-", "_", " ", "_").Replace(value)
+}
+
 // Result the outcome of the ERC-20 collection for a SourceAccount
 type Result struct {
-	Status        Status
+	Status Status
+	Reason ReasonCode
+	// Message the human readable explanation of the Reason, the error message for failures
+	Message string
+	// Err the error which caused the failure, nil unless the account failed
+	Err           error
 	SourceAccount SourceAccount
+	// Phase the step of the collection in which an error occurred
+	Phase Phase
+	// ReclaimStatus the outcome of the native reclaim step, empty when no reclaim was attempted
+	ReclaimStatus Status
+	// AfterCollectErr the error returned by the AfterCollect hook
+	AfterCollectErr error
+	// ApprovedAmount the wei amount the destination was allowed to pull, set by CollectStrategyApprove
+	ApprovedAmount string
+	// FundingTxTag the hex encoded EVMCollectorConfig.FundingTxTag, set when the account was funded
+	FundingTxTag string
+	// CollectedAmount the wei amount transferred to the destination, set when the tokens were collected
+	CollectedAmount string
+	// Destination the address of the destination account, the same for all the results of a run
+	Destination string
+	// StartBlock and EndBlock the latest blocks when the run started and ended, the same for all
+	// the results of a run, zero when they could not be read
+	StartBlock uint64
+	EndBlock   uint64
+	// BlockNumber and BlockTime the block the collection transaction was mined in, set on success
+	// unless the block could not be read
+	BlockNumber uint64
+	BlockTime   time.Time
+	// FundingBlockNumber and FundingBlockTime the block the funding transaction was mined in
+	FundingBlockNumber uint64
+	FundingBlockTime   time.Time
+	// GasUsed by the collection transaction, set on success
+	GasUsed uint64
+	// Fee the wei paid for the gas of the collection transaction, set on success unless it could not be derived
+	Fee string
+	// FeeDerived the node omitted the effective gas price from the receipt, the Fee was derived from the
+	// transaction fees and the base fee of its block
+	FeeDerived bool
+	// ResolvedAmount the wei amount which would be collected, set by dry runs
+	ResolvedAmount string
+	// EstimatedFee the most the sweep and the funding cost, set once the transfer was built
+	EstimatedFee string
+	// FundingAmount the wei the destination sends to the source, set when the account needs funding
+	FundingAmount string
+	// TxHash the hash of the ERC-20 transaction, set once it was sent or attempted to be sent,
+	// the replacement when the transfer was replaced
+	TxHash string
+	// FundingTxHash the hash of the funding transaction, set when one was sent
+	FundingTxHash string
+	// PermitTxHash the hash of the permit transaction sent instead of the funding, see SourceAccount UsePermit
+	PermitTxHash string
+	// Bundled the funding and the collection transaction were mined together in a bundle
+	Bundled bool
+	// SourceBacklog the transactions of the source found pending before it was funded, see SourceBacklogPolicy
+	SourceBacklog uint64
+	// Tokens the results of the SourceAccount Tokens in their order, while the result itself is the one of its Token.
+	// For an ERC721 account, the results of the tokens after the first one, each SourceAccount having its TokenIDs.
+	Tokens []Result
 }
 
 // SourceAccount keeps the details of the account from which the tokens are collected
 type SourceAccount struct {
 	KeyProvider key.Provider
-	Token       string
-	Amount      string
+	// Token the ERC-20 token address, the native balance is swept when empty
+	Token  string
+	Amount string
+	// GasLimit of the transfer, when set the gas estimation and the GasLimitMultiplier are skipped and the account
+	// is funded for it, e.g. for a token whose estimates fall short
+	GasLimit uint64
+	// Wallet the contract wallet holding the tokens, the KeyProvider is then the operator
+	// allowed to execute calls through the wallet, see EVMCollectorConfig.ExecutorCalldata
+	Wallet *common.Address
+	// GroupKey accounts sharing the same non-empty key are funded together, with funding transactions sent
+	// under sequential nonces without waiting in between, when the first of them is collected
+	GroupKey string
+	// CollectNative sends the native balance left on the account after a successful collection to the
+	// destination, like ReclaimNative does for all the accounts
+	CollectNative bool
+	// Tokens further ERC-20 tokens collected with their whole balance after the Token in the same pass, the
+	// account being funded once for all the transfers, which are sent under consecutive nonces
+	Tokens []string
+	// UsePermit collects the Token without funding the source, the destination sends an EIP-2612 permit signed
+	// by the KeyProvider and then pulls the tokens with transferFrom. The account is funded as usual when the
+	// token or the KeyProvider, which has to implement key.HashSigner, do not support it. It is ignored for
+	// contract wallets, accounts with Tokens and CollectStrategyApprove.
+	UsePermit bool
+	// ERC721 the Token is an ERC-721 collection whose tokens are transferred one by one with safeTransferFrom,
+	// funding the account once for all of them. It is not supported for contract wallets nor with Tokens, and
+	// the tokens are transferred with CollectStrategyApprove as well.
+	ERC721 bool
+	// TokenIDs the decimal ids of the ERC-721 tokens to collect, the ones not owned by the account are skipped.
+	// All the tokens of the account are collected when empty, which requires an enumerable collection.
+	TokenIDs []string
 }
 
 // DestinationAccount which provides the gas for the collection and receives the ERC-20 tokens
@@ -60,13 +250,173 @@ type DestinationAccount struct {
 
 // EVMCollectorConfig contains network configuration
 type EVMCollectorConfig struct {
-	BlockchainUrl     string
-	GasTrackerUrl     string
+	BlockchainUrl string
+	// BlockchainUrls additional endpoints used as failover when BlockchainUrl is not reachable
+	BlockchainUrls []string
+	// BroadcastUrls additional endpoints every sent transaction is also submitted to, without waiting for them,
+	// in case the node accepting it does not propagate it. Their failures are only logged
+	BroadcastUrls []string
+	// BroadcastTimeout of each secondary broadcast, five seconds when zero
+	BroadcastTimeout time.Duration
+	// Client the node used instead of dialing the BlockchainUrl and the BlockchainUrls, e.g. a dobermanntest.Chain.
+	// The RPCHook and the Retry policy still apply to it.
+	Client        client.Client
+	GasTrackerUrl string
+	// GasTracker used instead of the Polygon gas tracker of the GasTrackerUrl, e.g. a dobermanntest.GasTracker
+	GasTracker transactor.GasTracker
+	// GasTrackerMaxResponseSize the most bytes read from a gas tracker response, 1MB when zero
+	GasTrackerMaxResponseSize int64
+	// Retry the policies of the gas tracker requests and of the read-only node requests, see retry.Component.
+	// Nothing is retried by default
+	Retry retry.Policies
+	// NonceProviderType NonceProviderTypeFixed when empty, other values than the NonceProviderType
+	// constants fail the creation of the collector, see ParseNonceProviderType
 	NonceProviderType NonceProviderType
 	LoggerKind        string
 	LoggerLevel       string
+	// DetectPausedTokens enables short-circuiting the remaining accounts of a token
+	// once a transfer of that token failed with a pause-like revert
+	DetectPausedTokens bool
+	// FundingTxTag is sent as calldata of the funding transactions to identify them, at most 32 bytes
+	FundingTxTag []byte
+	// FundingBuffer is added in wei on top of the missing gas when funding a source account
+	FundingBuffer *big.Int
+	// MinimumFundingAmount is the smallest amount in wei sent in a funding transaction,
+	// smaller deficits are rounded up to it
+	MinimumFundingAmount *big.Int
+	// RetryRevertedFunding sends the funding transaction once more with fees bumped by 10%
+	// when it was mined but reverted
+	RetryRevertedFunding bool
+	// ReclaimNative sends the native balance left on a source account back to the
+	// destination after a successful collection
+	ReclaimNative bool
+	// MinReclaimAmount is the wei which has to remain above the reclaim transaction
+	// gas cost for the reclaim to be made, smaller amounts are left on the account
+	MinReclaimAmount *big.Int
+	// PlanTolerance the accepted changes between an approved Plan and the executed one
+	PlanTolerance PlanTolerance
+	// DriftPolicy the drifts of the chain state from a Plan detected by PlanVerify and what CollectPlan does about them
+	DriftPolicy DriftPolicy
+	// Multicall3Address the Multicall3 contract of the chain, e.g. transactor.Multicall3Address. When set, the token
+	// balances of all the accounts are read upfront with one call per token, and the accounts holding none are
+	// skipped without being collected. The balances are only read while collecting each account when nil.
+	Multicall3Address *common.Address
+	// RPCHook is invoked after every call made to a blockchain node, see client.NewLogHook
+	RPCHook client.Hook
+	// RPCHookSizes includes the encoded params and response sizes in the RPCHook calls
+	RPCHookSizes bool
+	// ReplaceChangedTransfers replaces the ERC-20 transfer of an account pending in the node, for the same token and
+	// destination, when the amount changed, e.g. when the collection is run again with another amount. The
+	// replacement reuses the nonce with bumped fees. The pending transfer is read from the node transaction pool
+	// with txpool_contentFrom, any other transaction pending under the nonce is never replaced.
+	ReplaceChangedTransfers bool
+	// MaxFeeCapMultiplier is applied to the gas tracker max fee, e.g. 1.25, to give the fee cap headroom
+	// while the base fee rises. The tip (maxPriorityFeePerGas) is what is paid on top of the base fee and
+	// stays as quoted, while the fee cap (maxFeePerGas) is only the ceiling of what may be paid per gas.
+	// Defaults to 1, values below 1 are rejected.
+	MaxFeeCapMultiplier float64
+	// GasLimitMultiplier is applied to the estimated gas limit of the token transfers, e.g. 1.2, so that the tokens
+	// whose gas depends on the balances, e.g. rebasing or fee-on-transfer tokens, do not run out of gas. The funding
+	// covers the multiplied limit. The SourceAccount GasLimit overrides it. Defaults to 1, values below 1 are rejected.
+	GasLimitMultiplier float64
+	// FeeSpeed the tier of the gas tracker suggestion the fees are taken from, transactor.FeeSpeedSafeLow when empty
+	FeeSpeed transactor.FeeSpeed
+	// FeeQuoteTTL how long CurrentFees returns the same quote before asking the gas tracker again, 10 seconds
+	// when zero. A negative TTL disables the cache.
+	FeeQuoteTTL time.Duration
+	// DisableNodeFeeFallback fails the fee lookups when the gas tracker fails. By default the node suggested tip
+	// is used instead, with a fee cap of twice the latest base fee plus the tip
+	DisableNodeFeeFallback bool
+	// NodeGasPriceTTL how long the minimum gas price and tip suggested by the node, which the fees of the transactions
+	// are raised to, are reused before being read again, 5 seconds when zero. A negative TTL reads them for each
+	// transaction.
+	NodeGasPriceTTL time.Duration
+	// GasTipCapWei is used as tip instead of the gas tracker suggestion
+	GasTipCapWei *big.Int
+	// GasTipCapGwei is GasTipCapWei in gwei, e.g. "1.5", only one of the two can be set
+	GasTipCapGwei string
+	// MaxGasFeeCapWei limits the fee cap of every transaction
+	MaxGasFeeCapWei *big.Int
+	// MaxGasFeeCapGwei is MaxGasFeeCapWei in gwei, e.g. "300", only one of the two can be set
+	MaxGasFeeCapGwei string
+	// PreBroadcast is invoked before every transaction is sent, returning an error vetoes the
+	// broadcast and the account ends with StatusVetoed
+	PreBroadcast transactor.PreBroadcastFunc
+	// FeeWindow gates the collection on the current fees, disabled by default
+	FeeWindow FeeWindow
+	// DestinationFundsWait pauses the collection until the destination is topped up when it can not fund
+	// an account, disabled by default
+	DestinationFundsWait DestinationFundsWait
+	// ChainStall pauses the collection while the chain produces no blocks, disabled by default
+	ChainStall ChainStall
+	// Quarantine skips the accounts which failed in a row in previous runs, disabled by default
+	Quarantine Quarantine
+	// Screening checks the source and destination addresses before anything is moved, disabled by default
+	Screening Screening
+	// SourceBacklogPolicy what happens to the accounts whose source has transactions pending before it is funded,
+	// the backlog is not checked by default
+	SourceBacklogPolicy SourceBacklogPolicy
+	// Clock used when waiting, the system clock by default
+	Clock Clock
+	// JitterSeed seeds the random jitter added to the polling waits, e.g. to reproduce the timing of
+	// a logged run, a random seed is used when nil
+	JitterSeed *int64
+	// AfterCollect is invoked after each account completes, successfully or not
+	AfterCollect AfterCollectFunc
+	// AbortOnAfterCollectError skips the remaining accounts when AfterCollect returns an error,
+	// by default the error is only recorded on the Result
+	AbortOnAfterCollectError bool
+	// Ledger records every successful collection, not used when nil
+	Ledger Ledger
+	// LedgerFailurePolicy decides the account outcome when the ledger write fails,
+	// defaults to LedgerFailurePolicyContinue
+	LedgerFailurePolicy LedgerFailurePolicy
+	// ConfirmationStrategy decides when a transaction is confirmed, by default a transactor.ReceiptWatcher
+	// shared by all the accounts polls the node for the receipts
+	ConfirmationStrategy transactor.ConfirmationStrategy
+	// ReceiptPollInterval how often the default ReceiptWatcher polls the node, 10 seconds when zero,
+	// e.g. lower on chains with short block times
+	ReceiptPollInterval time.Duration
+	// ReceiptBackoff lets the default ReceiptWatcher poll a transaction less often the longer it is pending, e.g.
+	// from 1 second doubling up to 10 seconds. Its Initial interval defaults to the ReceiptPollInterval.
+	ReceiptBackoff transactor.ReceiptBackoff
+	// ConfirmationTimeout how long a sent transaction is waited for before its account is left pending, two minutes
+	// when zero, e.g. shorter on chains with short block times and longer on congested ones
+	ConfirmationTimeout time.Duration
+	// Confirmations the number of blocks the block of a collection has to be behind the head before the account
+	// is successful, polled every ReceiptPollInterval. The collection is pending when a reorg removes it meanwhile.
+	Confirmations uint64
+	// ExecutorCalldata builds the execute call of the contract wallets, keyed by the keccak256 hash of
+	// the wallet runtime code. Source contracts without a configured executor fail with ErrSourceIsContract.
+	ExecutorCalldata map[common.Hash]transactor.ExecutorCalldata
+	// CollectStrategy decides how the tokens are collected, defaults to CollectStrategyTransfer
+	CollectStrategy CollectStrategy
+	// SignerType the type of the transactions built and signed, key.SignerTypeLondon by default. All the key
+	// providers have to be created for it, e.g. with pk.NewPrivateKeyProviderWithSigner for key.SignerTypeEIP155
+	SignerType key.SignerType
+	// DisableGasMemoization estimates every transfer. By default, after the first two transfers of a token in
+	// a Collect call, their largest estimate plus 10% is reused for the other transfers of the token, until one fails
+	DisableGasMemoization bool
+	// MaxConcurrentCollections the number of accounts collected at the same time, the accounts are collected
+	// one after another when below 2. The accounts of the same source address are never collected at the same
+	// time, and the funding transactions of the destination are sent one at a time under consecutive nonces.
+	MaxConcurrentCollections int
+	// DryRun makes Collect check the balances, estimate the gas and compute the fees of the accounts without
+	// sending any transaction, the accounts which would be collected get StatusSimulated
+	DryRun bool
+	// TokenPolicies the checks made for the accounts of a token before funding them, keyed by the token address
+	TokenPolicies map[string]TokenPolicy
+	// Bundle submits the funding and the sweep of each account needing funding together, disabled by default.
+	// The accounts of a GroupKey are funded together as before.
+	Bundle Bundle
+	// CostOrdering collects the cheapest accounts first and defers the ones over the budget, disabled by default.
+	// When enabled, the accounts skipped by the FeeWindow are deferred as well.
+	CostOrdering CostOrdering
 }
 
+// AfterCollectFunc is a hook invoked with the Result of each account
+type AfterCollectFunc func(ctx context.Context, result Result) error
+
 // NewEVMCollector utility method to create a EVM collector
 // using the provided EVMCollectorConfig
 func NewEVMCollector(config EVMCollectorConfig) (Collector, error) {
@@ -82,55 +432,586 @@ func NewEVMCollector(config EVMCollectorConfig) (Collector, error) {
 	}
 	zerolog.DefaultContextLogger = &log.Logger
 
-	client, err := ethclient.Dial(config.BlockchainUrl)
+	if config.MaxFeeCapMultiplier != 0 && config.MaxFeeCapMultiplier < 1 {
+		return nil, fmt.Errorf("invalid max fee cap multiplier %v", config.MaxFeeCapMultiplier)
+	}
+	if config.GasLimitMultiplier != 0 && config.GasLimitMultiplier < 1 {
+		return nil, fmt.Errorf("invalid gas limit multiplier %v", config.GasLimitMultiplier)
+	}
+	switch config.SourceBacklogPolicy {
+	case SourceBacklogIgnore, SourceBacklogSkip, SourceBacklogProceed:
+	default:
+		return nil, fmt.Errorf("invalid source backlog policy %q", config.SourceBacklogPolicy)
+	}
+	gasTipCap, err := weiOrGwei(config.GasTipCapWei, config.GasTipCapGwei, "gas tip cap")
 	if err != nil {
 		return nil, err
 	}
-	gasTracker := transactor.NewPolygonGasTracker(config.GasTrackerUrl)
+	maxGasFeeCap, err := weiOrGwei(config.MaxGasFeeCapWei, config.MaxGasFeeCapGwei, "max gas fee cap")
+	if err != nil {
+		return nil, err
+	}
+	err = validateConfigValues(config, gasTipCap, maxGasFeeCap)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.FundingTxTag) > maxFundingTxTagSize {
+		return nil, fmt.Errorf("funding tx tag longer than %d bytes", maxFundingTxTagSize)
+	}
+	feeSpeed, err := transactor.ParseFeeSpeed(string(config.FeeSpeed))
+	if err != nil {
+		return nil, err
+	}
+	signerType := config.SignerType
+	if signerType == "" {
+		signerType = key.SignerTypeLondon
+	}
+	_, err = key.NewSigner(signerType, big.NewInt(1))
+	if err != nil {
+		return nil, err
+	}
+	tokenPolicies, err := normalizeTokenPolicies(config.TokenPolicies)
+	if err != nil {
+		return nil, err
+	}
+	err = config.DriftPolicy.validate()
+	if err != nil {
+		return nil, err
+	}
+	switch config.CollectStrategy {
+	case "", CollectStrategyTransfer, CollectStrategyApprove:
+	default:
+		return nil, fmt.Errorf("invalid collect strategy %s", config.CollectStrategy)
+	}
+	nonceProviderType := NonceProviderTypeFixed
+	if config.NonceProviderType != "" {
+		nonceProviderType, err = ParseNonceProviderType(string(config.NonceProviderType))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := dialClient(config)
+	if err != nil {
+		return nil, err
+	}
+	gasTracker := config.GasTracker
+	if gasTracker == nil {
+		gasTracker = transactor.NewPolygonGasTracker(config.GasTrackerUrl, transactor.WithMaxResponseSize(config.GasTrackerMaxResponseSize))
+	}
+	gasTracker = transactor.WithGasTrackerRetry(gasTracker, config.Retry.For(retry.ComponentGasTracker))
 
 	var nonceProvider nonce.Provider
-	switch config.NonceProviderType {
+	switch nonceProviderType {
 	case NonceProviderTypeNetwork:
 		nonceProvider = nonce.NewNetworkNonceProvider(client)
 	default:
 		nonceProvider = nonce.NewFixedNonceProvider(nil)
-
 	}
 
 	chainId, err := client.ChainID(context.TODO())
 	if err != nil {
 		return nil, err
 	}
-	transactor, err := transactor.NewEvmTransactor(client, gasTracker, nonceProvider)
+	confirmationStrategy := config.ConfirmationStrategy
+	var receiptWatcher *transactor.ReceiptWatcher
+	if confirmationStrategy == nil {
+		backoff := config.ReceiptBackoff
+		if backoff.Initial <= 0 {
+			backoff.Initial = config.ReceiptPollInterval
+		}
+		receiptWatcher = transactor.NewReceiptWatcherWithBackoff(client, backoff)
+		confirmationStrategy = receiptWatcher
+	}
+	var headWatchdog *transactor.HeadWatchdog
+	if config.ChainStall.Threshold > 0 {
+		headWatchdog = transactor.NewHeadWatchdog(client, config.ChainStall.Threshold, config.ChainStall.PollInterval)
+	}
+	transactor, err := transactor.NewEvmTransactor(client, gasTracker, nonceProvider,
+		transactor.WithConfirmationStrategy(confirmationStrategy),
+		transactor.WithMaxFeeCapMultiplier(config.MaxFeeCapMultiplier),
+		transactor.WithGasLimitMultiplier(config.GasLimitMultiplier),
+		transactor.WithFeeSpeed(feeSpeed),
+		transactor.WithNodeFeeFallback(!config.DisableNodeFeeFallback),
+		transactor.WithNodeGasPriceTTL(config.NodeGasPriceTTL),
+		transactor.WithBundleSubmitter(config.Bundle.Submitter),
+		transactor.WithMulticall(config.Multicall3Address),
+		transactor.WithHeadWatchdog(headWatchdog),
+		transactor.WithPreBroadcast(config.PreBroadcast),
+		transactor.WithGasTipCap(gasTipCap),
+		transactor.WithMaxGasFeeCap(maxGasFeeCap),
+		transactor.WithSignerType(signerType),
+		transactor.WithChainID(chainId))
 	if err != nil {
+		if receiptWatcher != nil {
+			receiptWatcher.Close()
+		}
+		if headWatchdog != nil {
+			headWatchdog.Close()
+		}
 		return nil, err
 	}
 
+	confirmationTimeout := config.ConfirmationTimeout
+	if confirmationTimeout <= 0 {
+		confirmationTimeout = defaultConfirmationTimeout
+	}
+
+	clock := config.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
 	return evmCollector{
-		transactor: transactor,
-		chainId:    chainId,
+		transactor:           transactor,
+		gasTracker:           gasTracker,
+		gasTrackerUrl:        config.GasTrackerUrl,
+		feeSpeed:             feeSpeed,
+		feeQuotes:            newFeeQuoteCache(config.FeeQuoteTTL),
+		clock:                clock,
+		jitter:               newJitter(config.JitterSeed),
+		feeWindow:            config.FeeWindow,
+		destinationFundsWait: config.DestinationFundsWait,
+		chainStall:           config.ChainStall,
+		headWatchdog:         headWatchdog,
+		quarantine:           config.Quarantine,
+		screening:            config.Screening,
+		sourceBacklogPolicy:  config.SourceBacklogPolicy,
+		costOrdering:         config.CostOrdering,
+		client:               client,
+		chainId:              &chainIdCache{chainId: chainId},
+		detectPausedTokens:   config.DetectPausedTokens,
+		fundingBuffer:        config.FundingBuffer,
+		fundingTxTag:         config.FundingTxTag,
+		minimumFundingAmount: config.MinimumFundingAmount,
+		reclaimNative:        config.ReclaimNative,
+		retryRevertedFunding: config.RetryRevertedFunding,
+		minReclaimAmount:     config.MinReclaimAmount,
+		planTolerance:        config.PlanTolerance,
+		driftPolicy:          config.DriftPolicy,
+		afterCollect:         config.AfterCollect,
+		abortOnHookError:     config.AbortOnAfterCollectError,
+		ledger:               config.Ledger,
+		ledgerFailurePolicy:  config.LedgerFailurePolicy,
+		replaceChanged:       config.ReplaceChangedTransfers,
+		executors:            config.ExecutorCalldata,
+		strategy:             config.CollectStrategy,
+		tokenPolicies:        tokenPolicies,
+		signerType:           signerType,
+		disableGasMemo:       config.DisableGasMemoization,
+		bundle:               config.Bundle,
+		maxConcurrent:        config.MaxConcurrentCollections,
+		dryRun:               config.DryRun,
+		receiptWatcher:       receiptWatcher,
+		confirmationTimeout:  confirmationTimeout,
+		confirmations:        config.Confirmations,
+		confirmationPoll:     config.ReceiptPollInterval,
+		multicall:            config.Multicall3Address != nil,
+		info:                 newCollectorInfo(config, nonceProviderType, signerType, feeSpeed, gasTipCap, maxGasFeeCap, confirmationTimeout),
 	}, nil
 }
 
+func dialClient(config EVMCollectorConfig) (client.Client, error) {
+	urls := make([]string, 0, len(config.BlockchainUrls)+1)
+	if config.BlockchainUrl != "" {
+		urls = append(urls, config.BlockchainUrl)
+	}
+	urls = append(urls, config.BlockchainUrls...)
+
+	clients := make([]client.Client, 0, len(urls))
+	if config.Client != nil {
+		clients = append(clients, client.WithHook(config.Client, config.RPCHook, config.RPCHookSizes))
+		urls = nil
+	}
+	for _, url := range urls {
+		c, err := ethclient.Dial(url)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client.WithHook(c, config.RPCHook, config.RPCHookSizes))
+	}
+
+	primary, err := primaryClient(clients)
+	if err != nil {
+		return nil, err
+	}
+	primary = client.WithRetry(primary, config.Retry.For(retry.ComponentRPC))
+
+	secondaries := make([]client.Client, 0, len(config.BroadcastUrls))
+	for _, url := range config.BroadcastUrls {
+		c, err := ethclient.Dial(url)
+		if err != nil {
+			return nil, err
+		}
+		secondaries = append(secondaries, client.WithHook(c, config.RPCHook, config.RPCHookSizes))
+	}
+	return client.WithSecondaryBroadcast(primary, secondaries, config.BroadcastTimeout), nil
+}
+
+// primaryClient returns the only client, or the failover client of all the given ones
+func primaryClient(clients []client.Client) (client.Client, error) {
+	if len(clients) == 1 {
+		return clients[0], nil
+	}
+	return client.NewFailoverClient(clients)
+}
+
 type evmCollector struct {
-	transactor transactor.Transactor
-	chainId    *big.Int
+	transactor           transactor.Transactor
+	gasTracker           transactor.GasTracker
+	gasTrackerUrl        string
+	feeSpeed             transactor.FeeSpeed
+	feeQuotes            *feeQuoteCache
+	clock                Clock
+	jitter               *jitter
+	feeWindow            FeeWindow
+	destinationFundsWait DestinationFundsWait
+	chainStall           ChainStall
+	headWatchdog         *transactor.HeadWatchdog
+	quarantine           Quarantine
+	screening            Screening
+	sourceBacklogPolicy  SourceBacklogPolicy
+	costOrdering         CostOrdering
+	bundle               Bundle
+	client               client.Client
+	chainId              *chainIdCache
+	detectPausedTokens   bool
+	fundingBuffer        *big.Int
+	fundingTxTag         []byte
+	minimumFundingAmount *big.Int
+	reclaimNative        bool
+	retryRevertedFunding bool
+	minReclaimAmount     *big.Int
+	planTolerance        PlanTolerance
+	driftPolicy          DriftPolicy
+	afterCollect         AfterCollectFunc
+	abortOnHookError     bool
+	ledger               Ledger
+	ledgerFailurePolicy  LedgerFailurePolicy
+	replaceChanged       bool
+	executors            map[common.Hash]transactor.ExecutorCalldata
+	strategy             CollectStrategy
+	tokenPolicies        map[string]TokenPolicy
+	signerType           key.SignerType
+	disableGasMemo       bool
+	maxConcurrent        int
+	dryRun               bool
+	receiptWatcher       *transactor.ReceiptWatcher
+	confirmationTimeout  time.Duration
+	confirmations        uint64
+	confirmationPoll     time.Duration
+	multicall            bool
+	info                 CollectorInfo
+}
+
+// batch keeps the state shared between the accounts of a single Collect call
+type batch struct {
+	runID            string
+	mu               sync.Mutex
+	pausedTokens     map[string]bool
+	gasMemo          *gasMemo
+	destinationFunds *destinationFunds
+	blockTimes       *blockTimes
+	// destinationNonce is only set when the accounts are collected concurrently
+	destinationNonce *destinationNonce
+	// controller cancels single accounts, nil when the run has none
+	controller *RunController
+}
+
+func newBatch() *batch {
+	return &batch{
+		runID:            newRunID(),
+		pausedTokens:     make(map[string]bool),
+		gasMemo:          newGasMemo(),
+		destinationFunds: &destinationFunds{},
+		blockTimes:       newBlockTimes(),
+	}
+}
+
+func newRunID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func (b *batch) isTokenPaused(token string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pausedTokens[strings.ToLower(token)]
+}
+
+func (b *batch) markTokenPaused(token string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pausedTokens[strings.ToLower(token)] = true
+}
+
+func (c evmCollector) Close() error {
+	if c.headWatchdog != nil {
+		c.headWatchdog.Close()
+	}
+	if c.receiptWatcher != nil {
+		return c.receiptWatcher.Close()
+	}
+	return nil
 }
 
 func (c evmCollector) GetChainId(ctx context.Context) *big.Int {
-	return c.chainId
+	return c.chainId.get()
 }
 
 func (c evmCollector) Collect(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount) []Result {
-	var results = make([]Result, 0)
+	return c.CollectWithController(ctx, nil, destinationAccount, accounts)
+}
+
+func (c evmCollector) CollectWithController(ctx context.Context, controller *RunController, destinationAccount DestinationAccount, accounts []SourceAccount) []Result {
+	return c.collectRun(ctx, controller, destinationAccount, accounts, nil)
+}
 
-	for _, account := range accounts {
-		results = append(results, c.collect(ctx, account, destinationAccount))
+// collectRun collects the accounts, passing the final result of each account to emit as soon as it is known,
+// when set, without the EndBlock of the run which is only set on the returned results
+func (c evmCollector) collectRun(ctx context.Context, controller *RunController, destinationAccount DestinationAccount, accounts []SourceAccount, emit func(Result)) []Result {
+	if len(accounts) == 0 {
+		log.Ctx(ctx).Debug().Msg("no accounts to collect")
+		return make([]Result, 0)
 	}
 
+	// the node minimum gas price is read once for the transactions of the batch
+	ctx = transactor.WithNodeGasPriceCache(ctx)
+	b := newBatch()
+	b.controller = controller
+	destinationErr := validateKeyProvider(destinationAccount.KeyProvider)
+	if destinationErr != nil {
+		log.Ctx(ctx).Warn().Err(destinationErr).Msg("invalid destination account")
+	}
+	defer key.Release(destinationAccount.KeyProvider)
+	screening := c.newRunScreening()
+	var destinationScreenErr error
+	if destinationErr == nil {
+		destinationScreenErr = screening.check(ctx, *destinationAccount.KeyProvider.GetAddress(), ScreeningRoleDestination)
+		if destinationScreenErr != nil {
+			log.Ctx(ctx).Error().Err(destinationScreenErr).Msg("destination screening failed, aborting the run")
+		}
+	}
+	startBlock := c.blockNumber(ctx)
+	pool := newAccountPool(c.maxConcurrent)
+	if pool != nil {
+		b.destinationNonce = &destinationNonce{}
+	}
+
+	// the results keep the order of the given accounts, even when they are collected in another order
+	results := make([]Result, len(accounts))
+	finish := func(index int, result Result) {
+		results[index] = result
+		if emit != nil {
+			result.Destination = destinationHex(destinationAccount)
+			result.StartBlock = startBlock
+			emit(result)
+		}
+	}
+	scheduled := c.scheduleAccounts(ctx, destinationAccount, accounts, destinationErr == nil)
+	spent := new(big.Int)
+	// mu guards aborted and the quarantine, and runs the after collect hooks one at a time
+	var mu sync.Mutex
+	aborted := false
+	feeWindowMet := true
+	// stallExpired a pause of the run reached the ChainStall MaxPause, the accounts are skipped until the blocks resume
+	stallExpired := false
+	selfCollections := 0
+	fundedGroups := make(map[string]bool)
+	quarantine := c.loadQuarantine(ctx)
+	zeroBalances := c.zeroBalances(ctx, scheduled)
+	groupMembers := make(map[int]groupMember)
+	for i, s := range scheduled {
+		account := s.account
+		if destinationErr != nil || validateKeyProvider(account.KeyProvider) != nil {
+			finish(s.index, handleError(ctx, account, PhaseValidation, ErrNilKeyProvider))
+			continue
+		}
+		if destinationScreenErr != nil {
+			finish(s.index, screeningResult(ctx, account, destinationScreenErr))
+			continue
+		}
+		if err := c.validateSignerType(destinationAccount.KeyProvider, account.KeyProvider); err != nil {
+			finish(s.index, handleError(ctx, account, PhaseValidation, err))
+			continue
+		}
+		if err := validateAmount(account); err != nil {
+			finish(s.index, handleError(ctx, account, PhaseValidation, err))
+			continue
+		}
+		if isNative(account) && len(account.Tokens) > 0 {
+			finish(s.index, handleError(ctx, account, PhaseValidation, ErrTokensWithoutToken))
+			continue
+		}
+		if err := validateERC721(account); err != nil {
+			finish(s.index, handleError(ctx, account, PhaseValidation, err))
+			continue
+		}
+		if isSelfCollection(account, destinationAccount) {
+			selfCollections++
+			finish(s.index, getResult(ctx, account, StatusSkip, ReasonSelfCollection))
+			continue
+		}
+		if ctx.Err() != nil {
+			finish(s.index, getResult(ctx, account, StatusInterrupted, ReasonInterrupted))
+			continue
+		}
+		if controller.Cancelled(*account.KeyProvider.GetAddress()) {
+			finish(s.index, getResult(ctx, account, StatusCancelled, ReasonCancelled))
+			continue
+		}
+		if quarantine != nil && quarantine.isQuarantined(account, c.clock.Now()) {
+			finish(s.index, getResult(ctx, account, StatusQuarantined, ReasonQuarantined))
+			continue
+		}
+		if zeroBalances[s.index] {
+			finish(s.index, getResult(ctx, account, StatusSkip, ReasonZeroBalance))
+			continue
+		}
+		if err := screening.checkSource(ctx, account); err != nil {
+			finish(s.index, screeningResult(ctx, account, err))
+			continue
+		}
+		if c.headWatchdog.State().Stalled {
+			if !stallExpired {
+				stallExpired = !c.awaitChainHead(ctx)
+			}
+			if ctx.Err() != nil {
+				finish(s.index, getResult(ctx, account, StatusInterrupted, ReasonInterrupted))
+				continue
+			}
+			if c.headWatchdog.State().Stalled {
+				finish(s.index, getResult(ctx, account, StatusSkip, ReasonChainStalled))
+				continue
+			}
+		}
+		stallExpired = false
+		if c.feeWindow.MaxFee != nil && feeWindowMet &&
+			(i == 0 || (c.feeWindow.RecheckEvery > 0 && i%c.feeWindow.RecheckEvery == 0)) {
+			feeWindowMet = c.waitFeeWindow(ctx)
+		}
+		if !feeWindowMet {
+			status := StatusSkip
+			if c.costOrdering.Enabled {
+				status = StatusDeferred
+			}
+			finish(s.index, getResult(ctx, account, status, ReasonFeeWindowNotMet))
+			continue
+		}
+		if c.costOrdering.Budget != nil && s.cost != nil {
+			if new(big.Int).Add(spent, s.cost).Cmp(c.costOrdering.Budget) > 0 {
+				finish(s.index, getResult(ctx, account, StatusDeferred, ReasonBudgetExceeded))
+				continue
+			}
+			spent.Add(spent, s.cost)
+		}
+		mu.Lock()
+		skip := aborted
+		mu.Unlock()
+		if skip {
+			finish(s.index, getResult(ctx, account, StatusSkip, ReasonAfterCollectAborted))
+			continue
+		}
+
+		if account.GroupKey != "" && !fundedGroups[account.GroupKey] && !c.dryRun {
+			fundedGroups[account.GroupKey] = true
+			gates := groupGates{screening: screening, quarantine: quarantine, zeroBalances: zeroBalances,
+				controller: controller, spent: spent}
+			if c.feeWindow.MaxFee != nil && c.feeWindow.RecheckEvery > 0 {
+				gates.limit = c.feeWindow.RecheckEvery - i%c.feeWindow.RecheckEvery
+			}
+			group := c.groupOf(ctx, gates, scheduled[i:], account.GroupKey, destinationAccount)
+			c.fundGroup(ctx, b, group, destinationAccount, groupMembers)
+		}
+		member, isMember := groupMembers[s.index]
+		index := s.index
+		started := pool.run(ctx, *account.KeyProvider.GetAddress(), func() {
+			// once started the collection is finished even when the run is interrupted meanwhile
+			collectCtx, cancel := drainContext(ctx, c.confirmationTimeout)
+			defer cancel()
+			var result Result
+			if isMember {
+				result = c.collectMember(collectCtx, b, member)
+			} else {
+				result = c.collect(collectCtx, b, account, destinationAccount)
+			}
+			key.Release(account.KeyProvider)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if quarantine != nil {
+				quarantine.record(collectCtx, result, c.clock.Now())
+			}
+			// the result refers to the given account, not to the one carrying the gas estimate
+			result.SourceAccount = accounts[index]
+			if c.afterCollect != nil && !c.dryRun {
+				result.AfterCollectErr = c.afterCollect(collectCtx, result)
+				if result.AfterCollectErr != nil {
+					log.Ctx(ctx).Warn().Err(result.AfterCollectErr).Msg("after collect hook failed")
+					aborted = aborted || c.abortOnHookError
+				}
+			}
+			finish(index, result)
+		})
+		if !started {
+			finish(s.index, getResult(ctx, account, StatusInterrupted, ReasonInterrupted))
+		}
+	}
+	pool.wait()
+	if selfCollections > 0 {
+		log.Ctx(ctx).Warn().Int("count", selfCollections).Msg("skipped source accounts equal to the destination")
+	}
+
+	c.setRun(ctx, results, destinationAccount, startBlock)
 	return results
 }
 
+// isSelfCollection checks if the tokens of the account are held by the destination account
+func isSelfCollection(account SourceAccount, destinationAccount DestinationAccount) bool {
+	return *tokenHolder(account) == *destinationAccount.KeyProvider.GetAddress()
+}
+
+// weiOrGwei returns the wei value, or the gwei one converted to wei, failing when both are set
+func weiOrGwei(wei *big.Int, gwei string, name string) (*big.Int, error) {
+	if gwei == "" {
+		if wei != nil && wei.Sign() < 0 {
+			return nil, fmt.Errorf("invalid %s %s", name, wei)
+		}
+		return wei, nil
+	}
+	if wei != nil {
+		return nil, fmt.Errorf("both wei and gwei %s set", name)
+	}
+	value, err := ParseUnits(gwei, 9)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s %s", name, gwei)
+	}
+	return value, nil
+}
+
+// validateSignerType checks that the key providers were created for the configured signer type
+func (c evmCollector) validateSignerType(keyProviders ...key.Provider) error {
+	for _, keyProvider := range keyProviders {
+		signerType := key.GetSignerType(keyProvider)
+		if signerType != c.signerType {
+			return fmt.Errorf("%w: key provider %s, collector %s", transactor.ErrSignerTypeMismatch, signerType, c.signerType)
+		}
+	}
+	return nil
+}
+
+// validateKeyProvider checks that a key provider is set and provides an address
+func validateKeyProvider(keyProvider key.Provider) error {
+	if keyProvider == nil || keyProvider.GetAddress() == nil {
+		return ErrNilKeyProvider
+	}
+	return nil
+}
+
 func (c evmCollector) getTokenBalance(ctx context.Context, toBeCollectedAccountAddr *common.Address, key SourceAccount) (*big.Int, error) {
 	accountToBeCollectedERC20Balance, err := c.transactor.BalanceOf(ctx, *toBeCollectedAccountAddr, key.Token)
 	if err != nil {
@@ -140,29 +1021,193 @@ func (c evmCollector) getTokenBalance(ctx context.Context, toBeCollectedAccountA
 	return accountToBeCollectedERC20Balance, nil
 }
 
-func (c evmCollector) collect(ctx context.Context, account SourceAccount, destinationAccount DestinationAccount) Result {
-	tokenBalance, err := c.getTokenBalance(ctx, account.KeyProvider.GetAddress(), account)
+// amountSnapshot fetches the state the collected amount of the account is decided from
+func (c evmCollector) amountSnapshot(ctx context.Context, account SourceAccount, holder common.Address, destination common.Address) (amountSnapshot, error) {
+	var snapshot amountSnapshot
+	if account.Amount != "" {
+		requestedAmount, err := parseAmount(account.Amount)
+		if err != nil {
+			return snapshot, err
+		}
+		snapshot.requestedAmount = requestedAmount
+	}
+
+	tokenBalance, err := c.getTokenBalance(ctx, &holder, account)
 	if err != nil {
-		return handleError(ctx, account, err)
+		return snapshot, err
 	}
+	snapshot.tokenBalance = tokenBalance
 
-	if tokenBalance.Cmp(big.NewInt(0)) == 0 {
-		return getResult(ctx, account, StatusSkip)
+	// the allowance is only read when there is an amount to collect
+	if c.strategy == CollectStrategyApprove && c.planner().planAmount(snapshot).collect() {
+		snapshot.allowance, err = c.transactor.Allowance(ctx, holder, destination, account.Token)
+		if err != nil {
+			return snapshot, err
+		}
 	}
+	return snapshot, nil
+}
 
-	amount := account.Amount
-	if amount != "" {
-		a, _ := new(big.Int).SetString(amount, 10)
-		if tokenBalance.Cmp(a) < 0 {
-			return handleError(ctx, account, errors.New("insufficient balance"))
+// collection the state of an account prepared for the collection, before it is funded and swept
+type collection struct {
+	account            SourceAccount
+	sourceAddress      *common.Address
+	destinationAddress *common.Address
+	holderAddress      *common.Address
+	executor           transactor.ExecutorCalldata
+	amount             string
+	gasTipCapValue     *big.Int
+	gasFeeCapValue     *big.Int
+	params             transactor.TxParams
+	erc20Tx            *types.Transaction
+	// fundingAmount the wei sent by the destination to the source before the sweep, zero when not needed
+	fundingAmount *big.Int
+	funded        bool
+	fundingTxHash common.Hash
+	// bundled the funding and the sweep were mined together in a bundle
+	bundled bool
+	// deferReclaim the native balance is reclaimed once all the tokens of the account were swept
+	deferReclaim bool
+	// tokenID the ERC-721 token transferred, nil for the ERC-20 transfers
+	tokenID *big.Int
+	// backlog the transactions of the source pending before the transfer, see SourceBacklogProceed
+	backlog uint64
+}
+
+// needsFunding reports whether the destination has to fund the source before the sweep.
+// The destination never funds itself, e.g. when it is also the operator of a contract wallet
+func (col *collection) needsFunding() bool {
+	return col.fundingAmount.Sign() > 0 && *col.sourceAddress != *col.destinationAddress
+}
+
+// collect prepares, funds and sweeps the account. The steps before the funding is sent run under a context
+// cancelled as well through the RunController of the batch, the funding and the sweep only under the run context.
+func (c evmCollector) collect(ctx context.Context, b *batch, account SourceAccount, destinationAccount DestinationAccount) Result {
+	if b.controller.Cancelled(*account.KeyProvider.GetAddress()) {
+		return getResult(ctx, account, StatusCancelled, ReasonCancelled)
+	}
+	if isNative(account) {
+		return c.collectNative(ctx, b, account, destinationAccount)
+	}
+	if account.ERC721 {
+		return c.collectERC721(ctx, b, account, destinationAccount)
+	}
+	if len(account.Tokens) > 0 {
+		return c.collectTokens(ctx, b, account, destinationAccount)
+	}
+	if c.usesPermit(account) {
+		if result, ok := c.collectPermit(ctx, b, account, destinationAccount); ok {
+			return result
 		}
-	} else {
-		amount = tokenBalance.String()
+	}
+	accountCtx, cancel := b.controller.accountContext(ctx, *account.KeyProvider.GetAddress())
+	defer cancel()
+	nonce, backlog, result, ok := c.checkBacklog(accountCtx, account)
+	if !ok {
+		return c.cancelledResult(ctx, b, account, result)
+	}
+	col, result := c.prepareAt(accountCtx, b, account, destinationAccount, nonce)
+	if col == nil {
+		return c.cancelledResult(ctx, b, account, result)
+	}
+	col.backlog = backlog
+	if c.dryRun {
+		return c.simulate(ctx, col)
+	}
+
+	if col.needsFunding() {
+		if c.destinationFundsWait.Enabled {
+			funded, err := c.awaitDestinationFunds(accountCtx, b, *col.destinationAddress, c.fundingCost(col))
+			if err != nil {
+				return c.cancelledResult(ctx, b, account, handleError(ctx, account, PhaseFundingBuild, err))
+			}
+			if !funded {
+				return getResult(ctx, account, StatusSkip, ReasonInsufficientDestinationFunds)
+			}
+		}
+		if b.controller.Cancelled(*col.sourceAddress) {
+			return getResult(ctx, account, StatusCancelled, ReasonCancelled)
+		}
+		var phase Phase
+		var err error
+		if c.bundle.Submitter != nil {
+			phase, err = c.fundBundled(ctx, b, col, destinationAccount)
+		}
+		if err == nil && !col.funded {
+			phase, err = c.fundWithRetry(ctx, b, col, destinationAccount)
+		}
+		if err != nil {
+			result = handleError(ctx, account, phase, err)
+			result.FundingTxHash = txHashHex(col.fundingTxHash)
+			result.FundingAmount = col.fundingAmount.String()
+			result.EstimatedFee = c.estimatedFee(col).String()
+			return result
+		}
+		col.funded = true
+		if !col.bundled {
+			if result, cancelled := c.cancelFunded(ctx, b, col); cancelled {
+				return result
+			}
+		}
+	} else if b.controller.Cancelled(*col.sourceAddress) {
+		return getResult(ctx, account, StatusCancelled, ReasonCancelled)
+	}
+	return c.sweep(ctx, b, col)
+}
+
+// prepare resolves the amount, builds the signed transfer and the funding amount of the account,
+// returning a nil collection with the final result of the account when it is not to be collected
+func (c evmCollector) prepare(ctx context.Context, b *batch, account SourceAccount, destinationAccount DestinationAccount) (*collection, Result) {
+	return c.prepareAt(ctx, b, account, destinationAccount, nil)
+}
+
+// prepareAt prepares the account with the transfer sent under the given nonce, the one of the nonce provider when nil
+func (c evmCollector) prepareAt(ctx context.Context, b *batch, account SourceAccount, destinationAccount DestinationAccount, nonce *big.Int) (*collection, Result) {
+	sourceAddress := account.KeyProvider.GetAddress()
+	destinationAddress := destinationAccount.KeyProvider.GetAddress()
+	if sourceAddress == nil || destinationAddress == nil {
+		return nil, handleError(ctx, account, PhaseValidation, ErrNilKeyProvider)
+	}
+
+	if c.detectPausedTokens && b.isTokenPaused(account.Token) {
+		return nil, getResult(ctx, account, StatusTokenPaused, ReasonTokenPaused)
+	}
+
+	// all the "nothing to do" cases are resolved before any other call than the balance read
+	holderAddress := tokenHolder(account)
+	snapshot, err := c.amountSnapshot(ctx, account, *holderAddress, *destinationAddress)
+	if err != nil {
+		return nil, handleError(ctx, account, PhaseBalanceCheck, err)
+	}
+	decision := c.planner().planAmount(snapshot)
+	if decision.err != nil {
+		return nil, handleError(ctx, account, PhaseBalanceCheck, decision.err)
+	}
+	if !decision.collect() {
+		result := getResult(ctx, account, decision.status, decision.reason)
+		if decision.approvedAmount != nil {
+			result.ApprovedAmount = decision.approvedAmount.String()
+		}
+		return nil, result
+	}
+	amount := decision.amount.String()
+
+	executor, err := c.resolveExecutor(ctx, account, *holderAddress)
+	if err != nil {
+		return nil, handleError(ctx, account, PhaseValidation, err)
+	}
+
+	eligible, err := c.isDestinationEligible(ctx, account, *holderAddress, *destinationAddress)
+	if err != nil {
+		return nil, handleError(ctx, account, PhaseValidation, err)
+	}
+	if !eligible {
+		return nil, getResult(ctx, account, StatusDestinationNotEligible, ReasonDestinationNotEligible)
 	}
 
 	gasTipCapValue, gasFeeCapValue, err := c.transactor.GetGasCapValues(ctx)
 	if err != nil {
-		return handleError(ctx, account, err)
+		return nil, handleError(ctx, account, PhaseGasFetch, err)
 	}
 
 	ecr20TxParams := transactor.TxParams{
@@ -172,93 +1217,379 @@ func (c evmCollector) collect(ctx context.Context, account SourceAccount, destin
 		Amount:              amount,
 		GasTipCapValue:      gasTipCapValue,
 		GasFeeCapValue:      gasFeeCapValue,
+		GasLimit:            account.GasLimit,
+		Nonce:               nonce,
 	}
-	erc20Tx, err := c.transactor.CreateERC20Tx(ctx, ecr20TxParams)
+	if executor != nil {
+		ecr20TxParams.Wallet = holderAddress
+		ecr20TxParams.Executor = executor
+	}
+	// the wallet calls differ per wallet, only the direct transfers share their estimates
+	memoize := !c.disableGasMemo && account.GasLimit == 0 && executor == nil
+	if memoize {
+		ecr20TxParams.GasLimit = b.gasMemo.gasLimit(account.Token)
+	}
+	erc20Tx, err := c.createCollectTx(ctx, ecr20TxParams)
 	if err != nil {
-		return handleError(ctx, account, err)
+		return nil, c.handleTransferError(ctx, b, account, PhaseSweepBuild, err)
 	}
-	estimatedFee := new(big.Int).Add(new(big.Int).Mul(big.NewInt(int64(erc20Tx.Gas())), gasFeeCapValue), gasTipCapValue)
-	accountToBeCollectedBalance, err := c.transactor.BalanceAt(ctx, *account.KeyProvider.GetAddress())
+	if memoize && ecr20TxParams.GasLimit == 0 {
+		b.gasMemo.record(account.Token, erc20Tx.Gas())
+	}
+	estimatedFee := new(big.Int).Mul(new(big.Int).SetUint64(erc20Tx.Gas()), erc20Tx.GasFeeCap())
+	accountToBeCollectedBalance, err := c.transactor.BalanceAt(ctx, *sourceAddress)
 	if err != nil {
-		return handleError(ctx, account, err)
+		return nil, handleError(ctx, account, PhaseFundingBuild, err)
+	}
+
+	return &collection{
+		account:            account,
+		sourceAddress:      sourceAddress,
+		destinationAddress: destinationAddress,
+		holderAddress:      holderAddress,
+		executor:           executor,
+		amount:             amount,
+		gasTipCapValue:     gasTipCapValue,
+		gasFeeCapValue:     gasFeeCapValue,
+		params:             ecr20TxParams,
+		erc20Tx:            erc20Tx,
+		fundingAmount:      c.planner().planFunding(estimatedFee, accountToBeCollectedBalance),
+	}, Result{}
+}
+
+// fundingParams the funding transaction of the prepared account
+func (c evmCollector) fundingParams(col *collection, destinationAccount DestinationAccount) transactor.TxParams {
+	return transactor.TxParams{
+		SenderKeyProvider:   destinationAccount.KeyProvider,
+		ReceiverKeyProvider: col.account.KeyProvider,
+		Amount:              col.fundingAmount.String(),
+		GasTipCapValue:      col.gasTipCapValue,
+		GasFeeCapValue:      col.gasFeeCapValue,
+		Data:                c.fundingTxTag,
 	}
+}
 
-	remainingFee := new(big.Int).Sub(estimatedFee, accountToBeCollectedBalance)
+// fundWithRetry funds the prepared account, retrying once with bumped fees when the
+// funding reverted and RetryRevertedFunding is enabled
+func (c evmCollector) fundWithRetry(ctx context.Context, b *batch, col *collection, destinationAccount DestinationAccount) (Phase, error) {
+	nativTxParams := c.fundingParams(col, destinationAccount)
+	txHash, phase, err := c.fund(ctx, b, nativTxParams)
+	if err != nil && errors.Is(err, ErrFundingReverted) && c.retryRevertedFunding {
+		log.Ctx(ctx).Warn().Err(err).Str("account", addressHex(col.account)).Msg("retrying funding with bumped fees")
+		nativTxParams.GasTipCapValue = bumpFee(col.gasTipCapValue)
+		nativTxParams.GasFeeCapValue = bumpFee(col.gasFeeCapValue)
+		txHash, phase, err = c.fund(ctx, b, nativTxParams)
+	}
+	col.fundingTxHash = txHash
+	return phase, err
+}
 
-	if remainingFee.Cmp(big.NewInt(0)) > 0 {
-		nativTxParams := transactor.TxParams{
-			SenderKeyProvider:   destinationAccount.KeyProvider,
-			ReceiverKeyProvider: account.KeyProvider,
-			Amount:              remainingFee.String(),
-			GasTipCapValue:      gasTipCapValue,
-			GasFeeCapValue:      gasFeeCapValue,
+// sweep sends the transfer of the prepared account, once it was funded when needed, and waits for it
+func (c evmCollector) sweep(ctx context.Context, b *batch, col *collection) (result Result) {
+	var fundingBlock minedBlock
+	if col.funded {
+		fundingBlock = c.minedBlock(ctx, b, col.fundingTxHash)
+	}
+	account := col.account
+	amount := col.amount
+	ecr20TxParams := col.params
+	erc20Tx := col.erc20Tx
+	defer func() {
+		if col.funded && len(c.fundingTxTag) > 0 {
+			result.FundingTxTag = hexutil.Encode(c.fundingTxTag)
 		}
-		nativTx, err := c.transactor.CreateTx(ctx, nativTxParams)
-		if err != nil {
-			return handleError(ctx, account, err)
+		result.FundingBlockNumber = fundingBlock.number
+		result.FundingBlockTime = fundingBlock.time
+		result.FundingTxHash = txHashHex(col.fundingTxHash)
+		result.EstimatedFee = c.estimatedFee(col).String()
+		if col.needsFunding() {
+			result.FundingAmount = col.fundingAmount.String()
+		}
+		if erc20Tx != nil {
+			result.TxHash = erc20Tx.Hash().Hex()
 		}
+		result.Bundled = col.bundled
+		result.SourceBacklog = col.backlog
+	}()
+	var err error
+	if !col.bundled {
+		err = c.transactor.Transfer(ctx, erc20Tx)
+	}
+	if errors.Is(err, transactor.ErrReplacementUnderpriced) && c.replaceChanged && c.strategy != CollectStrategyApprove &&
+		col.tokenID == nil {
+		erc20Tx, err = c.replaceTransfer(ctx, ecr20TxParams, erc20Tx, err)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, transactor.ErrNonceTooLow):
+			return getResult(ctx, account, StatusSkip, ReasonNonceTooLow)
+		case errors.Is(err, transactor.ErrAlreadyKnown), errors.Is(err, transactor.ErrReplacementUnderpriced):
+			return getResult(ctx, account, StatusPending, ReasonAlreadyPending)
+		default:
+			b.gasMemo.reset(account.Token)
+			return c.handleTransferError(ctx, b, account, PhaseSweepSend, err)
+		}
+	}
 
-		err = c.transactor.Transfer(ctx, nativTx)
+	// the transfer queued behind a backlog is only mined after it
+	timeoutCtx, cancelFunc := context.WithTimeout(ctx, c.confirmationTimeout*time.Duration(1+col.backlog))
+	defer cancelFunc()
+	receipt, err := c.transactor.VerifyTx(timeoutCtx, erc20Tx.Hash().Hex())
+	if err != nil {
+		return handleError(ctx, account, PhaseSweepWait, err)
+	}
+	if succeeded(receipt) {
+		receipt, err = c.awaitConfirmations(ctx, erc20Tx.Hash(), receipt)
 		if err != nil {
-			return handleError(ctx, account, err)
+			return handleError(ctx, account, PhaseSweepWait, err)
 		}
+	}
+	if !succeeded(receipt) {
+		b.gasMemo.reset(account.Token)
+		return getResult(ctx, account, StatusPending, ReasonNotMined)
 
-		timeoutCtx, cancelFunc := context.WithTimeout(ctx, 2*time.Minute)
-		defer cancelFunc()
-		isMined, err := c.transactor.VerifyTx(timeoutCtx, nativTx.Hash().Hex())
+	}
+	mined := c.receiptBlock(ctx, b, receipt)
+	if c.strategy == CollectStrategyApprove && col.tokenID == nil {
+		result = getResult(ctx, account, StatusSuccess, ReasonNone)
+		result.ApprovedAmount = amount
+		result.BlockNumber = mined.number
+		result.BlockTime = mined.time
+		result.GasUsed = receipt.GasUsed
+		result.Fee, result.FeeDerived = c.paidFeeString(ctx, erc20Tx, receipt)
+		return result
+	}
+	if col.executor != nil {
+		err = c.verifyInnerTransfer(ctx, account, *col.holderAddress, *col.destinationAddress, amount, erc20Tx.Hash().Hex())
 		if err != nil {
-			return handleError(ctx, account, err)
+			return handleError(ctx, account, PhaseSweepWait, err)
 		}
-
-		if !isMined {
-			return handleError(ctx, account, err)
+	}
+	if c.ledger != nil {
+		err = c.appendLedger(ctx, b, account, *col.holderAddress, *col.destinationAddress, amount, erc20Tx.Hash().Hex(), mined)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("tx", erc20Tx.Hash().Hex()).Msg("ledger write failed")
+			if c.ledgerFailurePolicy == LedgerFailurePolicyFail {
+				return handleError(ctx, account, PhaseLedgerWrite, err)
+			}
 		}
+	}
 
+	result = getResult(ctx, account, StatusSuccess, ReasonNone)
+	result.CollectedAmount = amount
+	result.BlockNumber = mined.number
+	result.BlockTime = mined.time
+	result.GasUsed = receipt.GasUsed
+	result.Fee, result.FeeDerived = c.paidFeeString(ctx, erc20Tx, receipt)
+	if (c.reclaimNative || account.CollectNative) && !col.deferReclaim {
+		result.ReclaimStatus = c.reclaim(ctx, account, *col.sourceAddress, *col.destinationAddress, col.gasTipCapValue, col.gasFeeCapValue)
 	}
+	return result
+}
+
+// txHashHex returns the hex hash, empty for the zero hash of a transaction which was not sent
+func txHashHex(hash common.Hash) string {
+	if hash == (common.Hash{}) {
+		return ""
+	}
+	return hash.Hex()
+}
 
-	err = c.transactor.Transfer(ctx, erc20Tx)
+// fund sends the funding transaction and waits for it to be mined,
+// returning its hash and the phase in which it failed
+func (c evmCollector) fund(ctx context.Context, b *batch, params transactor.TxParams) (common.Hash, Phase, error) {
+	nativTx, phase, err := c.sendFunding(ctx, b, params)
+	if nativTx == nil {
+		return common.Hash{}, phase, err
+	}
 	if err != nil {
-		switch err.Error() {
-		case nonceTooLow:
-			return getResult(ctx, account, StatusSkip)
-		case alreadyKnown:
-			fallthrough
-		case replacementTransactionUnderpriced:
-			return getResult(ctx, account, StatusPending)
-		default:
-			return handleError(ctx, account, err)
-		}
+		return nativTx.Hash(), phase, err
 	}
+	phase, err = c.waitFunding(ctx, nativTx)
+	return nativTx.Hash(), phase, err
+}
 
-	timeoutCtx, cancelFunc := context.WithTimeout(ctx, 2*time.Minute)
-	defer cancelFunc()
-	isMined, err := c.transactor.VerifyTx(timeoutCtx, erc20Tx.Hash().Hex())
+// appendLedger records the successful collection in the ledger
+func (c evmCollector) appendLedger(ctx context.Context, b *batch, account SourceAccount, sourceAddress common.Address, destinationAddress common.Address, amount string, txHash string, mined minedBlock) error {
+	amountWei, err := parseAmount(amount)
 	if err != nil {
-		return handleError(ctx, account, err)
+		return fmt.Errorf("%w: %v", ErrLedgerWriteFailed, err)
+	}
+	entry := LedgerEntry{
+		RunID:       b.runID,
+		Source:      sourceAddress.Hex(),
+		Token:       account.Token,
+		Amount:      NewWei(amountWei),
+		Destination: destinationAddress.Hex(),
+		TxHash:      txHash,
+	}
+	if account.ERC721 && len(account.TokenIDs) == 1 {
+		entry.TokenID = account.TokenIDs[0]
+	}
+	if mined.number == 0 {
+		return fmt.Errorf("%w: block of %s unknown", ErrLedgerWriteFailed, txHash)
 	}
-	if !isMined {
-		return getResult(ctx, account, StatusPending)
+	entry.BlockNumber = mined.number
+	entry.BlockTime = timePointer(mined.time)
 
+	err = c.ledger.Append(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrLedgerWriteFailed, err)
+	}
+	return nil
+}
+
+// reclaim sends the native balance left on the source account, minus the reclaim transaction
+// gas cost, back to the destination. The reclaim is skipped when the balance does not exceed
+// the gas cost plus the configured minimum reclaim amount.
+func (c evmCollector) reclaim(ctx context.Context, account SourceAccount, sourceAddress common.Address, destinationAddress common.Address, gasTipCapValue *big.Int, gasFeeCapValue *big.Int) Status {
+	balance, err := c.transactor.BalanceAt(ctx, sourceAddress)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("reclaim failed")
+		return StatusFail
 	}
-	return getResult(ctx, account, StatusSuccess)
 
+	gasCost := new(big.Int).Mul(new(big.Int).SetUint64(params.TxGas), gasFeeCapValue)
+	threshold := new(big.Int).Set(gasCost)
+	if c.minReclaimAmount != nil {
+		threshold.Add(threshold, c.minReclaimAmount)
+	}
+	if balance.Cmp(threshold) <= 0 {
+		log.Ctx(ctx).Debug().
+			Str("account", sourceAddress.Hex()).
+			Str("balance", balance.String()).
+			Msg("reclaim skipped")
+		return StatusSkip
+	}
+
+	reclaimTx, err := c.transactor.CreateTx(ctx, transactor.TxParams{
+		SenderKeyProvider: account.KeyProvider,
+		ReceiverAddress:   &destinationAddress,
+		Amount:            new(big.Int).Sub(balance, gasCost).String(),
+		GasTipCapValue:    gasTipCapValue,
+		GasFeeCapValue:    gasFeeCapValue,
+	})
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("reclaim failed")
+		return StatusFail
+	}
+
+	err = c.transactor.Transfer(ctx, reclaimTx)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("reclaim failed")
+		return StatusFail
+	}
+
+	timeoutCtx, cancelFunc := context.WithTimeout(ctx, c.confirmationTimeout)
+	defer cancelFunc()
+	receipt, err := c.transactor.VerifyTx(timeoutCtx, reclaimTx.Hash().Hex())
+	if err != nil || !succeeded(receipt) {
+		return StatusPending
+	}
+	return StatusSuccess
 }
 
-func getResult(ctx context.Context, account SourceAccount, status Status) Result {
+// planner returns the planner making the decisions with the collector configuration
+func (c evmCollector) planner() planner {
+	return planner{
+		strategy:             c.strategy,
+		fundingBuffer:        c.fundingBuffer,
+		minimumFundingAmount: c.minimumFundingAmount,
+	}
+}
+
+// handleTransferError marks the token as paused for the rest of the batch
+// when the ERC-20 transfer failed with a pause-like revert
+func (c evmCollector) handleTransferError(ctx context.Context, b *batch, account SourceAccount, phase Phase, err error) Result {
+	if c.detectPausedTokens && isTokenPausedError(err) {
+		b.markTokenPaused(account.Token)
+		log.Ctx(ctx).Warn().Err(err).
+			Str("token", account.Token).
+			Msg("token paused, skipping remaining accounts for token")
+		return getResult(ctx, account, StatusTokenPaused, ReasonTokenPaused)
+	}
+	return handleError(ctx, account, phase, err)
+}
+
+func isTokenPausedError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "paused")
+}
+
+func getResult(ctx context.Context, account SourceAccount, status Status, reason ReasonCode) Result {
+	if !reason.Registered() {
+		log.Ctx(ctx).Error().Str("reason", string(reason)).Msg("unregistered reason code")
+	}
 	result := Result{
 		SourceAccount: account,
 		Status:        status,
+		Reason:        reason,
+		Message:       reason.Description(),
 	}
 	log.Ctx(ctx).Debug().
-		Str("account", account.KeyProvider.GetAddress().Hex()).
+		Str("account", addressHex(account)).
 		Str("status", string(status)).
+		Str("reason", string(reason)).
 		Msg("got result")
 	return result
 }
 
-func handleError(ctx context.Context, account SourceAccount, err error) Result {
+// failureReason returns the reason code of the error failing an account
+func failureReason(err error) ReasonCode {
+	switch {
+	case errors.Is(err, ErrNilKeyProvider):
+		return ReasonInvalidKeyProvider
+	case errors.Is(err, transactor.ErrSignerTypeMismatch):
+		return ReasonSignerTypeMismatch
+	case errors.Is(err, transactor.ErrNodeMinGasPriceAboveCap):
+		return ReasonNodeMinGasPriceAboveCap
+	case errors.Is(err, ErrInsufficientBalance):
+		return ReasonInsufficientBalance
+	case errors.Is(err, ErrInsufficientAllowance):
+		return ReasonInsufficientAllowance
+	case errors.Is(err, ErrInvalidAmount), errors.Is(err, ErrAmountOverflow):
+		return ReasonInvalidAmount
+	case errors.Is(err, ErrSourceIsContract):
+		return ReasonSourceIsContract
+	case errors.Is(err, ErrWalletNotContract):
+		return ReasonWalletNotContract
+	case errors.Is(err, ErrInnerTransferMissing):
+		return ReasonInnerTransferMissing
+	case errors.Is(err, ErrLedgerWriteFailed):
+		return ReasonLedgerWriteFailed
+	case errors.Is(err, transactor.ErrInsufficientFunds):
+		return ReasonInsufficientFunds
+	case errors.Is(err, ErrPermitReverted):
+		return ReasonPermitReverted
+	case errors.Is(err, ErrTokenIDsRequired):
+		return ReasonTokenIDsRequired
+	case errors.Is(err, ErrScreeningFailed):
+		return ReasonScreeningFailed
+	default:
+		return ReasonError
+	}
+}
+
+func handleError(ctx context.Context, account SourceAccount, phase Phase, err error) Result {
 	log.Ctx(ctx).Debug().Err(err).
-		Str("account", account.KeyProvider.GetAddress().Hex()).
+		Str("account", addressHex(account)).
+		Str("phase", string(phase)).
 		Msg("got error")
-	return getResult(ctx, account, StatusFail)
+
+	var result Result
+	switch {
+	case errors.Is(err, context.Canceled):
+		result = getResult(ctx, account, StatusInterrupted, ReasonInterrupted)
+	case errors.Is(err, transactor.ErrBroadcastVetoed):
+		result = getResult(ctx, account, StatusVetoed, ReasonBroadcastVetoed)
+	case errors.Is(err, ErrFundingReverted):
+		result = getResult(ctx, account, StatusFundingReverted, ReasonFundingReverted)
+	case errors.Is(err, transactor.ErrChainStalled):
+		result = getResult(ctx, account, StatusPending, ReasonChainStalled)
+	default:
+		result = getResult(ctx, account, StatusFail, failureReason(err))
+	}
+	result.Phase = phase
+	result.Message = err.Error()
+	result.Err = err
+	return result
 }
@@ -0,0 +1,211 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/welthee/dobermann/client"
+	"github.com/welthee/dobermann/dobermanntest"
+	"github.com/welthee/dobermann/key"
+	"github.com/welthee/dobermann/transactor"
+)
+
+// recordingTransactor a transactor holding balance tokens, recording the calls made to it.
+// The calls following the balance read fail, the other calls panic.
+type recordingTransactor struct {
+	transactor.Transactor
+	balance *big.Int
+	calls   []string
+}
+
+func (t *recordingTransactor) BalanceOf(ctx context.Context, accountAddr common.Address, erc20Address string) (*big.Int, error) {
+	t.calls = append(t.calls, "BalanceOf")
+	return new(big.Int).Set(t.balance), nil
+}
+
+func (t *recordingTransactor) Allowance(ctx context.Context, owner common.Address, spender common.Address, erc20Address string) (*big.Int, error) {
+	t.calls = append(t.calls, "Allowance")
+	return nil, errors.New("unexpected call")
+}
+
+func (t *recordingTransactor) CodeAt(ctx context.Context, accountAddr common.Address) ([]byte, error) {
+	t.calls = append(t.calls, "CodeAt")
+	return nil, errors.New("unexpected call")
+}
+
+func (t *recordingTransactor) GetGasCapValues(ctx context.Context) (*big.Int, *big.Int, error) {
+	t.calls = append(t.calls, "GetGasCapValues")
+	return nil, nil, errors.New("unexpected call")
+}
+
+func TestCollectZeroOutcomes(t *testing.T) {
+	destinationAccount := DestinationAccount{KeyProvider: newTestKeyProvider(t)}
+	tests := []struct {
+		name     string
+		amount   string
+		balance  int64
+		strategy CollectStrategy
+		reason   ReasonCode
+	}{
+		{name: "explicit zero amount", amount: "0", balance: 100, reason: ReasonZeroAmount},
+		{name: "zero amount with leading zeros", amount: "000", balance: 100, reason: ReasonZeroAmount},
+		{name: "zero balance", balance: 0, reason: ReasonZeroBalance},
+		{name: "zero balance with amount", amount: "5", balance: 0, reason: ReasonZeroBalance},
+		{name: "zero amount and zero balance", amount: "0", balance: 0, reason: ReasonZeroAmount},
+		{name: "approve zero amount", amount: "0", balance: 100, strategy: CollectStrategyApprove, reason: ReasonZeroAmount},
+		{name: "approve zero balance", balance: 0, strategy: CollectStrategyApprove, reason: ReasonZeroBalance},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := &recordingTransactor{balance: big.NewInt(test.balance)}
+			c := evmCollector{transactor: recorder, strategy: test.strategy, clock: realClock{}}
+			account := SourceAccount{KeyProvider: newTestKeyProvider(t), Token: testToken, Amount: test.amount}
+
+			result := c.collect(context.Background(), newBatch(), account, destinationAccount)
+			if result.Status != StatusSkip || result.Reason != test.reason {
+				t.Fatalf("result %s %s, want %s %s", result.Status, result.Reason, StatusSkip, test.reason)
+			}
+			if len(recorder.calls) != 1 || recorder.calls[0] != "BalanceOf" {
+				t.Fatalf("calls %v, want only the balance read", recorder.calls)
+			}
+		})
+	}
+}
+
+// blockingTransactor a transactor whose balance reads hold zero tokens, each of them announced on started and
+// answered once release is closed, or failing with the error of their context when it is cancelled first
+type blockingTransactor struct {
+	transactor.Transactor
+	started chan common.Address
+	release chan struct{}
+}
+
+func (t *blockingTransactor) BalanceOf(ctx context.Context, accountAddr common.Address, erc20Address string) (*big.Int, error) {
+	t.started <- accountAddr
+	select {
+	case <-t.release:
+		return big.NewInt(0), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestCollectInterruptedPool(t *testing.T) {
+	tests := []struct {
+		name                string
+		confirmationTimeout time.Duration
+		release             bool
+		inFlight            Status
+	}{
+		{name: "in-flight collections complete", confirmationTimeout: time.Minute, release: true, inFlight: StatusSkip},
+		{name: "in-flight collections past the confirmation timeout", confirmationTimeout: 10 * time.Millisecond,
+			inFlight: StatusInterrupted},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tr := &blockingTransactor{started: make(chan common.Address, 4), release: make(chan struct{})}
+			c := evmCollector{transactor: tr, client: headClient{}, clock: realClock{}, signerType: key.SignerTypeLondon,
+				maxConcurrent: 2, confirmationTimeout: test.confirmationTimeout}
+			destinationAccount := DestinationAccount{KeyProvider: newTestKeyProvider(t)}
+			accounts := make([]SourceAccount, 4)
+			for i := range accounts {
+				accounts[i] = SourceAccount{KeyProvider: newTestKeyProvider(t), Token: testToken}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan []Result)
+			go func() {
+				done <- c.Collect(ctx, destinationAccount, accounts)
+			}()
+			// the two workers of the pool are busy, the other accounts wait for them
+			<-tr.started
+			<-tr.started
+			cancel()
+			if test.release {
+				close(tr.release)
+			}
+			results := <-done
+
+			if len(tr.started) != 0 {
+				t.Fatalf("%d accounts started after the cancellation", len(tr.started))
+			}
+			for i, result := range results {
+				want := StatusInterrupted
+				if i < 2 {
+					want = test.inFlight
+				}
+				if result.Status != want {
+					t.Fatalf("account %d: status %s, want %s", i, result.Status, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectRPCHook(t *testing.T) {
+	tests := []struct {
+		name          string
+		tokenBalance  int64
+		sourceBalance int64
+		status        Status
+		// sends the transactions sent to the node, the funding and the sweep
+		sends int
+	}{
+		{name: "funded sweep", tokenBalance: 100, status: StatusSuccess, sends: 2},
+		{name: "sweep paying its own gas", tokenBalance: 100, sourceBalance: 1e18, status: StatusSuccess, sends: 1},
+		{name: "zero balance", status: StatusSkip},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			destination := newTestKeyProvider(t)
+			source := newTestKeyProvider(t)
+			chain := dobermanntest.NewChain(big.NewInt(1))
+			chain.SetBalance(*destination.GetAddress(), big.NewInt(1e18))
+			chain.SetBalance(*source.GetAddress(), big.NewInt(test.sourceBalance))
+			chain.SetTokenBalance(common.HexToAddress(testToken), *source.GetAddress(), big.NewInt(test.tokenBalance))
+			counting := client.NewCountingHook()
+			collector, err := NewEVMCollector(EVMCollectorConfig{
+				Client:              chain,
+				GasTracker:          dobermanntest.NewGasTracker(30, 90),
+				NonceProviderType:   NonceProviderTypeNetwork,
+				ReceiptPollInterval: time.Millisecond,
+				RPCHook:             counting.Hook(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer collector.Close()
+
+			results := collector.Collect(context.Background(), DestinationAccount{KeyProvider: destination},
+				[]SourceAccount{{KeyProvider: source, Token: testToken}})
+			if results[0].Status != test.status {
+				t.Fatalf("status %s, want %s", results[0].Status, test.status)
+			}
+			if got := counting.Count("eth_sendRawTransaction"); got != test.sends || got != len(chain.Sent()) {
+				t.Fatalf("%d transactions counted, %d sent, want %d", got, len(chain.Sent()), test.sends)
+			}
+			// every sent transaction is confirmed by its receipt
+			if got := counting.Count("eth_getTransactionReceipt"); got < test.sends {
+				t.Fatalf("%d receipts read for %d transactions", got, test.sends)
+			}
+			// the token balance is read through a call, the chain ID once when the collector is created
+			if counting.Count("eth_call") == 0 || counting.Count("eth_chainId") != 1 {
+				t.Fatalf("%d calls and %d chain ID reads", counting.Count("eth_call"), counting.Count("eth_chainId"))
+			}
+			total := 0
+			for _, method := range []string{"eth_chainId", "eth_getBalance", "eth_getTransactionCount", "eth_getCode",
+				"eth_call", "eth_gasPrice", "eth_maxPriorityFeePerGas", "eth_estimateGas", "eth_sendRawTransaction",
+				"eth_blockNumber", "eth_getTransactionReceipt", "eth_getBlockByNumber"} {
+				total += counting.Count(method)
+			}
+			if total != counting.Total() {
+				t.Fatalf("total %d, want the %d calls of the known methods", counting.Total(), total)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/welthee/dobermann/transactor"
+)
+
+// destinationNonce allocates the nonces of the funding transactions, and of the other transactions of the
+// destination, when the accounts are collected concurrently, since the nonce providers do not see the transactions which are not mined yet
+type destinationNonce struct {
+	mu   sync.Mutex
+	next *big.Int
+}
+
+// sendFunding builds and sends a funding transaction of the destination
+func (c evmCollector) sendFunding(ctx context.Context, b *batch, params transactor.TxParams) (*types.Transaction, Phase, error) {
+	return c.submitFunding(ctx, b, params, func(nativTx *types.Transaction) error {
+		return c.transactor.Transfer(ctx, nativTx)
+	})
+}
+
+// submitFunding builds a funding transaction of the destination and hands it to submit. When the accounts are
+// collected concurrently the funding transactions are submitted one at a time, each under the nonce following
+// the previous one, which is only consumed when submit succeeds.
+func (c evmCollector) submitFunding(ctx context.Context, b *batch, params transactor.TxParams,
+	submit func(nativTx *types.Transaction) error) (*types.Transaction, Phase, error) {
+	release := b.destinationNonce.allocate(&params)
+	nativTx, err := c.transactor.CreateTx(ctx, params)
+	if err != nil {
+		release(nil)
+		return nil, PhaseFundingBuild, err
+	}
+	err = submit(nativTx)
+	if err != nil {
+		release(nil)
+		return nativTx, PhaseFundingSend, err
+	}
+	release(nativTx)
+	return nativTx, "", nil
+}
+
+// allocate holds the nonces of the destination until release is called, with the transaction sent under the
+// nonce set on the params or nil when none was sent. It does nothing when the accounts are collected sequentially.
+func (n *destinationNonce) allocate(params *transactor.TxParams) (release func(sent *types.Transaction)) {
+	if n == nil {
+		return func(*types.Transaction) {}
+	}
+	n.mu.Lock()
+	if params.Nonce == nil && n.next != nil {
+		params.Nonce = new(big.Int).Set(n.next)
+	}
+	return func(sent *types.Transaction) {
+		if sent != nil {
+			n.next = new(big.Int).SetUint64(sent.Nonce() + 1)
+		}
+		n.mu.Unlock()
+	}
+}
+
+// accountPool collects the accounts on up to a given number of goroutines,
+// the accounts of the same source address one after another
+type accountPool struct {
+	slots chan struct{}
+	wg    sync.WaitGroup
+
+	mu      sync.Mutex
+	sources map[common.Address]*sync.Mutex
+}
+
+// newAccountPool returns the pool of the given size, nil to collect sequentially when the size is below 2
+func newAccountPool(size int) *accountPool {
+	if size < 2 {
+		return nil
+	}
+	return &accountPool{
+		slots:   make(chan struct{}, size),
+		sources: make(map[common.Address]*sync.Mutex),
+	}
+}
+
+// run calls collect on a goroutine of the pool once one is free, or right away when the pool is nil.
+// It returns false without calling collect when the context is cancelled before a goroutine is free, the
+// collections already started are left to finish.
+func (p *accountPool) run(ctx context.Context, source common.Address, collect func()) bool {
+	if p == nil {
+		collect()
+		return true
+	}
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+	// a free goroutine and the cancellation may be seen at once
+	if ctx.Err() != nil {
+		<-p.slots
+		return false
+	}
+	lock := p.source(source)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() { <-p.slots }()
+		lock.Lock()
+		defer lock.Unlock()
+		collect()
+	}()
+	return true
+}
+
+// wait returns once all the collections finished
+func (p *accountPool) wait() {
+	if p != nil {
+		p.wg.Wait()
+	}
+}
+
+func (p *accountPool) source(address common.Address) *sync.Mutex {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	lock, ok := p.sources[address]
+	if !ok {
+		lock = &sync.Mutex{}
+		p.sources[address] = lock
+	}
+	return lock
+}
+
+// detachedContext carries the values of its parent without its cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
+
+// drainContext returns the context a started collection runs under: it carries the values of ctx but is only
+// cancelled once the timeout passed after ctx is done, so that an interrupted run does not abandon the funding
+// and the sweep it already sent.
+func drainContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	drained, cancel := context.WithCancel(detachedContext{parent: ctx})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-drained.Done():
+			return
+		}
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel()
+		case <-drained.Done():
+		}
+	}()
+	return drained, cancel
+}
@@ -0,0 +1,58 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/transactor"
+)
+
+const defaultConfirmationPollInterval = 10 * time.Second
+
+// awaitConfirmations waits until the block of the receipt is at least the configured number of confirmations
+// behind the head, and returns the receipt read again at that point. It returns nil when the transaction is not
+// mined anymore after a reorg, and the receipt as is when no confirmations are configured.
+func (c evmCollector) awaitConfirmations(ctx context.Context, txHash common.Hash, receipt *types.Receipt) (*types.Receipt, error) {
+	if c.confirmations == 0 {
+		return receipt, nil
+	}
+	interval := c.confirmationPoll
+	if interval <= 0 {
+		interval = defaultConfirmationPollInterval
+	}
+
+	for {
+		head, err := c.client.BlockNumber(ctx)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to get block number")
+		} else if head >= receipt.BlockNumber.Uint64()+c.confirmations {
+			confirmed, err := c.client.TransactionReceipt(ctx, txHash)
+			switch {
+			case errors.Is(err, ethereum.NotFound):
+				log.Ctx(ctx).Warn().Str("tx", txHash.Hex()).Msg("transaction removed by a reorg")
+				return nil, nil
+			case err != nil:
+				log.Ctx(ctx).Warn().Err(err).Str("tx", txHash.Hex()).Msg("failed to get receipt")
+			case confirmed.BlockNumber.Cmp(receipt.BlockNumber) == 0:
+				return confirmed, nil
+			default:
+				// mined again in another block, its confirmations start over
+				receipt = confirmed
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.headWatchdog.Stalled():
+			return nil, fmt.Errorf("%w: waiting for the confirmations of %s", transactor.ErrChainStalled, txHash.Hex())
+		case <-c.clock.After(interval):
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/welthee/dobermann/transactor"
+)
+
+var (
+	// ErrSourceIsContract the source address is a contract for which no ExecutorCalldata is configured
+	ErrSourceIsContract = errors.New("source is a contract")
+	// ErrWalletNotContract the SourceAccount Wallet has no contract code
+	ErrWalletNotContract = errors.New("wallet is not a contract")
+	// ErrInnerTransferMissing the wallet call was mined but it did not emit the expected ERC-20 Transfer
+	ErrInnerTransferMissing = errors.New("wallet call did not transfer the tokens")
+)
+
+// tokenHolder returns the address holding the tokens of the account,
+// the Wallet when set, otherwise the key provider address
+func tokenHolder(account SourceAccount) *common.Address {
+	if account.Wallet != nil {
+		return account.Wallet
+	}
+	return account.KeyProvider.GetAddress()
+}
+
+// resolveExecutor checks the code of the token holder, returning the ExecutorCalldata
+// configured for the wallet code when the holder is a contract wallet and nil for
+// externally owned accounts
+func (c evmCollector) resolveExecutor(ctx context.Context, account SourceAccount, holder common.Address) (transactor.ExecutorCalldata, error) {
+	code, err := c.transactor.CodeAt(ctx, holder)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		if account.Wallet != nil {
+			return nil, fmt.Errorf("%w: %s", ErrWalletNotContract, holder.Hex())
+		}
+		return nil, nil
+	}
+
+	codeHash := crypto.Keccak256Hash(code)
+	executor, ok := c.executors[codeHash]
+	if !ok || account.Wallet == nil {
+		return nil, fmt.Errorf("%w: %s with code hash %s", ErrSourceIsContract, holder.Hex(), codeHash.Hex())
+	}
+	return executor, nil
+}
+
+// verifyInnerTransfer checks that the mined wallet call emitted the ERC-20 Transfer
+// of the amount from the wallet to the destination
+func (c evmCollector) verifyInnerTransfer(ctx context.Context, account SourceAccount, wallet common.Address, destination common.Address, amount string, txHash string) error {
+	receipt, err := c.transactor.GetTxReceipt(ctx, txHash)
+	if err != nil {
+		return err
+	}
+	value, err := parseAmount(amount)
+	if err != nil {
+		return err
+	}
+	if !transactor.HasTransferLog(receipt, common.HexToAddress(account.Token), wallet, destination, value) {
+		return fmt.Errorf("%w: %s", ErrInnerTransferMissing, txHash)
+	}
+	return nil
+}
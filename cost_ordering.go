@@ -0,0 +1,170 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/transactor"
+)
+
+// CostOrdering schedules the accounts of a Collect call by their estimated collection cost, disabled by default.
+// The gas of every transfer is estimated before the first one is sent and the estimate is reused when sending it.
+type CostOrdering struct {
+	Enabled bool
+	// Budget the wei the estimated costs of a single Collect call may add up to, the accounts
+	// over it are deferred. Unlimited when nil
+	Budget *big.Int
+	// Comparators order the accounts, each next comparator decides between the accounts the previous
+	// ones found equal. Defaults to ByEstimatedCost, e.g. ByBalance followed by ByEstimatedCost
+	// collects the largest balances first and the cheapest transfer among equal balances
+	Comparators []AccountComparator
+}
+
+// AccountEstimate the details resolved for a SourceAccount before scheduling it,
+// Balance and EstimatedCost are nil when they could not be resolved
+type AccountEstimate struct {
+	Account SourceAccount
+	// Balance the token balance of the account
+	Balance *big.Int
+	// EstimatedCost the gas limit of the transfer multiplied by the current gas fee cap, in wei
+	EstimatedCost *big.Int
+}
+
+// AccountComparator returns a negative number when a has to be collected before b,
+// a positive one when b has to be collected first and zero when they are equal
+type AccountComparator func(a AccountEstimate, b AccountEstimate) int
+
+// ByEstimatedCost collects the cheapest transfers first
+func ByEstimatedCost(a AccountEstimate, b AccountEstimate) int {
+	return compareKnown(a.EstimatedCost, b.EstimatedCost)
+}
+
+// ByBalance collects the largest balances first
+func ByBalance(a AccountEstimate, b AccountEstimate) int {
+	if a.Balance == nil || b.Balance == nil {
+		return compareKnown(a.Balance, b.Balance)
+	}
+	return b.Balance.Cmp(a.Balance)
+}
+
+// compareKnown compares the values ascending, unknown values are placed last
+func compareKnown(a *big.Int, b *big.Int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return a.Cmp(b)
+}
+
+// scheduledAccount an account in collection order, index is its position in the given accounts
+type scheduledAccount struct {
+	index   int
+	account SourceAccount
+	cost    *big.Int
+}
+
+// scheduleAccounts returns the accounts in collection order. Without CostOrdering, or when the
+// accounts cannot be collected, the given order is kept and nothing is estimated.
+func (c evmCollector) scheduleAccounts(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount, valid bool) []scheduledAccount {
+	scheduled := make([]scheduledAccount, len(accounts))
+	for i, account := range accounts {
+		scheduled[i] = scheduledAccount{index: i, account: account}
+	}
+	if !c.costOrdering.Enabled || !valid {
+		return scheduled
+	}
+
+	_, gasFeeCapValue, err := c.transactor.GetGasCapValues(ctx)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to get fees, accounts not ordered by cost")
+		return scheduled
+	}
+
+	estimates := make([]AccountEstimate, len(accounts))
+	for i := range scheduled {
+		estimates[i] = c.estimateAccount(ctx, &scheduled[i], destinationAccount, gasFeeCapValue)
+	}
+
+	comparators := c.costOrdering.Comparators
+	if len(comparators) == 0 {
+		comparators = []AccountComparator{ByEstimatedCost}
+	}
+	order := make([]int, len(accounts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		for _, compare := range comparators {
+			if cmp := compare(estimates[order[i]], estimates[order[j]]); cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+
+	ordered := make([]scheduledAccount, len(order))
+	for i, index := range order {
+		ordered[i] = scheduled[index]
+	}
+	return ordered
+}
+
+// estimateAccount resolves the balance and the transfer cost of the scheduled account. The estimated
+// gas limit is set on the account so that the transfer is not estimated a second time.
+// Failures are left to the collection, which reports them with the failing phase.
+func (c evmCollector) estimateAccount(ctx context.Context, s *scheduledAccount, destinationAccount DestinationAccount, gasFeeCapValue *big.Int) AccountEstimate {
+	estimate := AccountEstimate{Account: s.account}
+	account := s.account
+	if validateKeyProvider(account.KeyProvider) != nil || isSelfCollection(account, destinationAccount) || account.ERC721 {
+		return estimate
+	}
+
+	holderAddress := tokenHolder(account)
+	snapshot, err := c.amountSnapshot(ctx, account, *holderAddress, *destinationAccount.KeyProvider.GetAddress())
+	if err != nil {
+		return estimate
+	}
+	estimate.Balance = snapshot.tokenBalance
+	decision := c.planner().planAmount(snapshot)
+	if !decision.collect() {
+		return estimate
+	}
+	amount := decision.amount
+
+	gasLimit := account.GasLimit
+	if gasLimit == 0 {
+		executor, err := c.resolveExecutor(ctx, account, *holderAddress)
+		if err != nil {
+			return estimate
+		}
+		params := transactor.TxParams{
+			TokenAddr:           account.Token,
+			SenderKeyProvider:   account.KeyProvider,
+			ReceiverKeyProvider: destinationAccount.KeyProvider,
+			Amount:              amount.String(),
+		}
+		if executor != nil {
+			params.Wallet = holderAddress
+			params.Executor = executor
+		}
+		gasLimit, err = c.transactor.EstimateERC20Gas(ctx, params)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("account", addressHex(account)).Msg("failed to estimate gas")
+			return estimate
+		}
+		// the approve strategy sends another call, its gas is estimated when it is built
+		if c.strategy != CollectStrategyApprove {
+			s.account.GasLimit = gasLimit
+		}
+	}
+
+	estimate.EstimatedCost = new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasFeeCapValue)
+	s.cost = estimate.EstimatedCost
+	return estimate
+}
@@ -0,0 +1,115 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultDestinationFundsPollInterval = time.Minute
+
+// DestinationFundsWait pauses the collection while the destination can not fund the next account, e.g. until
+// it is topped up during a long run, instead of letting its funding fail
+type DestinationFundsWait struct {
+	Enabled bool
+	// PollInterval between balance checks while paused, one minute when zero, with a random jitter of up to 20%
+	PollInterval time.Duration
+	// MaxPause the longest time to wait for a top-up, after which the account and the following ones
+	// needing more than the destination balance are skipped without waiting
+	MaxPause time.Duration
+	// OnWait is invoked every time the collector polls the destination balance while paused
+	OnWait func(ctx context.Context, event DestinationFundsEvent)
+}
+
+// DestinationFundsEvent the state of a pause waiting for the destination to be topped up
+type DestinationFundsEvent struct {
+	Destination string `json:"destination"`
+	Balance     Wei    `json:"balance"`
+	// Required the funding amount of the account plus the gas of the funding transaction
+	Required Wei `json:"required"`
+	// Waited since the pause started
+	Waited time.Duration `json:"waited"`
+}
+
+// destinationFunds keeps whether a pause of the batch reached the MaxPause
+type destinationFunds struct {
+	mu      sync.Mutex
+	expired bool
+}
+
+func (d *destinationFunds) isExpired() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.expired
+}
+
+func (d *destinationFunds) expire() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.expired = true
+}
+
+// fundingCost the wei the destination needs to fund the account: the funding amount plus the gas of the transaction
+func (c evmCollector) fundingCost(col *collection) *big.Int {
+	gas := params.TxGas + uint64(len(c.fundingTxTag))*params.TxDataNonZeroGasEIP2028
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(gas), col.gasFeeCapValue)
+	return cost.Add(cost, col.fundingAmount)
+}
+
+// awaitDestinationFunds returns whether the destination balance covers the required amount, pausing
+// until it is topped up when it does not. Once a pause of the batch reached the MaxPause, it does not wait anymore.
+func (c evmCollector) awaitDestinationFunds(ctx context.Context, b *batch, destination common.Address, required *big.Int) (bool, error) {
+	wait := c.destinationFundsWait
+	pollInterval := wait.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultDestinationFundsPollInterval
+	}
+	started := c.clock.Now()
+	deadline := started.Add(wait.MaxPause)
+	paused := false
+
+	for {
+		balance, err := c.transactor.BalanceAt(ctx, destination)
+		if err != nil {
+			return false, err
+		}
+		if balance.Cmp(required) >= 0 {
+			if paused {
+				log.Ctx(ctx).Info().Str("balance", balance.String()).Msg("destination topped up, resuming")
+			}
+			return true, nil
+		}
+		if b.destinationFunds.isExpired() || !c.clock.Now().Before(deadline) {
+			b.destinationFunds.expire()
+			log.Ctx(ctx).Warn().
+				Str("balance", balance.String()).
+				Str("required", required.String()).
+				Msg("insufficient destination funds")
+			return false, nil
+		}
+
+		paused = true
+		log.Ctx(ctx).Info().
+			Str("balance", balance.String()).
+			Str("required", required.String()).
+			Msg("waiting for destination funds")
+		if wait.OnWait != nil {
+			wait.OnWait(ctx, DestinationFundsEvent{
+				Destination: destination.Hex(),
+				Balance:     NewWei(balance),
+				Required:    NewWei(required),
+				Waited:      c.clock.Now().Sub(started),
+			})
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-c.clock.After(c.jitter.apply(pollInterval)):
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// Package dobermanntest provides in-memory fakes of the blockchain node, the gas tracker and the KMS service, to
+// test and try out the collectors without a network.
+package dobermanntest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/welthee/dobermann/client"
+	"github.com/welthee/dobermann/transactor"
+)
+
+const (
+	// NativeTransferGas the gas of the transactions without calldata
+	NativeTransferGas = 21_000
+	// TokenTransferGas the gas of the token transfers
+	TokenTransferGas = 52_000
+)
+
+var (
+	// balanceOfSelector the selector of the ERC-20 balanceOf(address)
+	balanceOfSelector = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+	// tokenCode the runtime code of the tokens, only checked for being set
+	tokenCode = []byte{0x60, 0x80, 0x60, 0x40}
+)
+
+// Chain is a fake node implementing client.Client. It mines every sent transaction right away in its own block,
+// moving the native balances and, for the transfer calls of its tokens, the token balances. It is safe for
+// concurrent use.
+type Chain struct {
+	mu       sync.Mutex
+	chainID  *big.Int
+	signer   types.Signer
+	baseFee  *big.Int
+	gasTip   *big.Int
+	head     uint64
+	balances map[common.Address]*big.Int
+	nonces   map[common.Address]uint64
+	tokens   map[common.Address]map[common.Address]*big.Int
+	receipts map[common.Hash]*types.Receipt
+	sent     []*types.Transaction
+}
+
+var _ client.Client = (*Chain)(nil)
+
+// NewChain returns a chain with the ID, at block 1, with a base fee of 30 gwei and a suggested tip of 2 gwei
+func NewChain(chainID *big.Int) *Chain {
+	return &Chain{
+		chainID:  new(big.Int).Set(chainID),
+		signer:   types.LatestSignerForChainID(chainID),
+		baseFee:  big.NewInt(30_000_000_000),
+		gasTip:   big.NewInt(2_000_000_000),
+		head:     1,
+		balances: make(map[common.Address]*big.Int),
+		nonces:   make(map[common.Address]uint64),
+		tokens:   make(map[common.Address]map[common.Address]*big.Int),
+		receipts: make(map[common.Hash]*types.Receipt),
+	}
+}
+
+// SetBalance sets the wei balance of the account
+func (c *Chain) SetBalance(account common.Address, wei *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.balances[account] = new(big.Int).Set(wei)
+}
+
+// SetTokenBalance sets the balance of the account in the token, deploying the token when it is new
+func (c *Chain) SetTokenBalance(token common.Address, account common.Address, amount *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenBalances(token)[account] = new(big.Int).Set(amount)
+}
+
+// SetBaseFee sets the base fee of the next blocks
+func (c *Chain) SetBaseFee(wei *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.baseFee = new(big.Int).Set(wei)
+}
+
+// Balance returns the wei balance of the account
+func (c *Chain) Balance(account common.Address) *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.balance(account))
+}
+
+// TokenBalance returns the balance of the account in the token
+func (c *Chain) TokenBalance(token common.Address, account common.Address) *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.tokenBalance(token, account))
+}
+
+// Sent returns the transactions sent so far, in the order they were mined
+func (c *Chain) Sent() []*types.Transaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*types.Transaction(nil), c.sent...)
+}
+
+func (c *Chain) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(c.chainID), nil
+}
+
+func (c *Chain) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return c.Balance(account), nil
+}
+
+func (c *Chain) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nonces[account], nil
+}
+
+// PendingNonceAt returns the nonce of the account, the transactions never waiting in a pool
+func (c *Chain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return c.NonceAt(ctx, account, nil)
+}
+
+func (c *Chain) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.tokens[account]; ok {
+		return tokenCode, nil
+	}
+	return nil, nil
+}
+
+// CallContract answers the balanceOf calls of the tokens, the other calls fail
+func (c *Chain) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if msg.To == nil {
+		return nil, errors.New("execution reverted")
+	}
+	if _, ok := c.tokens[*msg.To]; !ok {
+		return nil, nil
+	}
+	if len(msg.Data) != 4+32 || !bytes.Equal(msg.Data[:4], balanceOfSelector) {
+		return nil, errors.New("execution reverted")
+	}
+	account := common.BytesToAddress(msg.Data[4:])
+	return common.LeftPadBytes(c.tokenBalance(*msg.To, account).Bytes(), 32), nil
+}
+
+// SuggestGasPrice returns the base fee plus the suggested tip
+func (c *Chain) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Add(c.baseFee, c.gasTip), nil
+}
+
+func (c *Chain) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.gasTip), nil
+}
+
+// EstimateGas returns TokenTransferGas for the calls with calldata, NativeTransferGas for the others
+func (c *Chain) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return gasOf(msg.Data), nil
+}
+
+// SendTransaction mines the transaction in a new block. It fails like geth when the nonce is not the next one of the
+// sender, when the transaction was already sent or when the sender can not pay for it. A call of a token other than
+// a transfer within the balance of the sender is mined with a failed receipt.
+func (c *Chain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	from, err := types.Sender(c.signer, tx)
+	if err != nil {
+		return fmt.Errorf("invalid sender: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.receipts[tx.Hash()]; ok {
+		return errors.New("already known")
+	}
+	nonce := c.nonces[from]
+	switch {
+	case tx.Nonce() < nonce:
+		return fmt.Errorf("nonce too low: next nonce %d, tx nonce %d", nonce, tx.Nonce())
+	case tx.Nonce() > nonce:
+		return fmt.Errorf("nonce too high: next nonce %d, tx nonce %d", nonce, tx.Nonce())
+	}
+	if tx.GasFeeCap().Cmp(c.baseFee) < 0 {
+		return fmt.Errorf("max fee per gas less than block base fee: maxFeePerGas: %s baseFee: %s", tx.GasFeeCap(), c.baseFee)
+	}
+	gasUsed := gasOf(tx.Data())
+	if tx.Gas() < gasUsed {
+		return fmt.Errorf("intrinsic gas too low: have %d, want %d", tx.Gas(), gasUsed)
+	}
+	balance := c.balance(from)
+	if balance.Cmp(tx.Cost()) < 0 {
+		return fmt.Errorf("insufficient funds for gas * price + value: address %s have %s want %s", from, balance, tx.Cost())
+	}
+
+	price := new(big.Int).Add(c.baseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		price = tx.GasFeeCap()
+	}
+	fee := new(big.Int).Mul(price, new(big.Int).SetUint64(gasUsed))
+	c.balances[from] = new(big.Int).Sub(balance, fee.Add(fee, tx.Value()))
+	if tx.To() != nil {
+		c.balances[*tx.To()] = new(big.Int).Add(c.balance(*tx.To()), tx.Value())
+	}
+	status := types.ReceiptStatusSuccessful
+	if tx.To() != nil && len(tx.Data()) > 0 && !c.transferToken(*tx.To(), from, tx.Data()) {
+		status = types.ReceiptStatusFailed
+	}
+	c.nonces[from] = nonce + 1
+	c.head++
+	c.sent = append(c.sent, tx)
+	c.receipts[tx.Hash()] = &types.Receipt{
+		Type:              tx.Type(),
+		Status:            status,
+		CumulativeGasUsed: gasUsed,
+		TxHash:            tx.Hash(),
+		GasUsed:           gasUsed,
+		EffectiveGasPrice: price,
+		BlockHash:         blockHash(c.head),
+		BlockNumber:       new(big.Int).SetUint64(c.head),
+	}
+	return nil
+}
+
+func (c *Chain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	receipt, ok := c.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func (c *Chain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.head, nil
+}
+
+// HeaderByNumber returns the header of a past block, the blocks being 2 seconds apart
+func (c *Chain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	head := c.head
+	if number != nil {
+		if !number.IsUint64() || number.Uint64() > c.head {
+			return nil, ethereum.NotFound
+		}
+		head = number.Uint64()
+	}
+	return &types.Header{
+		ParentHash: blockHash(head - 1),
+		Number:     new(big.Int).SetUint64(head),
+		GasLimit:   30_000_000,
+		Time:       head * 2,
+		BaseFee:    new(big.Int).Set(c.baseFee),
+	}, nil
+}
+
+// transferToken applies the ERC-20 transfer call of the token, it returns false when the call fails. The calldata
+// sent to the other accounts, e.g. a tag of a funding transaction, is ignored.
+func (c *Chain) transferToken(token common.Address, from common.Address, data []byte) bool {
+	if _, ok := c.tokens[token]; !ok {
+		return true
+	}
+	to, amount, err := transactor.DecodeERC20Transfer(data)
+	if err != nil {
+		return false
+	}
+	balance := c.tokenBalance(token, from)
+	if balance.Cmp(amount) < 0 {
+		return false
+	}
+	balances := c.tokenBalances(token)
+	balances[from] = new(big.Int).Sub(balance, amount)
+	balances[to] = new(big.Int).Add(c.tokenBalance(token, to), amount)
+	return true
+}
+
+func (c *Chain) balance(account common.Address) *big.Int {
+	if balance, ok := c.balances[account]; ok {
+		return balance
+	}
+	return new(big.Int)
+}
+
+func (c *Chain) tokenBalances(token common.Address) map[common.Address]*big.Int {
+	balances, ok := c.tokens[token]
+	if !ok {
+		balances = make(map[common.Address]*big.Int)
+		c.tokens[token] = balances
+	}
+	return balances
+}
+
+func (c *Chain) tokenBalance(token common.Address, account common.Address) *big.Int {
+	if balance, ok := c.tokens[token][account]; ok {
+		return balance
+	}
+	return new(big.Int)
+}
+
+func gasOf(data []byte) uint64 {
+	if len(data) > 0 {
+		return TokenTransferGas
+	}
+	return NativeTransferGas
+}
+
+func blockHash(number uint64) common.Hash {
+	return crypto.Keccak256Hash([]byte("block"), new(big.Int).SetUint64(number).Bytes())
+}
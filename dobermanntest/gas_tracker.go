@@ -0,0 +1,70 @@
+package dobermanntest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/welthee/dobermann/transactor"
+)
+
+// ErrGasTrackerUnavailable the error of an unavailable GasTracker
+var ErrGasTrackerUnavailable = errors.New("gas tracker unavailable")
+
+// GasTracker is a fake transactor.GasTracker suggesting the same fees on all its tiers, or failing with
+// ErrGasTrackerUnavailable while it is unavailable. It is safe for concurrent use.
+type GasTracker struct {
+	mu             sync.Mutex
+	maxPriorityFee float64
+	maxFee         float64
+	unavailable    bool
+	calls          int
+}
+
+var _ transactor.GasTracker = (*GasTracker)(nil)
+
+// NewGasTracker returns a gas tracker suggesting the fees, in gwei
+func NewGasTracker(maxPriorityFee float64, maxFee float64) *GasTracker {
+	return &GasTracker{maxPriorityFee: maxPriorityFee, maxFee: maxFee}
+}
+
+// NewUnavailableGasTracker returns a gas tracker failing every request, e.g. to collect with the fees suggested by
+// the node
+func NewUnavailableGasTracker() *GasTracker {
+	return &GasTracker{unavailable: true}
+}
+
+// SetFees sets the suggested fees, in gwei
+func (t *GasTracker) SetFees(maxPriorityFee float64, maxFee float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maxPriorityFee, t.maxFee = maxPriorityFee, maxFee
+}
+
+// SetUnavailable makes the following requests fail, or succeed again
+func (t *GasTracker) SetUnavailable(unavailable bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.unavailable = unavailable
+}
+
+// Calls returns the number of requests made so far, the failed ones included
+func (t *GasTracker) Calls() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.calls
+}
+
+func (t *GasTracker) GetSuggestedGasPrice(ctx context.Context) (*transactor.GasTrackerResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls++
+	if t.unavailable {
+		return nil, ErrGasTrackerUnavailable
+	}
+	response := &transactor.GasTrackerResponse{}
+	response.SafeLow.MaxPriorityFee, response.SafeLow.MaxFee = t.maxPriorityFee, t.maxFee
+	response.Standard.MaxPriorityFee, response.Standard.MaxFee = t.maxPriorityFee, t.maxFee
+	response.Fast.MaxPriorityFee, response.Fast.MaxFee = t.maxPriorityFee, t.maxFee
+	return response, nil
+}
@@ -0,0 +1,93 @@
+package dobermanntest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// ciphertextPrefix marks the ciphertexts of the fake KMS service, followed by the key ID and the plaintext
+const ciphertextPrefix = "dobermanntest:"
+
+// NewKMSClient returns a KMS client served in memory instead of by AWS. Its Encrypt wraps the plaintext in a fake
+// ciphertext bound to the key ID, which its Decrypt unwraps for the same key ID only. The other operations fail.
+func NewKMSClient() *kms.Client {
+	return kms.New(kms.Options{
+		Region:           "us-east-1",
+		Credentials:      aws.AnonymousCredentials{},
+		HTTPClient:       kmsService{},
+		RetryMaxAttempts: 1,
+	})
+}
+
+// EncryptKey returns the private key encrypted by the client of NewKMSClient with the key ID, base64 encoded like
+// the encrypted keys read by the key/pk/kms providers
+func EncryptKey(keyID string, privateKeyHex string) string {
+	return base64.StdEncoding.EncodeToString(ciphertext(keyID, []byte(privateKeyHex)))
+}
+
+func ciphertext(keyID string, plaintext []byte) []byte {
+	return append([]byte(ciphertextPrefix+keyID+":"), plaintext...)
+}
+
+// kmsService answers the JSON requests of the KMS client
+type kmsService struct{}
+
+// kmsMessage the fields of the Encrypt and Decrypt requests and responses, the byte slices being base64 encoded
+type kmsMessage struct {
+	KeyId               string `json:",omitempty"`
+	Plaintext           []byte `json:",omitempty"`
+	CiphertextBlob      []byte `json:",omitempty"`
+	EncryptionAlgorithm string `json:",omitempty"`
+}
+
+func (kmsService) Do(request *http.Request) (*http.Response, error) {
+	var message kmsMessage
+	if request.Body != nil {
+		err := json.NewDecoder(request.Body).Decode(&message)
+		if err != nil {
+			return kmsError("SerializationException", err.Error()), nil
+		}
+	}
+	switch request.Header.Get("X-Amz-Target") {
+	case "TrentService.Encrypt":
+		message.CiphertextBlob = ciphertext(message.KeyId, message.Plaintext)
+		message.Plaintext = nil
+	case "TrentService.Decrypt":
+		prefix := []byte(ciphertextPrefix + message.KeyId + ":")
+		if !bytes.HasPrefix(message.CiphertextBlob, prefix) {
+			return kmsError("InvalidCiphertextException", "the ciphertext was not encrypted with the key "+message.KeyId), nil
+		}
+		message.Plaintext = message.CiphertextBlob[len(prefix):]
+		message.CiphertextBlob = nil
+	default:
+		return kmsError("UnsupportedOperationException", fmt.Sprintf("%s not supported", request.Header.Get("X-Amz-Target"))), nil
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	return kmsResponse(http.StatusOK, body), nil
+}
+
+func kmsError(errorType string, message string) *http.Response {
+	body, _ := json.Marshal(map[string]string{"__type": errorType, "message": message})
+	return kmsResponse(http.StatusBadRequest, body)
+}
+
+func kmsResponse(status int, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		Status:        http.StatusText(status),
+		Header:        http.Header{"Content-Type": []string{"application/x-amz-json-1.1"}},
+		Body:          io.NopCloser(strings.NewReader(string(body))),
+		ContentLength: int64(len(body)),
+	}
+}
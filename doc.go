@@ -0,0 +1,53 @@
+// Package dobermann collects ERC-20 tokens from source accounts into a destination account,
+// funding the gas of the source accounts from the destination.
+//
+// A collector is created from an EVMCollectorConfig and has to be closed once it is not used anymore:
+//
+//	collector, err := dobermann.NewEVMCollector(dobermann.EVMCollectorConfig{
+//		BlockchainUrl:     "https://polygon-rpc.com",
+//		GasTrackerUrl:     "https://gasstation.polygon.technology/v2",
+//		NonceProviderType: dobermann.NonceProviderTypeNetwork,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer collector.Close()
+//
+// Every account needs a key.Provider, e.g. created from a private key hex:
+//
+//	chainId := collector.GetChainId(ctx)
+//	sourceKey, err := pk.NewPrivateKeyProvider(sourcePrivateKeyHex, chainId)
+//	if err != nil {
+//		return err
+//	}
+//	destinationKey, err := pk.NewPrivateKeyProvider(destinationPrivateKeyHex, chainId)
+//	if err != nil {
+//		return err
+//	}
+//
+// or from a KMS encrypted private key, decrypted with the given KMS client:
+//
+//	sourceKey, err := kms.NewKmsEncryptedPrivateKeyProvider(kmsClient, kmsKeyId, encryptedKeyHex, chainId)
+//
+// Collect returns one Result per source account, in the order of the given accounts. An empty Amount
+// collects the whole token balance:
+//
+//	results := collector.Collect(ctx, dobermann.DestinationAccount{KeyProvider: destinationKey}, []dobermann.SourceAccount{
+//		{KeyProvider: sourceKey, Token: tokenAddress},
+//	})
+//	for _, result := range results {
+//		if result.Status != dobermann.StatusSuccess {
+//			log.Warn().Str("reason", string(result.Reason)).Str("phase", string(result.Phase)).Msg("not collected")
+//		}
+//	}
+//
+// The results can be written as JSON with NewRunReport, all the wei amounts being decimal strings:
+//
+//	data, err := json.MarshalIndent(dobermann.NewRunReport(results), "", "  ")
+//
+// Each Result is also passed to the AfterCollect hook as soon as its account completes, which can be used to
+// process the results while the collection is still running.
+//
+// The examples run by go test collect from the in-memory chain of the dobermanntest package, which also provides a
+// fake gas tracker and a fake KMS client.
+package dobermann
@@ -0,0 +1,75 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+	"strings"
+)
+
+// DryRunSummary the totals of the accounts a dry run would collect, the ones with StatusSimulated
+type DryRunSummary struct {
+	// Accounts the number of accounts which would be collected
+	Accounts int `json:"accounts"`
+	// Amounts the wei which would be collected per lower case token address, the native balances under ""
+	Amounts map[string]Wei `json:"amounts"`
+	// FundingAmount the wei the destination would send to fund the accounts, without the gas of the fundings
+	FundingAmount Wei `json:"fundingAmount"`
+	// EstimatedFee the most the sweeps and the fundings would cost
+	EstimatedFee Wei `json:"estimatedFee"`
+}
+
+func newDryRunSummary() *DryRunSummary {
+	return &DryRunSummary{
+		Amounts:       make(map[string]Wei),
+		FundingAmount: NewWei(new(big.Int)),
+		EstimatedFee:  NewWei(new(big.Int)),
+	}
+}
+
+// add adds the simulated result and the ones of its further tokens to the totals
+func (s *DryRunSummary) add(result Result) {
+	s.Accounts++
+	addWei(s.FundingAmount.Int, result.FundingAmount)
+	addWei(s.EstimatedFee.Int, result.EstimatedFee)
+	results := append([]Result{result}, result.Tokens...)
+	for _, r := range results {
+		if r.Status != StatusSimulated {
+			continue
+		}
+		token := strings.ToLower(r.SourceAccount.Token)
+		amount, ok := s.Amounts[token]
+		if !ok {
+			amount = NewWei(new(big.Int))
+			s.Amounts[token] = amount
+		}
+		addWei(amount.Int, r.ResolvedAmount)
+	}
+}
+
+// addWei adds the decimal wei value to the total, ignoring empty and invalid values
+func addWei(total *big.Int, value string) {
+	if amount := parseWei(value); amount != nil {
+		total.Add(total, amount.Int)
+	}
+}
+
+// simulate returns the result of the prepared account without funding or sweeping it, with the amount
+// which would be collected and the fees it would cost at most
+func (c evmCollector) simulate(ctx context.Context, col *collection) Result {
+	result := getResult(ctx, col.account, StatusSimulated, ReasonDryRun)
+	if col.needsFunding() {
+		result.FundingAmount = col.fundingAmount.String()
+	}
+	result.ResolvedAmount = col.amount
+	result.EstimatedFee = c.estimatedFee(col).String()
+	return result
+}
+
+// estimatedFee the most the sweep and, when needed, the funding of the prepared account cost
+func (c evmCollector) estimatedFee(col *collection) *big.Int {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(col.erc20Tx.Gas()), col.erc20Tx.GasFeeCap())
+	if col.needsFunding() {
+		fee.Add(fee, new(big.Int).Sub(c.fundingCost(col), col.fundingAmount))
+	}
+	return fee
+}
@@ -0,0 +1,156 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/welthee/dobermann/transactor"
+)
+
+var (
+	// ErrTokenIDsRequired the ERC-721 collection is not enumerable, the tokens of the account have to be listed
+	// in the SourceAccount TokenIDs
+	ErrTokenIDsRequired = errors.New("token ids required")
+	// ErrInvalidERC721Account the ERC721 source account has no Token, a Wallet, further Tokens or an invalid TokenIDs
+	ErrInvalidERC721Account = errors.New("invalid erc-721 account")
+)
+
+// validateERC721 checks the ERC-721 fields of the account
+func validateERC721(account SourceAccount) error {
+	if !account.ERC721 {
+		if len(account.TokenIDs) > 0 {
+			return fmt.Errorf("%w: token ids set without ERC721", ErrInvalidERC721Account)
+		}
+		return nil
+	}
+	switch {
+	case isNative(account):
+		return fmt.Errorf("%w: no collection", ErrInvalidERC721Account)
+	case account.Wallet != nil:
+		return fmt.Errorf("%w: contract wallets are not supported", ErrInvalidERC721Account)
+	case len(account.Tokens) > 0:
+		return fmt.Errorf("%w: further tokens are not supported", ErrInvalidERC721Account)
+	}
+	_, err := parseTokenIDs(account.TokenIDs)
+	return err
+}
+
+// parseTokenIDs parses the decimal token ids, which are uint256 like the amounts
+func parseTokenIDs(ids []string) ([]*big.Int, error) {
+	tokenIDs := make([]*big.Int, 0, len(ids))
+	for _, id := range ids {
+		tokenID, err := parseAmount(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: token id: %w", ErrInvalidERC721Account, err)
+		}
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+	return tokenIDs, nil
+}
+
+// collectERC721 collects the ERC-721 tokens of the account in one pass, like the Tokens of an ERC-20 account: the
+// transfers are prepared under consecutive nonces and the account is funded once for all of them. The result is
+// the one of the first token, the others being in its Tokens.
+func (c evmCollector) collectERC721(ctx context.Context, b *batch, account SourceAccount, destinationAccount DestinationAccount) Result {
+	accountCtx, cancel := b.controller.accountContext(ctx, *account.KeyProvider.GetAddress())
+	defer cancel()
+
+	tokenIDs, err := c.erc721TokenIDs(accountCtx, account)
+	if err != nil {
+		return c.cancelledResult(ctx, b, account, handleError(ctx, account, PhaseBalanceCheck, err))
+	}
+	if len(tokenIDs) == 0 {
+		return getResult(ctx, account, StatusSkip, ReasonZeroBalance)
+	}
+
+	results := make([]Result, len(tokenIDs))
+	cols := make([]*collection, len(tokenIDs))
+	nonce, backlog, result, ok := c.checkBacklog(accountCtx, account)
+	if !ok {
+		return c.cancelledResult(ctx, b, account, result)
+	}
+	for i, tokenID := range tokenIDs {
+		tokenAccount := account
+		tokenAccount.TokenIDs = []string{tokenID.String()}
+		col, result := c.prepareERC721(accountCtx, b, tokenAccount, destinationAccount, tokenID, nonce)
+		if col == nil {
+			results[i] = c.cancelledResult(ctx, b, tokenAccount, result)
+			continue
+		}
+		col.backlog = backlog
+		cols[i] = col
+		nonce = new(big.Int).SetUint64(col.erc20Tx.Nonce() + 1)
+	}
+	return tokenResults(c.collectPrepared(ctx, accountCtx, b, account, destinationAccount, cols, results))
+}
+
+// erc721TokenIDs returns the TokenIDs of the account, or all the tokens it owns when none is set
+func (c evmCollector) erc721TokenIDs(ctx context.Context, account SourceAccount) ([]*big.Int, error) {
+	if len(account.TokenIDs) > 0 {
+		return parseTokenIDs(account.TokenIDs)
+	}
+	tokenIDs, err := c.transactor.ERC721TokensOfOwner(ctx, *account.KeyProvider.GetAddress(), account.Token)
+	if errors.Is(err, transactor.ErrNotEnumerable) {
+		return nil, fmt.Errorf("%w: %w", ErrTokenIDsRequired, err)
+	}
+	return tokenIDs, err
+}
+
+// prepareERC721 prepares the transfer of the token under the given nonce, returning a nil collection with the final
+// result of the token when the account does not own it. The funding of the account is planned by collectPrepared.
+func (c evmCollector) prepareERC721(ctx context.Context, b *batch, account SourceAccount, destinationAccount DestinationAccount,
+	tokenID *big.Int, nonce *big.Int) (*collection, Result) {
+	sourceAddress := account.KeyProvider.GetAddress()
+	destinationAddress := destinationAccount.KeyProvider.GetAddress()
+	if sourceAddress == nil || destinationAddress == nil {
+		return nil, handleError(ctx, account, PhaseValidation, ErrNilKeyProvider)
+	}
+
+	if c.detectPausedTokens && b.isTokenPaused(account.Token) {
+		return nil, getResult(ctx, account, StatusTokenPaused, ReasonTokenPaused)
+	}
+
+	owner, err := c.transactor.ERC721OwnerOf(ctx, account.Token, tokenID)
+	if err != nil {
+		return nil, handleError(ctx, account, PhaseBalanceCheck, err)
+	}
+	if owner != *sourceAddress {
+		return nil, getResult(ctx, account, StatusSkip, ReasonNotTokenOwner)
+	}
+
+	gasTipCapValue, gasFeeCapValue, err := c.transactor.GetGasCapValues(ctx)
+	if err != nil {
+		return nil, handleError(ctx, account, PhaseGasFetch, err)
+	}
+
+	params := transactor.TxParams{
+		TokenAddr:           account.Token,
+		SenderKeyProvider:   account.KeyProvider,
+		ReceiverKeyProvider: destinationAccount.KeyProvider,
+		TokenID:             tokenID,
+		GasTipCapValue:      gasTipCapValue,
+		GasFeeCapValue:      gasFeeCapValue,
+		GasLimit:            account.GasLimit,
+		Nonce:               nonce,
+	}
+	tx, err := c.transactor.CreateERC721Tx(ctx, params)
+	if err != nil {
+		return nil, c.handleTransferError(ctx, b, account, PhaseSweepBuild, err)
+	}
+
+	return &collection{
+		account:            account,
+		sourceAddress:      sourceAddress,
+		destinationAddress: destinationAddress,
+		holderAddress:      sourceAddress,
+		amount:             "1",
+		gasTipCapValue:     gasTipCapValue,
+		gasFeeCapValue:     gasFeeCapValue,
+		params:             params,
+		erc20Tx:            tx,
+		fundingAmount:      big.NewInt(0),
+		tokenID:            tokenID,
+	}, Result{}
+}
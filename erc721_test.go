@@ -0,0 +1,209 @@
+package dobermann
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/welthee/dobermann/dobermanntest"
+	"github.com/welthee/dobermann/tokens"
+)
+
+const testCollection = "0x00000000000000000000000000000000000000cc"
+
+// nftChain a chain holding the ERC-721 collection testCollection, enumerable or not, whose safeTransferFrom calls
+// move the tokens of their sender
+type nftChain struct {
+	*dobermanntest.Chain
+	enumerable bool
+
+	mu     sync.Mutex
+	owners map[int64]common.Address
+}
+
+func (c *nftChain) owner(tokenID int64) common.Address {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.owners[tokenID]
+}
+
+func (c *nftChain) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	if account == common.HexToAddress(testCollection) {
+		return []byte{0x60, 0x80}, nil
+	}
+	return c.Chain.CodeAt(ctx, account, blockNumber)
+}
+
+func (c *nftChain) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if msg.To == nil || *msg.To != common.HexToAddress(testCollection) {
+		return c.Chain.CallContract(ctx, msg, blockNumber)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	selector, args := msg.Data[:4], msg.Data[4:]
+	switch {
+	case bytes.Equal(selector, tokens.SelectorSupportsInterface[:]) && c.enumerable:
+		return common.LeftPadBytes([]byte{1}, 32), nil
+	case bytes.Equal(selector, tokens.SelectorBalanceOf[:]):
+		owned := c.owned(common.BytesToAddress(args[:32]))
+		return common.LeftPadBytes(big.NewInt(int64(len(owned))).Bytes(), 32), nil
+	case bytes.Equal(selector, tokens.SelectorTokenOfOwnerByIndex[:]) && c.enumerable:
+		owned := c.owned(common.BytesToAddress(args[:32]))
+		index := new(big.Int).SetBytes(args[32:64]).Int64()
+		if index >= int64(len(owned)) {
+			break
+		}
+		return common.LeftPadBytes(big.NewInt(owned[index]).Bytes(), 32), nil
+	case bytes.Equal(selector, tokens.SelectorOwnerOf[:]):
+		owner, ok := c.owners[new(big.Int).SetBytes(args[:32]).Int64()]
+		if !ok {
+			break
+		}
+		return common.LeftPadBytes(owner.Bytes(), 32), nil
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func (c *nftChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	err := c.Chain.SendTransaction(ctx, tx)
+	if err != nil || tx.To() == nil || *tx.To() != common.HexToAddress(testCollection) {
+		return err
+	}
+	data := tx.Data()
+	if len(data) != 4+3*32 || !bytes.Equal(data[:4], tokens.SelectorSafeTransferFrom[:]) {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	from, to := common.BytesToAddress(data[4:36]), common.BytesToAddress(data[36:68])
+	tokenID := new(big.Int).SetBytes(data[68:]).Int64()
+	if c.owners[tokenID] == from {
+		c.owners[tokenID] = to
+	}
+	return nil
+}
+
+// owned returns the sorted ids of the tokens of the owner, the lock must be held
+func (c *nftChain) owned(owner common.Address) []int64 {
+	ids := make([]int64, 0)
+	for id, tokenOwner := range c.owners {
+		if tokenOwner == owner {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func TestCollectERC721(t *testing.T) {
+	other := common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	tests := []struct {
+		name       string
+		enumerable bool
+		// owned the tokens of the source account, token 5 being owned by another account
+		owned    []int64
+		tokenIDs []string
+		want     []Status
+		reasons  []ReasonCode
+		// collected the tokens owned by the destination after the run
+		collected []int64
+	}{
+		{name: "enumerated tokens", enumerable: true, owned: []int64{1, 2}, want: []Status{StatusSuccess, StatusSuccess},
+			reasons: []ReasonCode{"", ""}, collected: []int64{1, 2}},
+		{name: "listed tokens", owned: []int64{1}, tokenIDs: []string{"1", "5"}, want: []Status{StatusSuccess, StatusSkip},
+			reasons: []ReasonCode{"", ReasonNotTokenOwner}, collected: []int64{1}},
+		{name: "not enumerable", owned: []int64{1}, want: []Status{StatusFail},
+			reasons: []ReasonCode{ReasonTokenIDsRequired}},
+		{name: "no tokens", enumerable: true, want: []Status{StatusSkip}, reasons: []ReasonCode{ReasonZeroBalance}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			destination := newTestKeyProvider(t)
+			source := newTestKeyProvider(t)
+			chain := &nftChain{Chain: dobermanntest.NewChain(big.NewInt(1)), enumerable: test.enumerable,
+				owners: map[int64]common.Address{5: other}}
+			chain.SetBalance(*destination.GetAddress(), big.NewInt(1e18))
+			for _, id := range test.owned {
+				chain.owners[id] = *source.GetAddress()
+			}
+			collector, err := NewEVMCollector(EVMCollectorConfig{
+				Client:              chain,
+				GasTracker:          dobermanntest.NewGasTracker(30, 90),
+				NonceProviderType:   NonceProviderTypeNetwork,
+				ReceiptPollInterval: time.Millisecond,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer collector.Close()
+
+			results := collector.Collect(context.Background(), DestinationAccount{KeyProvider: destination},
+				[]SourceAccount{{KeyProvider: source, Token: testCollection, ERC721: true, TokenIDs: test.tokenIDs}})
+			// the result of each token after the first one is in the Tokens of the first
+			tokenResults := append([]Result{results[0]}, results[0].Tokens...)
+			if len(tokenResults) != len(test.want) {
+				t.Fatalf("%d token results, want %d", len(tokenResults), len(test.want))
+			}
+			for i, result := range tokenResults {
+				if result.Status != test.want[i] || result.Reason != test.reasons[i] {
+					t.Fatalf("token %d: %s %s, want %s %s", i, result.Status, result.Reason, test.want[i], test.reasons[i])
+				}
+			}
+			for _, id := range test.collected {
+				if chain.owner(id) != *destination.GetAddress() {
+					t.Fatalf("token %d not collected", id)
+				}
+			}
+			// the account is funded once for all its transfers
+			sent := len(chain.Sent())
+			if want := len(test.collected); want > 0 {
+				want++
+				if sent != want {
+					t.Fatalf("%d transactions sent, want %d", sent, want)
+				}
+			} else if sent != 0 {
+				t.Fatalf("%d transactions sent without tokens to collect", sent)
+			}
+			if chain.owner(5) != other {
+				t.Fatal("token of another account moved")
+			}
+		})
+	}
+}
+
+func TestValidateERC721(t *testing.T) {
+	wallet := common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	tests := []struct {
+		name    string
+		account SourceAccount
+		wantErr bool
+	}{
+		{name: "collection", account: SourceAccount{Token: testCollection, ERC721: true}},
+		{name: "listed tokens", account: SourceAccount{Token: testCollection, ERC721: true, TokenIDs: []string{"1", "2"}}},
+		{name: "no collection", account: SourceAccount{ERC721: true}, wantErr: true},
+		{name: "contract wallet", account: SourceAccount{Token: testCollection, ERC721: true, Wallet: &wallet}, wantErr: true},
+		{name: "further tokens", account: SourceAccount{Token: testCollection, ERC721: true, Tokens: []string{testToken}},
+			wantErr: true},
+		{name: "invalid token id", account: SourceAccount{Token: testCollection, ERC721: true, TokenIDs: []string{"-1"}},
+			wantErr: true},
+		{name: "token ids without ERC721", account: SourceAccount{Token: testCollection, TokenIDs: []string{"1"}},
+			wantErr: true},
+		{name: "ERC-20 token", account: SourceAccount{Token: testToken}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateERC721(test.account)
+			if (err != nil) != test.wantErr || (err != nil && !errors.Is(err, ErrInvalidERC721Account)) {
+				t.Fatalf("error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package dobermann_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann"
+	"github.com/welthee/dobermann/dobermanntest"
+	"github.com/welthee/dobermann/key/pk"
+)
+
+const (
+	sourcePrivateKeyHex      = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	destinationPrivateKeyHex = "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"
+	tokenAddress             = "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+)
+
+func ExampleNewEVMCollector() {
+	// the fakes of dobermanntest replace the node and the gas tracker, e.g. the BlockchainUrl and the GasTrackerUrl
+	collector, err := dobermann.NewEVMCollector(dobermann.EVMCollectorConfig{
+		Client:            dobermanntest.NewChain(big.NewInt(137)),
+		GasTracker:        dobermanntest.NewGasTracker(30, 90),
+		NonceProviderType: dobermann.NonceProviderTypeNetwork,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	defer collector.Close()
+
+	fmt.Println(collector.GetChainId(context.Background()))
+	// Output: 137
+}
+
+func ExampleCollector_Collect() {
+	chain := dobermanntest.NewChain(big.NewInt(137))
+	collector, err := dobermann.NewEVMCollector(dobermann.EVMCollectorConfig{
+		Client:              chain,
+		GasTracker:          dobermanntest.NewGasTracker(30, 90),
+		NonceProviderType:   dobermann.NonceProviderTypeNetwork,
+		ReceiptPollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	defer collector.Close()
+
+	ctx := context.Background()
+	chainId := collector.GetChainId(ctx)
+	sourceKey, err := pk.NewPrivateKeyProvider(sourcePrivateKeyHex, chainId)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	destinationKey, err := pk.NewPrivateKeyProvider(destinationPrivateKeyHex, chainId)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	token := common.HexToAddress(tokenAddress)
+	chain.SetTokenBalance(token, *sourceKey.GetAddress(), big.NewInt(1500000))
+	// the destination funds the gas of the source
+	chain.SetBalance(*destinationKey.GetAddress(), big.NewInt(1e18))
+
+	// an empty Amount collects the whole token balance
+	results := collector.Collect(ctx, dobermann.DestinationAccount{KeyProvider: destinationKey}, []dobermann.SourceAccount{
+		{KeyProvider: sourceKey, Token: tokenAddress},
+	})
+	for _, result := range results {
+		fmt.Println(result.Status, result.CollectedAmount)
+	}
+	fmt.Println(chain.TokenBalance(token, *destinationKey.GetAddress()))
+	// Output:
+	// success 1500000
+	// 1500000
+}
+
+// The plan of a collection is approved by its hash, then collected only if the chain state still matches it
+func ExampleCollector_CollectPlan() {
+	chain := dobermanntest.NewChain(big.NewInt(137))
+	collector, err := dobermann.NewEVMCollector(dobermann.EVMCollectorConfig{
+		Client:              chain,
+		GasTracker:          dobermanntest.NewGasTracker(30, 90),
+		NonceProviderType:   dobermann.NonceProviderTypeNetwork,
+		ReceiptPollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	defer collector.Close()
+
+	ctx := context.Background()
+	chainId := collector.GetChainId(ctx)
+	sourceKey, err := pk.NewPrivateKeyProvider(sourcePrivateKeyHex, chainId)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	destinationKey, err := pk.NewPrivateKeyProvider(destinationPrivateKeyHex, chainId)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	chain.SetTokenBalance(common.HexToAddress(tokenAddress), *sourceKey.GetAddress(), big.NewInt(1500000))
+	chain.SetBalance(*destinationKey.GetAddress(), big.NewInt(1e18))
+	destination := dobermann.DestinationAccount{KeyProvider: destinationKey}
+	accounts := []dobermann.SourceAccount{{KeyProvider: sourceKey, Token: tokenAddress}}
+
+	plan, err := collector.Plan(ctx, destination, accounts)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	hash, err := plan.Hash()
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+
+	// the plan and its hash are reviewed, then the approved plan is collected
+	results, err := collector.CollectPlan(ctx, destination, accounts, *plan, hash)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	fmt.Println(plan.Entries[0].Amount, results[0].Status)
+	// Output: 1500000 success
+}
+
+// The results are received as soon as each account completes, the channel being closed after the last one
+func ExampleCollector_CollectAsync() {
+	chain := dobermanntest.NewChain(big.NewInt(137))
+	collector, err := dobermann.NewEVMCollector(dobermann.EVMCollectorConfig{
+		Client:              chain,
+		GasTracker:          dobermanntest.NewGasTracker(30, 90),
+		NonceProviderType:   dobermann.NonceProviderTypeNetwork,
+		ReceiptPollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	defer collector.Close()
+
+	ctx := context.Background()
+	chainId := collector.GetChainId(ctx)
+	sourceKey, err := pk.NewPrivateKeyProvider(sourcePrivateKeyHex, chainId)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	destinationKey, err := pk.NewPrivateKeyProvider(destinationPrivateKeyHex, chainId)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	chain.SetTokenBalance(common.HexToAddress(tokenAddress), *sourceKey.GetAddress(), big.NewInt(1500000))
+	chain.SetBalance(*destinationKey.GetAddress(), big.NewInt(1e18))
+
+	// the source holds none of the second token
+	const otherToken = "0xc2132D05D31c914a87C6611C10748AEb04B58e8F"
+	chain.SetTokenBalance(common.HexToAddress(otherToken), *sourceKey.GetAddress(), big.NewInt(0))
+	results, err := collector.CollectAsync(ctx, dobermann.DestinationAccount{KeyProvider: destinationKey}, []dobermann.SourceAccount{
+		{KeyProvider: sourceKey, Token: tokenAddress},
+		{KeyProvider: sourceKey, Token: otherToken},
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	for result := range results {
+		fmt.Println(result.SourceAccount.Token, result.Status)
+	}
+	// Output:
+	// 0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174 success
+	// 0xc2132D05D31c914a87C6611C10748AEb04B58e8F skip
+}
+
+// Without the gas tracker the fees are taken from the node: the suggested tip and a fee cap of twice the base fee
+// plus the tip, unless the DisableNodeFeeFallback is set
+func ExampleEVMCollectorConfig_nodeFeeFallback() {
+	chain := dobermanntest.NewChain(big.NewInt(137))
+	collector, err := dobermann.NewEVMCollector(dobermann.EVMCollectorConfig{
+		Client:              chain,
+		GasTracker:          dobermanntest.NewUnavailableGasTracker(),
+		NonceProviderType:   dobermann.NonceProviderTypeNetwork,
+		ReceiptPollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	defer collector.Close()
+
+	ctx := context.Background()
+	chainId := collector.GetChainId(ctx)
+	sourceKey, err := pk.NewPrivateKeyProvider(sourcePrivateKeyHex, chainId)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	destinationKey, err := pk.NewPrivateKeyProvider(destinationPrivateKeyHex, chainId)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	chain.SetTokenBalance(common.HexToAddress(tokenAddress), *sourceKey.GetAddress(), big.NewInt(1500000))
+	chain.SetBalance(*destinationKey.GetAddress(), big.NewInt(1e18))
+
+	results := collector.Collect(ctx, dobermann.DestinationAccount{KeyProvider: destinationKey}, []dobermann.SourceAccount{
+		{KeyProvider: sourceKey, Token: tokenAddress},
+	})
+	fmt.Println(results[0].Status)
+	for _, tx := range chain.Sent() {
+		fmt.Println(dobermann.FormatUnits(tx.GasTipCap(), 9), dobermann.FormatUnits(tx.GasFeeCap(), 9))
+	}
+	// Output:
+	// success
+	// 2.0 62.0
+	// 2.0 62.0
+}
+
+func ExamplePlan_Hash() {
+	plan := dobermann.Plan{
+		Destination: common.HexToAddress("0x00000000000000000000000000000000000000d1"),
+		Entries: []dobermann.PlanEntry{{
+			Source: common.HexToAddress("0x00000000000000000000000000000000000000a1"),
+			Token:  tokenAddress,
+			Amount: "1500000",
+		}},
+		Fees: dobermann.PlanFees{GasTipCap: "30000000000", GasFeeCap: "60000000000"},
+	}
+	hash, err := plan.Hash()
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	// the tokens are compared ignoring their case
+	plan.Entries[0].Token = "0x2791bca1f2de4661ed88a30c99a7a9449aa84174"
+	same, err := plan.Hash()
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	fmt.Println(hash == same)
+	// Output: true
+}
+
+func ExampleNewRunReport() {
+	results := []dobermann.Result{
+		{Status: dobermann.StatusSuccess, CollectedAmount: "1500000"},
+		{Status: dobermann.StatusSkip, Reason: dobermann.ReasonZeroBalance},
+		{Status: dobermann.StatusSkip, Reason: dobermann.ReasonZeroBalance},
+	}
+	report := dobermann.NewRunReport(results)
+	data, err := json.Marshal(report.Summary)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	fmt.Println(string(data))
+	// Output: {"total":3,"statuses":{"skip":2,"success":1},"reasons":{"zero_balance":2}}
+}
+
+func ExampleParseUnits() {
+	amount, err := dobermann.ParseUnits("1.5", 6)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	fmt.Println(amount)
+
+	_, err = dobermann.ParseUnits("1.0000001", 6)
+	fmt.Println(err)
+	// Output:
+	// 1500000
+	// invalid units: "1.0000001" has more than 6 decimals
+}
+
+func ExampleFormatUnits() {
+	fmt.Println(dobermann.FormatUnits(big.NewInt(1500000), 6))
+	fmt.Println(dobermann.FormatUnits(big.NewInt(1), 6))
+	// Output:
+	// 1.5
+	// 0.000001
+}
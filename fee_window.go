@@ -0,0 +1,75 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/transactor"
+)
+
+const defaultFeeWindowPollInterval = time.Minute
+
+// FeeWindow gates the collection on the gas tracker standard max fee being at most MaxFee
+type FeeWindow struct {
+	// MaxFee the ceiling in wei, the gate is disabled when nil
+	MaxFee *big.Int
+	// Wait polls the fees until they drop below MaxFee or MaxWait elapses,
+	// instead of skipping all the accounts right away
+	Wait bool
+	// PollInterval between fee checks while waiting, one minute when zero, with a random jitter of up to 20%
+	PollInterval time.Duration
+	// MaxWait the longest time to wait for the fee window
+	MaxWait time.Duration
+	// RecheckEvery re-checks the gate every given number of accounts, zero checks only before starting
+	RecheckEvery int
+}
+
+// waitFeeWindow returns whether the current fees are within the fee window,
+// waiting for them to drop when configured
+func (c evmCollector) waitFeeWindow(ctx context.Context) bool {
+	window := c.feeWindow
+	pollInterval := window.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultFeeWindowPollInterval
+	}
+	deadline := c.clock.Now().Add(window.MaxWait)
+
+	for {
+		fee, err := c.currentMaxFee(ctx)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to get fees for the fee window")
+		} else if fee.Cmp(window.MaxFee) <= 0 {
+			log.Ctx(ctx).Info().
+				Str("maxFee", fee.String()).
+				Str("ceiling", window.MaxFee.String()).
+				Msg("fee window met")
+			return true
+		} else {
+			log.Ctx(ctx).Info().
+				Str("maxFee", fee.String()).
+				Str("ceiling", window.MaxFee.String()).
+				Msg("fee above fee window")
+		}
+
+		if !window.Wait || !c.clock.Now().Before(deadline) {
+			log.Ctx(ctx).Warn().Msg("fee window not met")
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-c.clock.After(c.jitter.apply(pollInterval)):
+		}
+	}
+}
+
+// currentMaxFee returns the standard max fee suggested by the gas tracker in wei
+func (c evmCollector) currentMaxFee(ctx context.Context) (*big.Int, error) {
+	response, err := c.gasTracker.GetSuggestedGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return transactor.GweiToWei(response.Standard.MaxFee)
+}
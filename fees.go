@@ -0,0 +1,158 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/welthee/dobermann/transactor"
+)
+
+// FeeQuote the fees suggested by the gas tracker, converted to wei
+type FeeQuote struct {
+	SafeLow  FeeTier `json:"safeLow"`
+	Standard FeeTier `json:"standard"`
+	Fast     FeeTier `json:"fast"`
+	// Speed the tier the fees of the collector are taken from
+	Speed transactor.FeeSpeed `json:"speed"`
+	// EstimatedBaseFee the base fee of the next block estimated by the gas tracker
+	EstimatedBaseFee Wei `json:"estimatedBaseFee"`
+	// Source the gas tracker url
+	Source string `json:"source"`
+	// BlockNumber the latest block known by the gas tracker when the quote was made
+	BlockNumber uint64 `json:"blockNumber"`
+	// StaleBlocks how many blocks the node head is ahead of BlockNumber
+	StaleBlocks uint64 `json:"staleBlocks"`
+	// Staleness StaleBlocks multiplied by the block time reported by the gas tracker
+	Staleness time.Duration `json:"staleness"`
+	// FetchedAt when the quote was fetched
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// FeeTier the tip (maxPriorityFeePerGas) and fee cap (maxFeePerGas) of a gas tracker tier in wei
+type FeeTier struct {
+	MaxPriorityFee Wei `json:"maxPriorityFee"`
+	MaxFee         Wei `json:"maxFee"`
+}
+
+// clone returns a copy of the quote not sharing its amounts
+func (q *FeeQuote) clone() *FeeQuote {
+	clone := *q
+	for _, tier := range []*FeeTier{&clone.SafeLow, &clone.Standard, &clone.Fast} {
+		tier.MaxPriorityFee = copyWei(tier.MaxPriorityFee)
+		tier.MaxFee = copyWei(tier.MaxFee)
+	}
+	clone.EstimatedBaseFee = copyWei(clone.EstimatedBaseFee)
+	return &clone
+}
+
+func copyWei(w Wei) Wei {
+	if w.Int == nil {
+		return w
+	}
+	return NewWei(new(big.Int).Set(w.Int))
+}
+
+// feeQuoteCache keeps the quotes returned by CurrentFees for their TTL, keyed by the fee speed of the collector.
+// It is shared by the copies of the collector.
+type feeQuoteCache struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	quotes map[transactor.FeeSpeed]*FeeQuote
+}
+
+func newFeeQuoteCache(ttl time.Duration) *feeQuoteCache {
+	if ttl == 0 {
+		ttl = defaultFeeQuoteTTL
+	}
+	return &feeQuoteCache{ttl: ttl, quotes: make(map[transactor.FeeSpeed]*FeeQuote)}
+}
+
+// get returns a copy of the quote of the speed fetched less than the TTL before now, nil when there is none
+func (c *feeQuoteCache) get(speed transactor.FeeSpeed, now time.Time) *FeeQuote {
+	if c == nil || c.ttl < 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	quote, ok := c.quotes[speed]
+	if !ok || now.Sub(quote.FetchedAt) >= c.ttl {
+		return nil
+	}
+	return quote.clone()
+}
+
+func (c *feeQuoteCache) set(speed transactor.FeeSpeed, quote *FeeQuote) {
+	if c == nil || c.ttl < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.quotes[speed] = quote.clone()
+}
+
+// newFeeTier converts the gwei fees of a gas tracker tier to wei
+func newFeeTier(maxPriorityFee float64, maxFee float64) (FeeTier, error) {
+	maxPriorityFeeWei, err := transactor.GweiToWei(maxPriorityFee)
+	if err != nil {
+		return FeeTier{}, err
+	}
+	maxFeeWei, err := transactor.GweiToWei(maxFee)
+	if err != nil {
+		return FeeTier{}, err
+	}
+	return FeeTier{MaxPriorityFee: NewWei(maxPriorityFeeWei), MaxFee: NewWei(maxFeeWei)}, nil
+}
+
+// CurrentFees returns the quote of the gas tracker, the same one for FeeQuoteTTL so that frequent callers, e.g. a
+// scheduler polling the fees, do not hit the gas tracker each time
+func (c evmCollector) CurrentFees(ctx context.Context) (*FeeQuote, error) {
+	if quote := c.feeQuotes.get(c.feeSpeed, c.clock.Now()); quote != nil {
+		return quote, nil
+	}
+
+	response, err := c.gasTracker.GetSuggestedGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &FeeQuote{
+		Speed:     c.feeSpeed,
+		Source:    c.gasTrackerUrl,
+		FetchedAt: c.clock.Now(),
+	}
+	tiers := []struct {
+		tier           *FeeTier
+		maxPriorityFee float64
+		maxFee         float64
+	}{
+		{&quote.SafeLow, response.SafeLow.MaxPriorityFee, response.SafeLow.MaxFee},
+		{&quote.Standard, response.Standard.MaxPriorityFee, response.Standard.MaxFee},
+		{&quote.Fast, response.Fast.MaxPriorityFee, response.Fast.MaxFee},
+	}
+	for _, t := range tiers {
+		if *t.tier, err = newFeeTier(t.maxPriorityFee, t.maxFee); err != nil {
+			return nil, err
+		}
+	}
+	estimatedBaseFee, err := transactor.GweiToWei(response.EstimatedBaseFee)
+	if err != nil {
+		return nil, err
+	}
+	quote.EstimatedBaseFee = NewWei(estimatedBaseFee)
+	if response.BlockNumber > 0 {
+		quote.BlockNumber = uint64(response.BlockNumber)
+	}
+
+	head, err := c.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head > quote.BlockNumber {
+		quote.StaleBlocks = head - quote.BlockNumber
+		quote.Staleness = time.Duration(quote.StaleBlocks) * time.Duration(response.BlockTime) * time.Second
+	}
+	c.feeQuotes.set(c.feeSpeed, quote)
+	return quote, nil
+}
@@ -0,0 +1,164 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/welthee/dobermann/client"
+	"github.com/welthee/dobermann/transactor"
+)
+
+// countingGasTracker returns the same response, counting the calls
+type countingGasTracker struct {
+	response transactor.GasTrackerResponse
+	calls    int
+}
+
+func (t *countingGasTracker) GetSuggestedGasPrice(ctx context.Context) (*transactor.GasTrackerResponse, error) {
+	t.calls++
+	response := t.response
+	return &response, nil
+}
+
+// headClient a node at the head block
+type headClient struct {
+	client.Client
+	head uint64
+}
+
+func (c headClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.head, nil
+}
+
+// manualClock a clock moved by the test
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func newTestGasTracker() *countingGasTracker {
+	tracker := &countingGasTracker{}
+	tracker.response.SafeLow.MaxPriorityFee = 30.000000012
+	tracker.response.SafeLow.MaxFee = 0.1 + 0.2
+	tracker.response.Standard.MaxPriorityFee = 31.5
+	tracker.response.Standard.MaxFee = 123456789.12345679
+	tracker.response.Fast.MaxPriorityFee = 0.0000000005
+	tracker.response.Fast.MaxFee = 40
+	tracker.response.EstimatedBaseFee = 28.123456789
+	tracker.response.BlockTime = 2
+	tracker.response.BlockNumber = 100
+	return tracker
+}
+
+func newTestFeesCollector(tracker transactor.GasTracker, clock Clock, ttl time.Duration) evmCollector {
+	return evmCollector{
+		gasTracker:    tracker,
+		gasTrackerUrl: "https://gasstation.example",
+		feeSpeed:      transactor.FeeSpeedSafeLow,
+		feeQuotes:     newFeeQuoteCache(ttl),
+		client:        headClient{head: 103},
+		clock:         clock,
+	}
+}
+
+func TestCurrentFeesTiers(t *testing.T) {
+	c := newTestFeesCollector(newTestGasTracker(), &manualClock{now: time.Unix(1700000000, 0)}, 0)
+	quote, err := c.CurrentFees(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  Wei
+		want string
+	}{
+		{"safe low tip", quote.SafeLow.MaxPriorityFee, "30000000012"},
+		{"safe low fee cap", quote.SafeLow.MaxFee, "300000000"},
+		{"standard tip", quote.Standard.MaxPriorityFee, "31500000000"},
+		{"standard fee cap", quote.Standard.MaxFee, "123456789123456790"},
+		{"fast tip", quote.Fast.MaxPriorityFee, "1"},
+		{"fast fee cap", quote.Fast.MaxFee, "40000000000"},
+		{"estimated base fee", quote.EstimatedBaseFee, "28123456789"},
+	}
+	for _, test := range tests {
+		if test.got.String() != test.want {
+			t.Errorf("%s %s wei, want %s", test.name, test.got, test.want)
+		}
+	}
+	if quote.Speed != transactor.FeeSpeedSafeLow || quote.BlockNumber != 100 || quote.StaleBlocks != 3 ||
+		quote.Staleness != 6*time.Second {
+		t.Fatalf("speed %s, block %d, stale blocks %d, staleness %s", quote.Speed, quote.BlockNumber, quote.StaleBlocks,
+			quote.Staleness)
+	}
+}
+
+func TestCurrentFeesCache(t *testing.T) {
+	tracker := newTestGasTracker()
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	c := newTestFeesCollector(tracker, clock, 10*time.Second)
+	ctx := context.Background()
+
+	first, err := c.CurrentFees(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the returned quote does not share its amounts with the cached one
+	first.SafeLow.MaxFee.Set(big.NewInt(1))
+
+	clock.now = clock.now.Add(9 * time.Second)
+	cached, err := c.CurrentFees(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tracker.calls != 1 {
+		t.Fatalf("%d gas tracker calls within the TTL, want 1", tracker.calls)
+	}
+	if cached.SafeLow.MaxFee.String() != "300000000" {
+		t.Fatalf("cached safe low fee cap %s, want 300000000", cached.SafeLow.MaxFee)
+	}
+
+	// the copies of the collector share the cache, keyed by their speed
+	fast := c
+	fast.feeSpeed = transactor.FeeSpeedFast
+	quote, err := fast.CurrentFees(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tracker.calls != 2 || quote.Speed != transactor.FeeSpeedFast {
+		t.Fatalf("%d gas tracker calls, speed %s, want 2, %s", tracker.calls, quote.Speed, transactor.FeeSpeedFast)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	if _, err = c.CurrentFees(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if tracker.calls != 3 {
+		t.Fatalf("%d gas tracker calls after the TTL, want 3", tracker.calls)
+	}
+}
+
+func TestCurrentFeesCacheDisabled(t *testing.T) {
+	tracker := newTestGasTracker()
+	c := newTestFeesCollector(tracker, &manualClock{now: time.Unix(1700000000, 0)}, -1)
+	for i := 0; i < 2; i++ {
+		if _, err := c.CurrentFees(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tracker.calls != 2 {
+		t.Fatalf("%d gas tracker calls, want 2", tracker.calls)
+	}
+}
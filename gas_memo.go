@@ -0,0 +1,61 @@
+package dobermann
+
+import (
+	"strings"
+	"sync"
+)
+
+const (
+	// gasMemoWarmup the transfers of a token which are always estimated before the estimates are reused
+	gasMemoWarmup = 2
+	// gasMemoBufferPercent is added to the largest estimate of a token when reusing it
+	gasMemoBufferPercent = 10
+)
+
+// gasMemo keeps the gas estimates of the collect transactions per token during a Collect call.
+// After the first transfers to the destination initialized its balance slot, the transfers of
+// the same token need about the same gas, so the largest estimate is reused for the rest.
+type gasMemo struct {
+	mu     sync.Mutex
+	tokens map[string]tokenGas
+}
+
+type tokenGas struct {
+	estimates int
+	max       uint64
+}
+
+func newGasMemo() *gasMemo {
+	return &gasMemo{tokens: make(map[string]tokenGas)}
+}
+
+// gasLimit returns the gas limit to reuse for the token, zero while the token has to be estimated
+func (m *gasMemo) gasLimit(token string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	gas := m.tokens[strings.ToLower(token)]
+	if gas.estimates < gasMemoWarmup {
+		return 0
+	}
+	return gas.max + gas.max*gasMemoBufferPercent/100
+}
+
+// record keeps the estimated gas limit of a transaction of the token
+func (m *gasMemo) record(token string, gasLimit uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := strings.ToLower(token)
+	gas := m.tokens[key]
+	gas.estimates++
+	if gasLimit > gas.max {
+		gas.max = gasLimit
+	}
+	m.tokens[key] = gas
+}
+
+// reset makes the next transfers of the token estimated again, e.g. after a transfer failed
+func (m *gasMemo) reset(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tokens, strings.ToLower(token))
+}
@@ -0,0 +1,196 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// groupMember an account of a group, prepared and funded together with the others,
+// or its final result when it failed before the sweep
+type groupMember struct {
+	col    *collection
+	result Result
+}
+
+// collectMember sweeps the member funded with its group
+func (c evmCollector) collectMember(ctx context.Context, b *batch, member groupMember) Result {
+	if member.col == nil {
+		return member.result
+	}
+	if member.col.funded {
+		if result, cancelled := c.cancelFunded(ctx, b, member.col); cancelled {
+			return result
+		}
+	} else if b.controller.Cancelled(*member.col.sourceAddress) {
+		return getResult(ctx, member.col.account, StatusCancelled, ReasonCancelled)
+	}
+	return c.sweep(ctx, b, member.col)
+}
+
+// groupGates the state of the collection loop at the first account of a group, which the other members are
+// checked against so that none is funded to be skipped or deferred by the loop afterwards
+type groupGates struct {
+	screening    *runScreening
+	quarantine   *quarantined
+	zeroBalances map[int]bool
+	controller   *RunController
+	// spent the cost of the accounts collected within the CostOrdering Budget, the first account included
+	spent *big.Int
+	// limit the number of scheduled accounts before the next fee window recheck, which may skip the following
+	// ones, no limit when 0
+	limit int
+}
+
+// groupOf returns the accounts of the group among the scheduled ones, the first being the account the loop is at,
+// which pass the gates of the collection loop: the validation, the cancellation, the quarantine, the zero balances,
+// the screening, the fee window rechecks and the CostOrdering Budget, the accounts in between spending it too.
+// The native accounts are left out as they pay their own fee, and so are the accounts of several tokens funded on
+// their own.
+func (c evmCollector) groupOf(ctx context.Context, gates groupGates, scheduled []scheduledAccount, groupKey string,
+	destinationAccount DestinationAccount) []scheduledAccount {
+	spent := new(big.Int).Set(gates.spent)
+	group := make([]scheduledAccount, 0)
+	for j, s := range scheduled {
+		if gates.limit > 0 && j >= gates.limit {
+			break
+		}
+		member := c.isGroupMember(s.account, groupKey)
+		if !member && c.costOrdering.Budget == nil {
+			continue
+		}
+		if !c.passesGates(ctx, gates, s, destinationAccount) {
+			continue
+		}
+		if j > 0 && c.costOrdering.Budget != nil && s.cost != nil {
+			if new(big.Int).Add(spent, s.cost).Cmp(c.costOrdering.Budget) > 0 {
+				continue
+			}
+			spent.Add(spent, s.cost)
+		}
+		if member {
+			group = append(group, s)
+		}
+	}
+	return group
+}
+
+// isGroupMember checks if the account can be funded with the group
+func (c evmCollector) isGroupMember(account SourceAccount, groupKey string) bool {
+	return account.GroupKey == groupKey && !isNative(account) && len(account.Tokens) == 0 && !account.ERC721 &&
+		!c.usesPermit(account)
+}
+
+// passesGates checks if the collection loop gets the account past its validation, cancellation, quarantine,
+// zero balance and screening checks
+func (c evmCollector) passesGates(ctx context.Context, gates groupGates, s scheduledAccount,
+	destinationAccount DestinationAccount) bool {
+	account := s.account
+	return validateKeyProvider(account.KeyProvider) == nil && c.validateSignerType(account.KeyProvider) == nil &&
+		validateAmount(account) == nil && !(isNative(account) && len(account.Tokens) > 0) &&
+		validateERC721(account) == nil &&
+		!isSelfCollection(account, destinationAccount) &&
+		!gates.controller.Cancelled(*account.KeyProvider.GetAddress()) &&
+		(gates.quarantine == nil || !gates.quarantine.isQuarantined(account, c.clock.Now())) &&
+		!gates.zeroBalances[s.index] &&
+		gates.screening.checkSource(ctx, account) == nil
+}
+
+// fundGroup prepares the accounts of the group and funds the ones needing it with transactions sent under
+// sequential nonces, waiting for all of them only once they were sent. The prepared members are added to
+// members by their index. A member whose funding fails gets its final result, while the members whose funding
+// was not sent, as it would follow a failed nonce, or reverted while RetryRevertedFunding is enabled, are left
+// out to be collected on their own.
+func (c evmCollector) fundGroup(ctx context.Context, b *batch, group []scheduledAccount, destinationAccount DestinationAccount, members map[int]groupMember) {
+	if len(group) < 2 {
+		return
+	}
+
+	funding := make([]scheduledAccount, 0)
+	collections := make(map[int]*collection)
+	for _, s := range group {
+		col, result := c.prepare(ctx, b, s.account, destinationAccount)
+		if col == nil {
+			members[s.index] = groupMember{result: result}
+			continue
+		}
+		collections[s.index] = col
+		if col.needsFunding() {
+			funding = append(funding, s)
+			continue
+		}
+		members[s.index] = groupMember{col: col}
+	}
+
+	if c.destinationFundsWait.Enabled && len(funding) > 0 {
+		required := new(big.Int)
+		for _, s := range funding {
+			required.Add(required, c.fundingCost(collections[s.index]))
+		}
+		funded, err := c.awaitDestinationFunds(ctx, b, *collections[funding[0].index].destinationAddress, required)
+		if err != nil || !funded {
+			// the members are collected on their own, each waiting for the funds it needs
+			return
+		}
+	}
+
+	sent := make([]*types.Transaction, 0, len(funding))
+	for _, s := range funding {
+		params := c.fundingParams(collections[s.index], destinationAccount)
+		// the nonces are allocated by sendFunding when the accounts are collected concurrently
+		if len(sent) > 0 && b.destinationNonce == nil {
+			params.Nonce = new(big.Int).SetUint64(sent[len(sent)-1].Nonce() + 1)
+		}
+		nativTx, phase, err := c.sendFunding(ctx, b, params)
+		if err != nil {
+			result := handleError(ctx, s.account, phase, err)
+			if nativTx != nil {
+				result.FundingTxHash = nativTx.Hash().Hex()
+			}
+			members[s.index] = groupMember{result: result}
+			break
+		}
+		sent = append(sent, nativTx)
+	}
+	log.Ctx(ctx).Debug().
+		Str("group", group[0].account.GroupKey).
+		Int("members", len(group)).
+		Int("funded", len(sent)).
+		Msg("group funding sent")
+
+	for i, nativTx := range sent {
+		s := funding[i]
+		phase, err := c.waitFunding(ctx, nativTx)
+		switch {
+		case err == nil:
+			col := collections[s.index]
+			col.funded = true
+			col.fundingTxHash = nativTx.Hash()
+			members[s.index] = groupMember{col: col}
+		case errors.Is(err, ErrFundingReverted) && c.retryRevertedFunding:
+			// collected on its own, which retries the funding with bumped fees
+		default:
+			result := handleError(ctx, s.account, phase, err)
+			result.FundingTxHash = nativTx.Hash().Hex()
+			members[s.index] = groupMember{result: result}
+		}
+	}
+}
+
+// waitFunding waits for the funding transaction to be mined, returning the phase in which it failed
+func (c evmCollector) waitFunding(ctx context.Context, nativTx *types.Transaction) (Phase, error) {
+	timeoutCtx, cancelFunc := context.WithTimeout(ctx, c.confirmationTimeout)
+	defer cancelFunc()
+	receipt, err := c.transactor.VerifyTx(timeoutCtx, nativTx.Hash().Hex())
+	if err != nil {
+		return PhaseFundingWait, err
+	}
+	if !succeeded(receipt) {
+		return PhaseFundingWait, fmt.Errorf("%w: %s", ErrFundingReverted, nativTx.Hash().Hex())
+	}
+	return "", nil
+}
@@ -0,0 +1,143 @@
+package dobermann
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/welthee/dobermann/key"
+	"github.com/welthee/dobermann/key/pk"
+)
+
+const testToken = "0x00000000000000000000000000000000000000aa"
+
+// newTestKeyProvider returns the key provider of a random private key
+func newTestKeyProvider(t *testing.T) key.Provider {
+	t.Helper()
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err := pk.NewPrivateKeyProvider(hex.EncodeToString(crypto.FromECDSA(privateKey)), big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+// scheduleTestGroup returns accounts of the group g, each costing 10 wei
+func scheduleTestGroup(t *testing.T, n int) []scheduledAccount {
+	scheduled := make([]scheduledAccount, n)
+	for i := range scheduled {
+		scheduled[i] = scheduledAccount{
+			index:   i,
+			account: SourceAccount{KeyProvider: newTestKeyProvider(t), Token: testToken, GroupKey: "g"},
+			cost:    big.NewInt(10),
+		}
+	}
+	return scheduled
+}
+
+func groupIndexes(group []scheduledAccount) []int {
+	indexes := make([]int, len(group))
+	for i, s := range group {
+		indexes[i] = s.index
+	}
+	return indexes
+}
+
+func TestGroupOfGates(t *testing.T) {
+	destinationAccount := DestinationAccount{KeyProvider: newTestKeyProvider(t)}
+	tests := []struct {
+		name      string
+		budget    *big.Int
+		gates     func(scheduled []scheduledAccount) groupGates
+		scheduled func(scheduled []scheduledAccount)
+		want      []int
+	}{
+		{
+			name: "all members",
+			gates: func([]scheduledAccount) groupGates {
+				return groupGates{spent: big.NewInt(10)}
+			},
+			want: []int{0, 1, 2, 3},
+		},
+		{
+			name:   "member over budget",
+			budget: big.NewInt(25),
+			gates: func([]scheduledAccount) groupGates {
+				return groupGates{spent: big.NewInt(10)}
+			},
+			want: []int{0, 1},
+		},
+		{
+			name:   "budget spent by a non member",
+			budget: big.NewInt(30),
+			gates: func([]scheduledAccount) groupGates {
+				return groupGates{spent: big.NewInt(10)}
+			},
+			scheduled: func(scheduled []scheduledAccount) {
+				scheduled[1].account.GroupKey = "other"
+			},
+			want: []int{0, 2},
+		},
+		{
+			name: "zero balance",
+			gates: func([]scheduledAccount) groupGates {
+				return groupGates{spent: big.NewInt(10), zeroBalances: map[int]bool{2: true}}
+			},
+			want: []int{0, 1, 3},
+		},
+		{
+			name: "cancelled",
+			gates: func(scheduled []scheduledAccount) groupGates {
+				controller := NewRunController()
+				controller.Cancel(*scheduled[3].account.KeyProvider.GetAddress())
+				return groupGates{spent: big.NewInt(10), controller: controller}
+			},
+			want: []int{0, 1, 2},
+		},
+		{
+			name: "quarantined",
+			gates: func(scheduled []scheduledAccount) groupGates {
+				quarantine := &quarantined{threshold: 1, entries: []QuarantineEntry{{
+					Address:  addressHex(scheduled[1].account),
+					Token:    testToken,
+					Class:    QuarantineClassTokenRejected,
+					Failures: 1,
+				}}}
+				return groupGates{spent: big.NewInt(10), quarantine: quarantine}
+			},
+			want: []int{0, 2, 3},
+		},
+		{
+			name: "fee window recheck",
+			gates: func([]scheduledAccount) groupGates {
+				return groupGates{spent: big.NewInt(10), limit: 2}
+			},
+			want: []int{0, 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := evmCollector{signerType: key.SignerTypeLondon, costOrdering: CostOrdering{Budget: test.budget},
+				clock: realClock{}}
+			scheduled := scheduleTestGroup(t, 4)
+			if test.scheduled != nil {
+				test.scheduled(scheduled)
+			}
+			group := c.groupOf(context.Background(), test.gates(scheduled), scheduled, "g", destinationAccount)
+			got := groupIndexes(group)
+			if len(got) != len(test.want) {
+				t.Fatalf("group %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Fatalf("group %v, want %v", got, test.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package dobermann
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/welthee/dobermann/internal/redact"
+	"github.com/welthee/dobermann/key"
+	"github.com/welthee/dobermann/retry"
+	"github.com/welthee/dobermann/transactor"
+)
+
+// CollectorInfo the effective configuration of a collector, after defaulting and validation. The endpoints are
+// redacted of their credentials, so that it can be logged or shared.
+type CollectorInfo struct {
+	ChainID             string            `json:"chainId"`
+	Endpoints           []string          `json:"endpoints"`
+	BroadcastEndpoints  []string          `json:"broadcastEndpoints,omitempty"`
+	BroadcastTimeout    string            `json:"broadcastTimeout,omitempty"`
+	GasTracker          string            `json:"gasTracker"`
+	NonceProviderType   NonceProviderType `json:"nonceProviderType"`
+	SignerType          key.SignerType    `json:"signerType"`
+	TxType              string            `json:"txType"`
+	CollectStrategy     CollectStrategy   `json:"collectStrategy"`
+	TransferWaitTimeout string            `json:"transferWaitTimeout"`
+	// MaxConcurrentCollections the accounts collected at the same time
+	MaxConcurrentCollections int `json:"maxConcurrentCollections"`
+	// Confirmations the blocks a collection waits for behind its block
+	Confirmations uint64      `json:"confirmations,omitempty"`
+	Fees          FeeInfo     `json:"fees"`
+	Funding       FundingInfo `json:"funding"`
+	// Features the optional behaviours which are enabled
+	Features            []string            `json:"features,omitempty"`
+	LedgerFailurePolicy LedgerFailurePolicy `json:"ledgerFailurePolicy,omitempty"`
+	Retry               map[string]int      `json:"retryAttempts,omitempty"`
+}
+
+// FeeInfo the fee policy of a collector
+type FeeInfo struct {
+	Speed               transactor.FeeSpeed `json:"speed"`
+	MaxFeeCapMultiplier float64             `json:"maxFeeCapMultiplier"`
+	GasLimitMultiplier  float64             `json:"gasLimitMultiplier"`
+	GasTipCap           *Wei                `json:"gasTipCap,omitempty"`
+	MaxGasFeeCap        *Wei                `json:"maxGasFeeCap,omitempty"`
+	FeeWindowMaxFee     *Wei                `json:"feeWindowMaxFee,omitempty"`
+	FeeWindowMaxWait    string              `json:"feeWindowMaxWait,omitempty"`
+	CostOrderingBudget  *Wei                `json:"costOrderingBudget,omitempty"`
+}
+
+// FundingInfo the funding policy of a collector
+type FundingInfo struct {
+	Buffer        *Wei   `json:"buffer,omitempty"`
+	MinimumAmount *Wei   `json:"minimumAmount,omitempty"`
+	TxTag         string `json:"txTag,omitempty"`
+}
+
+// Info returns the effective configuration of the collector, with the current chain ID
+func (c evmCollector) Info() CollectorInfo {
+	info := c.info
+	info.ChainID = c.chainId.get().String()
+	return info
+}
+
+// newCollectorInfo describes the configuration with the defaults the collector applies
+func newCollectorInfo(config EVMCollectorConfig, nonceProviderType NonceProviderType, signerType key.SignerType, feeSpeed transactor.FeeSpeed,
+	gasTipCap *big.Int, maxGasFeeCap *big.Int, confirmationTimeout time.Duration) CollectorInfo {
+	endpoints := make([]string, 0, len(config.BlockchainUrls)+1)
+	if config.BlockchainUrl != "" {
+		endpoints = append(endpoints, config.BlockchainUrl)
+	}
+	endpoints = append(endpoints, config.BlockchainUrls...)
+	// the given client is not dialed from the endpoints
+	if config.Client != nil {
+		endpoints = endpoints[:0]
+	}
+
+	info := CollectorInfo{
+		Endpoints:                redact.URLs(endpoints),
+		GasTracker:               redact.URL(config.GasTrackerUrl),
+		NonceProviderType:        nonceProviderType,
+		SignerType:               signerType,
+		TxType:                   "dynamic_fee",
+		CollectStrategy:          CollectStrategyTransfer,
+		TransferWaitTimeout:      confirmationTimeout.String(),
+		MaxConcurrentCollections: 1,
+		Confirmations:            config.Confirmations,
+		LedgerFailurePolicy:      config.LedgerFailurePolicy,
+		Fees: FeeInfo{
+			Speed:               feeSpeed,
+			MaxFeeCapMultiplier: 1,
+			GasLimitMultiplier:  1,
+			GasTipCap:           weiPointer(gasTipCap),
+			MaxGasFeeCap:        weiPointer(maxGasFeeCap),
+			FeeWindowMaxFee:     weiPointer(config.FeeWindow.MaxFee),
+			CostOrderingBudget:  weiPointer(config.CostOrdering.Budget),
+		},
+		Funding: FundingInfo{
+			Buffer:        weiPointer(config.FundingBuffer),
+			MinimumAmount: weiPointer(config.MinimumFundingAmount),
+		},
+	}
+	if len(config.BroadcastUrls) > 0 {
+		info.BroadcastEndpoints = redact.URLs(config.BroadcastUrls)
+		info.BroadcastTimeout = config.BroadcastTimeout.String()
+	}
+	if signerType == key.SignerTypeEIP155 {
+		info.TxType = "legacy"
+	}
+	if config.CollectStrategy != "" {
+		info.CollectStrategy = config.CollectStrategy
+	}
+	if config.MaxConcurrentCollections > 1 {
+		info.MaxConcurrentCollections = config.MaxConcurrentCollections
+	}
+	if config.MaxFeeCapMultiplier != 0 {
+		info.Fees.MaxFeeCapMultiplier = config.MaxFeeCapMultiplier
+	}
+	if config.GasLimitMultiplier != 0 {
+		info.Fees.GasLimitMultiplier = config.GasLimitMultiplier
+	}
+	if config.FeeWindow.MaxFee != nil && config.FeeWindow.Wait {
+		info.Fees.FeeWindowMaxWait = config.FeeWindow.MaxWait.String()
+	}
+	if len(config.FundingTxTag) > 0 {
+		info.Funding.TxTag = string(config.FundingTxTag)
+	}
+	if info.LedgerFailurePolicy == "" && config.Ledger != nil {
+		info.LedgerFailurePolicy = LedgerFailurePolicyContinue
+	}
+
+	features := []struct {
+		name    string
+		enabled bool
+	}{
+		{"detectPausedTokens", config.DetectPausedTokens},
+		{"reclaimNative", config.ReclaimNative},
+		{"retryRevertedFunding", config.RetryRevertedFunding},
+		{"replaceChanged", config.ReplaceChangedTransfers},
+		{"gasMemoization", !config.DisableGasMemoization},
+		{"nodeFeeFallback", !config.DisableNodeFeeFallback},
+		{"costOrdering", config.CostOrdering.Enabled},
+		{"planDriftCheck", config.DriftPolicy.BeforeCollect},
+		{"destinationFundsWait", config.DestinationFundsWait.Enabled},
+		{"quarantine", config.Quarantine.Store != nil},
+		{"ledger", config.Ledger != nil},
+		{"afterCollect", config.AfterCollect != nil},
+		{"preBroadcast", config.PreBroadcast != nil},
+		{"dryRun", config.DryRun},
+		{"bundle", config.Bundle.Submitter != nil},
+		{"multicall", config.Multicall3Address != nil},
+		{"chainStallWatchdog", config.ChainStall.Threshold > 0},
+		{"screening", config.Screening.Screener != nil},
+		{"sourceBacklog", config.SourceBacklogPolicy != SourceBacklogIgnore},
+	}
+	for _, feature := range features {
+		if feature.enabled {
+			info.Features = append(info.Features, feature.name)
+		}
+	}
+
+	for _, component := range []retry.Component{retry.ComponentRPC, retry.ComponentGasTracker} {
+		attempts := config.Retry.For(component).MaxAttempts
+		if attempts > 1 {
+			if info.Retry == nil {
+				info.Retry = make(map[string]int)
+			}
+			info.Retry[string(component)] = attempts
+		}
+	}
+	return info
+}
+
+// weiPointer returns the value as Wei, nil when it is not set
+func weiPointer(value *big.Int) *Wei {
+	if value == nil {
+		return nil
+	}
+	return &Wei{Int: value}
+}
@@ -0,0 +1,38 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxBodySize the largest response body read when no limit is configured
+const DefaultMaxBodySize int64 = 1 << 20
+
+// ErrBodyTooLarge the response body is larger than the configured limit
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// ReadBody reads the whole body, failing with ErrBodyTooLarge instead of reading more than
+// limit bytes, DefaultMaxBodySize when limit is not positive. The body is always closed and
+// the close error is returned when the read succeeded.
+func ReadBody(body io.ReadCloser, limit int64) (data []byte, err error) {
+	defer func() {
+		closeErr := body.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+	// one more byte than the limit tells a body of exactly limit bytes from a larger one
+	data, err = io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,82 @@
+// Package redact removes the credentials from values before they are logged or reported
+package redact
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces the removed credentials
+const Redacted = "REDACTED"
+
+// minKeyLength the shortest path segment considered an API key, e.g. the Infura project id
+const minKeyLength = 20
+
+// URL returns the URL without its credentials: the user info, the query values and the path segments which
+// look like API keys, e.g. https://polygon-mainnet.infura.io/v3/<key>. Values which can not be parsed are
+// entirely redacted.
+func URL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return Redacted
+	}
+	if parsed.User != nil {
+		parsed.User = url.User(Redacted)
+	}
+	if parsed.RawQuery != "" {
+		query := parsed.Query()
+		for name := range query {
+			query.Set(name, Redacted)
+		}
+		parsed.RawQuery = query.Encode()
+	}
+	segments := strings.Split(parsed.Path, "/")
+	for i, segment := range segments {
+		if isKeyLike(segment) {
+			segments[i] = Redacted
+		}
+	}
+	parsed.Path = strings.Join(segments, "/")
+	parsed.RawPath = ""
+	return parsed.String()
+}
+
+// URLs redacts every URL
+func URLs(raws []string) []string {
+	redacted := make([]string, 0, len(raws))
+	for _, raw := range raws {
+		redacted = append(redacted, URL(raw))
+	}
+	return redacted
+}
+
+// isKeyLike reports whether the path segment is long and only made of letters, digits, dashes and underscores
+func isKeyLike(segment string) bool {
+	if len(segment) < minKeyLength {
+		return false
+	}
+	hasDigit := false
+	for _, r := range segment {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return hasDigit
+}
+
+// Error redacts the URL of the request errors of net/http, which otherwise end up in the logs with their credentials
+func Error(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = URL(urlErr.URL)
+	}
+	return err
+}
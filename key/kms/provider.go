@@ -12,6 +12,7 @@ import (
 type kmsKeyProvider struct {
 	TransactOpts *bind.TransactOpts
 	Address      *common.Address
+	signerType   key.SignerType
 }
 
 func (k kmsKeyProvider) GetAddress() *common.Address {
@@ -22,9 +23,23 @@ func (k kmsKeyProvider) GetTransactOpts() *bind.TransactOpts {
 	return k.TransactOpts
 }
 
+func (k kmsKeyProvider) SignerType() key.SignerType {
+	return k.signerType
+}
+
 // NewKmsKeyProvider is a utility method to easily create a transaction signer
 // using a KMS key for the given chainID.
 func NewKmsKeyProvider(svc *kms.Client, keyId string, chainId *big.Int) (key.Provider, error) {
+	return NewKmsKeyProviderWithSigner(svc, keyId, chainId, key.SignerTypeLondon)
+}
+
+// NewKmsKeyProviderWithSigner is NewKmsKeyProvider for the given signer type. The KMS signer protects
+// legacy transactions with EIP-155, so its signatures are valid for both signer types.
+func NewKmsKeyProviderWithSigner(svc *kms.Client, keyId string, chainId *big.Int, signerType key.SignerType) (key.Provider, error) {
+	_, err := key.NewSigner(signerType, chainId)
+	if err != nil {
+		return nil, err
+	}
 	txOpts, err := ethawskmssigner.NewAwsKmsTransactorWithChainID(svc, keyId, chainId)
 	if err != nil {
 		return nil, err
@@ -32,5 +47,6 @@ func NewKmsKeyProvider(svc *kms.Client, keyId string, chainId *big.Int) (key.Pro
 	return kmsKeyProvider{
 		TransactOpts: txOpts,
 		Address:      &txOpts.From,
+		signerType:   signerType,
 	}, nil
 }
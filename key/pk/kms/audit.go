@@ -0,0 +1,150 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/welthee/dobermann/retry"
+)
+
+const defaultAuditConcurrency = 4
+
+// defaultAuditRetry retries the throttled decryptions 3 times
+var defaultAuditRetry = retry.Policy{
+	MaxAttempts:  4,
+	InitialDelay: 500 * time.Millisecond,
+	Jitter:       0.2,
+}
+
+// AuditStatus the outcome of the audit of a key
+type AuditStatus string
+
+const (
+	// AuditStatusMatch the key decrypts to the expected address
+	AuditStatusMatch AuditStatus = "match"
+	// AuditStatusMismatch the key decrypts to another address than the expected one
+	AuditStatusMismatch AuditStatus = "mismatch"
+	// AuditStatusDecryptFailed the key could not be decrypted or is not a valid private key
+	AuditStatusDecryptFailed AuditStatus = "decrypt_failed"
+)
+
+// KeySpec an encrypted key of an inventory with the address it is expected to belong to
+type KeySpec struct {
+	// ID identifies the key in the inventory, e.g. a database row id
+	ID           string         `json:"id"`
+	Address      common.Address `json:"address"`
+	EncryptedKey string         `json:"encryptedKey"`
+}
+
+// AuditResult the outcome of the audit of a KeySpec, it never contains key material
+type AuditResult struct {
+	ID       string          `json:"id"`
+	Expected common.Address  `json:"expected"`
+	Derived  *common.Address `json:"derived,omitempty"`
+	Status   AuditStatus     `json:"status"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// AuditOptions the limits of AuditKeys
+type AuditOptions struct {
+	// Concurrency the most keys decrypted at the same time, 4 when zero
+	Concurrency int
+	// Retry the policy of the decryptions, by default the ones throttled by KMS are retried 3 times with an
+	// exponential backoff. Only the throttled decryptions are retried when the policy has no classifier
+	Retry retry.Policy
+}
+
+// AuditKeys decrypts every key of the inventory and checks that it belongs to the expected address, without
+// any blockchain access. The results are in the order of the specs, and the decrypted keys are zeroed as soon
+// as their address is derived.
+func AuditKeys(ctx context.Context, decrypter Decrypter, specs []KeySpec, options AuditOptions) []AuditResult {
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultAuditConcurrency
+	}
+	policy := options.Retry
+	if policy.MaxAttempts == 0 {
+		policy = defaultAuditRetry
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = isThrottled
+	}
+	decrypter = retryingDecrypter{decrypter: decrypter, policy: policy}
+
+	results := make([]AuditResult, len(specs))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, spec KeySpec) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[i] = auditKey(ctx, decrypter, spec)
+		}(i, spec)
+	}
+	wg.Wait()
+	return results
+}
+
+func auditKey(ctx context.Context, decrypter Decrypter, spec KeySpec) AuditResult {
+	result := AuditResult{
+		ID:       spec.ID,
+		Expected: spec.Address,
+	}
+
+	privateKey, err := decryptPrivateKey(ctx, decrypter, spec.EncryptedKey)
+	if err != nil {
+		result.Status = AuditStatusDecryptFailed
+		result.Error = err.Error()
+		return result
+	}
+	derived := crypto.PubkeyToAddress(privateKey.PublicKey)
+	zeroKey(privateKey)
+
+	result.Derived = &derived
+	result.Status = AuditStatusMatch
+	if derived != spec.Address {
+		result.Status = AuditStatusMismatch
+	}
+	return result
+}
+
+// retryingDecrypter retries the decryptions with the policy
+type retryingDecrypter struct {
+	decrypter Decrypter
+	policy    retry.Policy
+}
+
+func (r retryingDecrypter) Decrypt(ctx context.Context, data string) (string, error) {
+	var plaintext string
+	err := retry.Do(ctx, r.policy, func(ctx context.Context) error {
+		var err error
+		plaintext, err = r.decrypter.Decrypt(ctx, data)
+		return err
+	})
+	return plaintext, err
+}
+
+func (r retryingDecrypter) Encrypt(ctx context.Context, data string) (string, error) {
+	return r.decrypter.Encrypt(ctx, data)
+}
+
+// isThrottled reports whether KMS rejected the request because of its rate limits
+func isThrottled(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "ThrottlingException", "TooManyRequestsException", "LimitExceededException":
+		return true
+	default:
+		return false
+	}
+}
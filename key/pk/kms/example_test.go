@@ -0,0 +1,30 @@
+package kms_test
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/dobermanntest"
+	"github.com/welthee/dobermann/key/pk/kms"
+)
+
+func ExampleNewKmsEncryptedPrivateKeyProvider() {
+	// the fake KMS client decrypts the keys encrypted by EncryptKey, instead of the kms.NewFromConfig client of AWS
+	kmsClient := dobermanntest.NewKMSClient()
+	const kmsKeyId = "alias/dobermann"
+	encryptedKey := dobermanntest.EncryptKey(kmsKeyId, "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+
+	provider, err := kms.NewKmsEncryptedPrivateKeyProvider(kmsClient, kmsKeyId, encryptedKey, big.NewInt(137))
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	fmt.Println(provider.GetAddress())
+
+	// a key encrypted with another KMS key is not decrypted
+	_, err = kms.NewKmsEncryptedPrivateKeyProvider(kmsClient, "alias/other", encryptedKey, big.NewInt(137))
+	fmt.Println(err != nil)
+	// Output:
+	// 0x2c7536E3605D9C16a7a3D7b1898e529396a65c23
+	// true
+}
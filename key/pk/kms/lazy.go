@@ -0,0 +1,171 @@
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/welthee/dobermann/key"
+)
+
+// ErrAddressMismatch the decrypted private key does not belong to the address given to the provider
+var ErrAddressMismatch = errors.New("decrypted key address mismatch")
+
+type lazyKmsEncryptedPrivateKeyProvider struct {
+	decrypter    Decrypter
+	encryptedKey string
+	address      common.Address
+	signer       types.Signer
+	signerType   key.SignerType
+
+	mu         sync.Mutex
+	privateKey *ecdsa.PrivateKey
+}
+
+// NewLazyKmsEncryptedPrivateKeyProvider is NewKmsEncryptedPrivateKeyProvider deferring the decryption until
+// the first transaction is signed, within the context and deadline of the collected account. The private key
+// is kept until the collector releases the provider once the account is done, then it is zeroed and decrypted
+// again by the next signature. As the key is not decrypted upfront, the address of the key has to be given.
+func NewLazyKmsEncryptedPrivateKeyProvider(svc *kms.Client, kmsKeyId string, encryptedKey string, address common.Address, chainId *big.Int) (key.Provider, error) {
+	return NewLazyKmsEncryptedPrivateKeyProviderWithSigner(svc, kmsKeyId, encryptedKey, address, chainId, key.SignerTypeLondon)
+}
+
+// NewLazyKmsEncryptedPrivateKeyProviderWithSigner is NewLazyKmsEncryptedPrivateKeyProvider signing with the given
+// signer type, e.g. key.SignerTypeEIP155 for chains which only accept legacy transactions.
+func NewLazyKmsEncryptedPrivateKeyProviderWithSigner(svc *kms.Client, kmsKeyId string, encryptedKey string, address common.Address,
+	chainId *big.Int, signerType key.SignerType) (key.Provider, error) {
+	provider, err := newLazyProvider(NewKmsDecrypter(svc, kmsKeyId), encryptedKey, address, chainId, signerType)
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+func newLazyProvider(decrypter Decrypter, encryptedKey string, address common.Address, chainId *big.Int,
+	signerType key.SignerType) (*lazyKmsEncryptedPrivateKeyProvider, error) {
+	if signerType == "" {
+		signerType = key.SignerTypeLondon
+	}
+	signer, err := key.NewSigner(signerType, chainId)
+	if err != nil {
+		return nil, err
+	}
+	return &lazyKmsEncryptedPrivateKeyProvider{
+		decrypter:    decrypter,
+		encryptedKey: encryptedKey,
+		address:      address,
+		signer:       signer,
+		signerType:   signerType,
+	}, nil
+}
+
+func (p *lazyKmsEncryptedPrivateKeyProvider) GetAddress() *common.Address {
+	return &p.address
+}
+
+// GetTransactOpts returns options whose signer decrypts the key without a deadline,
+// the collector signs with SignTx instead
+func (p *lazyKmsEncryptedPrivateKeyProvider) GetTransactOpts() *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: p.address,
+		Signer: func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if from != p.address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return p.SignTx(context.Background(), tx)
+		},
+	}
+}
+
+func (p *lazyKmsEncryptedPrivateKeyProvider) SignerType() key.SignerType {
+	return p.signerType
+}
+
+func (p *lazyKmsEncryptedPrivateKeyProvider) SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	privateKey, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return types.SignTx(tx, p.signer, privateKey)
+}
+
+func (p *lazyKmsEncryptedPrivateKeyProvider) SignHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	privateKey, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(hash[:], privateKey)
+}
+
+// load returns the private key, decrypting it when it was not yet or was released, the caller holds mu
+func (p *lazyKmsEncryptedPrivateKeyProvider) load(ctx context.Context) (*ecdsa.PrivateKey, error) {
+	if p.privateKey == nil {
+		privateKey, err := p.decrypt(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p.privateKey = privateKey
+	}
+	return p.privateKey, nil
+}
+
+// Release zeroes the decrypted private key
+func (p *lazyKmsEncryptedPrivateKeyProvider) Release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.privateKey == nil {
+		return
+	}
+	zeroKey(p.privateKey)
+	p.privateKey = nil
+}
+
+func (p *lazyKmsEncryptedPrivateKeyProvider) decrypt(ctx context.Context) (*ecdsa.PrivateKey, error) {
+	privateKey, err := decryptPrivateKey(ctx, p.decrypter, p.encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	if address != p.address {
+		zeroKey(privateKey)
+		return nil, fmt.Errorf("%w: %s, expected %s", ErrAddressMismatch, address.Hex(), p.address.Hex())
+	}
+	return privateKey, nil
+}
+
+// decryptPrivateKey decrypts the private key hex, the errors never contain key material
+func decryptPrivateKey(ctx context.Context, decrypter Decrypter, encryptedKey string) (*ecdsa.PrivateKey, error) {
+	privateKeyHex, err := decrypter.Decrypt(ctx, encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		return nil, errors.New("decrypted key is not a valid private key")
+	}
+	return privateKey, nil
+}
+
+// zeroKey overwrites the private scalar of the key
+func zeroKey(privateKey *ecdsa.PrivateKey) {
+	words := privateKey.D.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	privateKey.D.SetInt64(0)
+}
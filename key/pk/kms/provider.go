@@ -11,10 +11,19 @@ import (
 // NewKmsEncryptedPrivateKeyProvider is a utility method to easily create a transaction signer
 // from a kms encrypted private key for the given chainID.
 func NewKmsEncryptedPrivateKeyProvider(svc *kms.Client, kmsKeyId string, encryptedKey string, chainId *big.Int) (key.Provider, error) {
-	decrypter := NewKmsDecrypter(svc, kmsKeyId)
+	return NewKmsEncryptedPrivateKeyProviderWithSigner(svc, kmsKeyId, encryptedKey, chainId, key.SignerTypeLondon)
+}
+
+// NewKmsEncryptedPrivateKeyProviderWithSigner is NewKmsEncryptedPrivateKeyProvider signing with the given
+// signer type, e.g. key.SignerTypeEIP155 for chains which only accept legacy transactions.
+func NewKmsEncryptedPrivateKeyProviderWithSigner(svc *kms.Client, kmsKeyId string, encryptedKey string, chainId *big.Int, signerType key.SignerType) (key.Provider, error) {
+	return newEncryptedPrivateKeyProvider(NewKmsDecrypter(svc, kmsKeyId), encryptedKey, chainId, signerType)
+}
+
+func newEncryptedPrivateKeyProvider(decrypter Decrypter, encryptedKey string, chainId *big.Int, signerType key.SignerType) (key.Provider, error) {
 	privateKeyHex, err := decrypter.Decrypt(context.TODO(), encryptedKey)
 	if err != nil {
 		return nil, err
 	}
-	return pk.NewPrivateKeyProvider(privateKeyHex, chainId)
+	return pk.NewPrivateKeyProviderWithSigner(privateKeyHex, chainId, signerType)
 }
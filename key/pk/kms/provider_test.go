@@ -0,0 +1,104 @@
+package kms
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/welthee/dobermann/key"
+)
+
+// plainDecrypter returns the data as is, the encrypted keys of the tests being plain hex
+type plainDecrypter struct{}
+
+func (plainDecrypter) Decrypt(ctx context.Context, data string) (string, error) {
+	return data, nil
+}
+
+func (plainDecrypter) Encrypt(ctx context.Context, data string) (string, error) {
+	return data, nil
+}
+
+func newTestKey(t *testing.T) (string, common.Address) {
+	t.Helper()
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(crypto.FromECDSA(privateKey)), crypto.PubkeyToAddress(privateKey.PublicKey)
+}
+
+func newTestTx(legacy bool) *types.Transaction {
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	if legacy {
+		return types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(1), Gas: 21000, To: &to})
+	}
+	return types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1),
+		Gas: 21000, To: &to})
+}
+
+func TestProvidersSignerType(t *testing.T) {
+	chainID := big.NewInt(1)
+	encryptedKey, address := newTestKey(t)
+	providers := map[string]func(signerType key.SignerType) (key.Provider, error){
+		"encrypted": func(signerType key.SignerType) (key.Provider, error) {
+			return newEncryptedPrivateKeyProvider(plainDecrypter{}, encryptedKey, chainID, signerType)
+		},
+		"lazy": func(signerType key.SignerType) (key.Provider, error) {
+			return newLazyProvider(plainDecrypter{}, encryptedKey, address, chainID, signerType)
+		},
+	}
+	tests := []struct {
+		signerType key.SignerType
+		legacy     bool
+		want       key.SignerType
+	}{
+		{signerType: "", want: key.SignerTypeLondon},
+		{signerType: key.SignerTypeLondon, want: key.SignerTypeLondon},
+		{signerType: key.SignerTypeEIP155, legacy: true, want: key.SignerTypeEIP155},
+	}
+	for name, newProvider := range providers {
+		for _, test := range tests {
+			t.Run(name+"/"+string(test.want), func(t *testing.T) {
+				provider, err := newProvider(test.signerType)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := key.GetSignerType(provider); got != test.want {
+					t.Fatalf("signer type %s, want %s", got, test.want)
+				}
+				tx, err := key.SignTx(context.Background(), provider, newTestTx(test.legacy))
+				if err != nil {
+					t.Fatal(err)
+				}
+				signer, err := key.NewSigner(test.want, chainID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				sender, err := types.Sender(signer, tx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if sender != address {
+					t.Fatalf("sender %s, want %s", sender.Hex(), address.Hex())
+				}
+			})
+		}
+	}
+}
+
+func TestProvidersRejectUnknownSignerType(t *testing.T) {
+	encryptedKey, address := newTestKey(t)
+	_, err := newEncryptedPrivateKeyProvider(plainDecrypter{}, encryptedKey, big.NewInt(1), "unknown")
+	if err == nil {
+		t.Fatal("encrypted provider accepted an unknown signer type")
+	}
+	_, err = newLazyProvider(plainDecrypter{}, encryptedKey, address, big.NewInt(1), "unknown")
+	if err == nil {
+		t.Fatal("lazy provider accepted an unknown signer type")
+	}
+}
@@ -1,8 +1,12 @@
 package pk
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/welthee/dobermann/key"
 	"math/big"
@@ -11,24 +15,47 @@ import (
 // NewPrivateKeyProvider is a utility method to easily create a transaction signer
 // from a single private key for the given chainID.
 func NewPrivateKeyProvider(privateKeyHex string, chainID *big.Int) (key.Provider, error) {
+	return NewPrivateKeyProviderWithSigner(privateKeyHex, chainID, key.SignerTypeLondon)
+}
+
+// NewPrivateKeyProviderWithSigner is NewPrivateKeyProvider signing with the given signer type,
+// e.g. key.SignerTypeEIP155 for chains which only accept legacy transactions.
+func NewPrivateKeyProviderWithSigner(privateKeyHex string, chainID *big.Int, signerType key.SignerType) (key.Provider, error) {
 	privateKey, err := crypto.HexToECDSA(privateKeyHex)
 	if err != nil {
 		return nil, err
 	}
-
-	opts, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	signer, err := key.NewSigner(signerType, chainID)
 	if err != nil {
 		return nil, err
 	}
+
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	opts := &bind.TransactOpts{
+		From: address,
+		Signer: func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if from != address {
+				return nil, bind.ErrNotAuthorized
+			}
+			if signerType == key.SignerTypeEIP155 && tx.Type() != types.LegacyTxType {
+				return nil, errors.New("eip155 signer only signs legacy transactions")
+			}
+			return types.SignTx(tx, signer, privateKey)
+		},
+	}
 	return privateKeyProvider{
 		TransactOpts: opts,
 		Address:      &opts.From,
+		signerType:   signerType,
+		privateKey:   privateKey,
 	}, nil
 }
 
 type privateKeyProvider struct {
 	TransactOpts *bind.TransactOpts
 	Address      *common.Address
+	signerType   key.SignerType
+	privateKey   *ecdsa.PrivateKey
 }
 
 func (p privateKeyProvider) GetAddress() *common.Address {
@@ -38,3 +65,11 @@ func (p privateKeyProvider) GetAddress() *common.Address {
 func (p privateKeyProvider) GetTransactOpts() *bind.TransactOpts {
 	return p.TransactOpts
 }
+
+func (p privateKeyProvider) SignerType() key.SignerType {
+	return p.signerType
+}
+
+func (p privateKeyProvider) SignHash(_ context.Context, hash common.Hash) ([]byte, error) {
+	return crypto.Sign(hash[:], p.privateKey)
+}
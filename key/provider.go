@@ -1,11 +1,16 @@
 package key
 
 import (
+	"context"
+	"errors"
+
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
-// Provider defines the methods needed to send and sign transactions
+// Provider defines the methods needed to send and sign transactions. A Provider may be used by several
+// accounts collected concurrently, e.g. the destination, so it must be safe for concurrent use.
 type Provider interface {
 	// GetAddress returns an Address which contains the 20 byte address of an Ethereum account
 	GetAddress() *common.Address
@@ -13,3 +18,51 @@ type Provider interface {
 	// to sign an Ethereum transaction.
 	GetTransactOpts() *bind.TransactOpts
 }
+
+// ContextSigner can be implemented by a Provider whose signing honors the context
+// deadline and cancellation, it is preferred over the TransactOpts signer when available
+type ContextSigner interface {
+	// SignTx returns the given transaction signed by the provider key
+	SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error)
+}
+
+// SignTx signs the transaction with the given provider, using its ContextSigner when implemented
+func SignTx(ctx context.Context, provider Provider, tx *types.Transaction) (*types.Transaction, error) {
+	if signer, ok := provider.(ContextSigner); ok {
+		return signer.SignTx(ctx, tx)
+	}
+	transactOpts := provider.GetTransactOpts()
+	return transactOpts.Signer(transactOpts.From, tx)
+}
+
+// ErrHashSigningUnsupported the provider can only sign transactions, e.g. the KMS and the remote providers
+var ErrHashSigningUnsupported = errors.New("key provider can not sign hashes")
+
+// HashSigner can be implemented by a Provider able to sign any 32 byte hash, e.g. the EIP-712 digest of an
+// EIP-2612 permit
+type HashSigner interface {
+	// SignHash returns the 65 bytes [R || S || V] signature of the hash, V being the recovery id 0 or 1
+	SignHash(ctx context.Context, hash common.Hash) ([]byte, error)
+}
+
+// SignHash signs the hash with the given provider, ErrHashSigningUnsupported when it does not implement HashSigner
+func SignHash(ctx context.Context, provider Provider, hash common.Hash) ([]byte, error) {
+	if signer, ok := provider.(HashSigner); ok {
+		return signer.SignHash(ctx, hash)
+	}
+	return nil, ErrHashSigningUnsupported
+}
+
+// Releaser can be implemented by a Provider holding key material which is only needed while an account
+// is collected, the collector calls Release once it is done with the account
+type Releaser interface {
+	// Release drops the key material, it is loaded again when the provider signs the next time
+	Release()
+}
+
+// Release releases the provider when it implements Releaser
+func Release(provider Provider) {
+	if releaser, ok := provider.(Releaser); ok {
+		releaser.Release()
+	}
+}
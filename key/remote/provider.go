@@ -0,0 +1,175 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/welthee/dobermann/internal/httpx"
+	"github.com/welthee/dobermann/key"
+)
+
+const defaultTimeout = 30 * time.Second
+
+var (
+	// ErrSigningRequestFailed the signing service could not be reached or did not answer successfully
+	ErrSigningRequestFailed = errors.New("signing request failed")
+	// ErrInvalidSignature the signing service answered with a signature which does not belong to the expected address
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Config contains the signing service configuration
+type Config struct {
+	// Endpoint the URL the unsigned transactions are posted to
+	Endpoint string
+	// Authorization the value of the Authorization header sent with every request, e.g. "Bearer <token>"
+	Authorization string
+	// Address the address the signatures must recover to
+	Address common.Address
+	ChainID *big.Int
+	// Timeout of a signing request when the context has no earlier deadline, 30 seconds when zero
+	Timeout time.Duration
+	// HTTPClient used for the requests, http.DefaultClient when nil
+	HTTPClient *http.Client
+}
+
+type signRequest struct {
+	Tx      string `json:"tx"`
+	ChainID string `json:"chainId"`
+}
+
+type signResponse struct {
+	Signature string `json:"signature"`
+}
+
+type remoteKeyProvider struct {
+	config       Config
+	signer       types.Signer
+	transactOpts *bind.TransactOpts
+}
+
+// NewRemoteKeyProvider is a utility method to create a transaction signer backed by an external
+// signing service. Each unsigned transaction is posted as RLP hex together with the chain id,
+// and the service has to answer with the 65 bytes [R || S || V] signature as hex, V being the
+// recovery id 0 or 1:
+//
+//	POST {"tx": "0x02f8...", "chainId": "137"}
+//	200 {"signature": "0x..."}
+func NewRemoteKeyProvider(config Config) (key.Provider, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("signing endpoint not set")
+	}
+	if config.ChainID == nil {
+		return nil, errors.New("chain id not set")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+
+	p := &remoteKeyProvider{
+		config: config,
+		signer: types.LatestSignerForChainID(config.ChainID),
+	}
+	p.transactOpts = &bind.TransactOpts{
+		From: config.Address,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != config.Address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return p.SignTx(context.Background(), tx)
+		},
+		Context: context.Background(),
+	}
+	return p, nil
+}
+
+func (p *remoteKeyProvider) GetAddress() *common.Address {
+	return &p.config.Address
+}
+
+func (p *remoteKeyProvider) GetTransactOpts() *bind.TransactOpts {
+	return p.transactOpts
+}
+
+// SignTx posts the unsigned transaction to the signing service and verifies
+// the returned signature recovers to the configured address
+func (p *remoteKeyProvider) SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+
+	signature, err := p.requestSignature(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx, err := tx.WithSignature(p.signer, signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	sender, err := types.Sender(p.signer, signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if sender != p.config.Address {
+		return nil, fmt.Errorf("%w: recovered %s, expected %s", ErrInvalidSignature, sender.Hex(), p.config.Address.Hex())
+	}
+	return signedTx, nil
+}
+
+func (p *remoteKeyProvider) requestSignature(ctx context.Context, tx *types.Transaction) ([]byte, error) {
+	unsignedTx, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(signRequest{
+		Tx:      hexutil.Encode(unsignedTx),
+		ChainID: p.config.ChainID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSigningRequestFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.config.Authorization != "" {
+		req.Header.Set("Authorization", p.config.Authorization)
+	}
+
+	resp, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSigningRequestFailed, err)
+	}
+	respBody, err := httpx.ReadBody(resp.Body, httpx.DefaultMaxBodySize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSigningRequestFailed, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d: %s", ErrSigningRequestFailed, resp.StatusCode, respBody)
+	}
+
+	var signResp signResponse
+	err = json.Unmarshal(respBody, &signResp)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSigningRequestFailed, err)
+	}
+	signature, err := hexutil.Decode(signResp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return signature, nil
+}
@@ -0,0 +1,49 @@
+package key
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SignerType selects how the transactions are signed and which transaction type is built for them
+type SignerType string
+
+const (
+	// SignerTypeLondon signs dynamic fee transactions, the default
+	SignerTypeLondon SignerType = "london"
+	// SignerTypeEIP155 signs legacy transactions with a gas price and EIP-155 replay protection,
+	// for chains which do not support dynamic fee transactions
+	SignerTypeEIP155 SignerType = "eip155"
+)
+
+// ErrUnknownSignerType the signer type is not one of the supported ones
+var ErrUnknownSignerType = errors.New("unknown signer type")
+
+// TypedSigner can be implemented by a Provider created for a specific SignerType,
+// so that it is not used with transactions of another type
+type TypedSigner interface {
+	SignerType() SignerType
+}
+
+// NewSigner returns the signer of the given type for the chain, SignerTypeLondon when empty
+func NewSigner(signerType SignerType, chainID *big.Int) (types.Signer, error) {
+	switch signerType {
+	case "", SignerTypeLondon:
+		return types.NewLondonSigner(chainID), nil
+	case SignerTypeEIP155:
+		return types.NewEIP155Signer(chainID), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSignerType, signerType)
+	}
+}
+
+// GetSignerType returns the SignerType of the provider, SignerTypeLondon when it does not implement TypedSigner
+func GetSignerType(provider Provider) SignerType {
+	if typed, ok := provider.(TypedSigner); ok && typed.SignerType() != "" {
+		return typed.SignerType()
+	}
+	return SignerTypeLondon
+}
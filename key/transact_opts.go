@@ -0,0 +1,53 @@
+package key
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrMissingTransactOpts = errors.New("transact opts not set")
+	ErrMissingFrom         = errors.New("transact opts from address not set")
+	ErrMissingSigner       = errors.New("transact opts signer not set")
+)
+
+type transactOptsProvider struct {
+	transactOpts *bind.TransactOpts
+	address      *common.Address
+}
+
+func (t transactOptsProvider) GetAddress() *common.Address {
+	return t.address
+}
+
+func (t transactOptsProvider) GetTransactOpts() *bind.TransactOpts {
+	return t.transactOpts
+}
+
+// FromTransactOpts is a utility method to create a Provider from already configured
+// TransactOpts, e.g. built by a hardware wallet or a remote signer like clef:
+//
+//	clef, _ := external.NewExternalSigner("http://localhost:8550")
+//	opts := bind.NewClefTransactor(clef, accounts.Account{Address: address})
+//	provider, err := key.FromTransactOpts(opts)
+//
+// The given opts are copied, so they are never mutated by dobermann.
+func FromTransactOpts(opts *bind.TransactOpts) (Provider, error) {
+	if opts == nil {
+		return nil, ErrMissingTransactOpts
+	}
+	if opts.From == (common.Address{}) {
+		return nil, ErrMissingFrom
+	}
+	if opts.Signer == nil {
+		return nil, ErrMissingSigner
+	}
+
+	transactOpts := *opts
+	return transactOptsProvider{
+		transactOpts: &transactOpts,
+		address:      &transactOpts.From,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package key
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestFromTransactOpts(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(privateKey, big.NewInt(137))
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err := FromTransactOpts(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the provider keeps its copy of the opts
+	opts.GasLimit = 21_000
+	if provider.GetTransactOpts() == opts || provider.GetTransactOpts().GasLimit != 0 {
+		t.Fatal("transact opts not copied")
+	}
+	if *provider.GetAddress() != crypto.PubkeyToAddress(privateKey.PublicKey) {
+		t.Fatalf("address %s, want %s", provider.GetAddress().Hex(), crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+	}
+
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tests := []struct {
+		name    string
+		chainID int64
+		from    common.Address
+		wantErr error
+	}{
+		{name: "signed for the chain", chainID: 137, from: *provider.GetAddress()},
+		{name: "mismatched chain id", chainID: 1, from: *provider.GetAddress(), wantErr: types.ErrInvalidChainId},
+		{name: "other sender", chainID: 137, from: to, wantErr: bind.ErrNotAuthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(test.chainID), GasTipCap: big.NewInt(1),
+				GasFeeCap: big.NewInt(2), Gas: 21_000, To: &to})
+
+			signed, err := provider.GetTransactOpts().Signer(test.from, tx)
+			if !errors.Is(err, test.wantErr) || (test.wantErr == nil && err != nil) {
+				t.Fatalf("error %v, want %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(137)), signed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sender != *provider.GetAddress() || signed.ChainId().Int64() != 137 {
+				t.Fatalf("signed by %s for chain %s, want %s for chain 137", sender.Hex(), signed.ChainId(),
+					provider.GetAddress().Hex())
+			}
+		})
+	}
+}
+
+func TestFromTransactOptsInvalid(t *testing.T) {
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tests := []struct {
+		name string
+		opts *bind.TransactOpts
+		want error
+	}{
+		{name: "no opts", want: ErrMissingTransactOpts},
+		{name: "no from", opts: &bind.TransactOpts{Signer: func(common.Address, *types.Transaction) (*types.Transaction, error) {
+			return nil, nil
+		}}, want: ErrMissingFrom},
+		{name: "no signer", opts: &bind.TransactOpts{From: from}, want: ErrMissingSigner},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := FromTransactOpts(test.opts); !errors.Is(err, test.want) {
+				t.Fatalf("error %v, want %v", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package dobermann
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+type LedgerFailurePolicy string
+
+var (
+	// LedgerFailurePolicyContinue logs the ledger write failure and keeps the account result
+	LedgerFailurePolicyContinue LedgerFailurePolicy = "continue"
+	// LedgerFailurePolicyFail marks the account as failed when the ledger write fails,
+	// even though the tokens were already collected
+	LedgerFailurePolicyFail LedgerFailurePolicy = "fail"
+
+	ErrLedgerWriteFailed = errors.New("failed to write ledger entry")
+)
+
+// Ledger records every successful collection as it happens
+type Ledger interface {
+	// Append records the entry, it is invoked once the collection transaction is mined
+	Append(ctx context.Context, entry LedgerEntry) error
+}
+
+// LedgerEntry identifies a successful collection
+type LedgerEntry struct {
+	RunID       string `json:"runId"`
+	Source      string `json:"source"`
+	Token       string `json:"token"`
+	Amount      Wei    `json:"amount"`
+	Destination string `json:"destination"`
+	TxHash      string `json:"txHash"`
+	BlockNumber uint64 `json:"blockNumber"`
+	// TokenID the id of the ERC-721 token collected, the Amount being 1
+	TokenID string `json:"tokenId,omitempty"`
+	// BlockTime the timestamp of the block, omitted when it could not be read
+	BlockTime *time.Time `json:"blockTime,omitempty"`
+}
+
+// FileLedger is an append-only Ledger writing one JSON entry per line
+type FileLedger struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileLedger utility method to create a FileLedger appending to the file at the given path
+func NewFileLedger(path string) (*FileLedger, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileLedger{file: file}, nil
+}
+
+// Append writes the entry and syncs the file to stable storage
+func (l *FileLedger) Append(ctx context.Context, entry LedgerEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(data)
+	if err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+// Close closes the underlying file
+func (l *FileLedger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
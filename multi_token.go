@@ -0,0 +1,157 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+)
+
+// tokenAccounts returns an account per token collected from the account, the one of its Token first and then
+// the ones of its further Tokens, which are collected with their whole balance and an estimated gas limit
+func tokenAccounts(account SourceAccount) []SourceAccount {
+	first := account
+	first.Tokens = nil
+	accounts := []SourceAccount{first}
+	for _, token := range account.Tokens {
+		next := first
+		next.Token = token
+		next.Amount = ""
+		next.GasLimit = 0
+		accounts = append(accounts, next)
+	}
+	return accounts
+}
+
+// collectTokens collects all the tokens of the account in one pass. The transfers are prepared under consecutive
+// nonces and collected together with collectPrepared.
+func (c evmCollector) collectTokens(ctx context.Context, b *batch, account SourceAccount, destinationAccount DestinationAccount) Result {
+	accountCtx, cancel := b.controller.accountContext(ctx, *account.KeyProvider.GetAddress())
+	defer cancel()
+
+	accounts := tokenAccounts(account)
+	results := make([]Result, len(accounts))
+	cols := make([]*collection, len(accounts))
+	nonce, backlog, result, ok := c.checkBacklog(accountCtx, account)
+	if !ok {
+		return c.cancelledResult(ctx, b, account, result)
+	}
+	for i, tokenAccount := range accounts {
+		col, result := c.prepareAt(accountCtx, b, tokenAccount, destinationAccount, nonce)
+		if col == nil {
+			results[i] = c.cancelledResult(ctx, b, tokenAccount, result)
+			continue
+		}
+		col.backlog = backlog
+		cols[i] = col
+		nonce = new(big.Int).SetUint64(col.erc20Tx.Nonce() + 1)
+	}
+	return tokenResults(c.collectPrepared(ctx, accountCtx, b, account, destinationAccount, cols, results))
+}
+
+// collectPrepared funds the account once with the fees of all the transfers prepared under consecutive nonces,
+// the nil collections having their result set already, and sweeps them one after the other, stopping at the
+// first one not collected as the following nonces could not be mined. The native balance is reclaimed once
+// after the last transfer. The steps before the funding is sent run under the accountCtx.
+func (c evmCollector) collectPrepared(ctx context.Context, accountCtx context.Context, b *batch, account SourceAccount,
+	destinationAccount DestinationAccount, cols []*collection, results []Result) []Result {
+	var lead *collection
+	fee := new(big.Int)
+	for _, col := range cols {
+		if col == nil {
+			continue
+		}
+		col.deferReclaim = true
+		col.fundingAmount = big.NewInt(0)
+		fee.Add(fee, new(big.Int).Mul(new(big.Int).SetUint64(col.erc20Tx.Gas()), col.erc20Tx.GasFeeCap()))
+		if lead == nil {
+			lead = col
+		}
+	}
+	if lead == nil {
+		return results
+	}
+
+	// the first prepared token carries the funding of all of them
+	balance, err := c.transactor.BalanceAt(accountCtx, *lead.sourceAddress)
+	if err != nil {
+		return endTokens(cols, results, handleError(ctx, account, PhaseFundingBuild, err))
+	}
+	lead.fundingAmount = c.planner().planFunding(fee, balance)
+	if c.dryRun {
+		for i, col := range cols {
+			if col != nil {
+				results[i] = c.simulate(ctx, col)
+			}
+		}
+		return results
+	}
+
+	if lead.needsFunding() {
+		if c.destinationFundsWait.Enabled {
+			funded, err := c.awaitDestinationFunds(accountCtx, b, *lead.destinationAddress, c.fundingCost(lead))
+			if err != nil {
+				result := c.cancelledResult(ctx, b, account, handleError(ctx, account, PhaseFundingBuild, err))
+				return endTokens(cols, results, result)
+			}
+			if !funded {
+				return endTokens(cols, results, getResult(ctx, account, StatusSkip, ReasonInsufficientDestinationFunds))
+			}
+		}
+		if b.controller.Cancelled(*lead.sourceAddress) {
+			return endTokens(cols, results, getResult(ctx, account, StatusCancelled, ReasonCancelled))
+		}
+		phase, err := c.fundWithRetry(ctx, b, lead, destinationAccount)
+		if err != nil {
+			result := handleError(ctx, account, phase, err)
+			result.FundingTxHash = txHashHex(lead.fundingTxHash)
+			result.FundingAmount = lead.fundingAmount.String()
+			return endTokens(cols, results, result)
+		}
+		lead.funded = true
+		if result, cancelled := c.cancelFunded(ctx, b, lead); cancelled {
+			return endTokens(cols, results, result)
+		}
+	} else if b.controller.Cancelled(*lead.sourceAddress) {
+		return endTokens(cols, results, getResult(ctx, account, StatusCancelled, ReasonCancelled))
+	}
+
+	var swept *collection
+	failed := false
+	for i, col := range cols {
+		if col == nil {
+			continue
+		}
+		if failed {
+			results[i] = getResult(ctx, col.account, StatusSkip, ReasonPreviousTokenFailed)
+			continue
+		}
+		results[i] = c.sweep(ctx, b, col)
+		if results[i].Status != StatusSuccess {
+			failed = true
+			continue
+		}
+		swept = col
+	}
+	if swept != nil && (c.reclaimNative || account.CollectNative) {
+		results[0].ReclaimStatus = c.reclaim(ctx, account, *swept.sourceAddress, *swept.destinationAddress, swept.gasTipCapValue, swept.gasFeeCapValue)
+	}
+	return results
+}
+
+// endTokens sets the result of all the prepared tokens from the result of the account, when none was swept
+func endTokens(cols []*collection, results []Result, result Result) []Result {
+	for i, col := range cols {
+		if col == nil {
+			continue
+		}
+		results[i] = result
+		results[i].SourceAccount = col.account
+	}
+	return results
+}
+
+// tokenResults returns the result of the Token with the results of the further Tokens
+func tokenResults(results []Result) Result {
+	result := results[0]
+	result.Tokens = results[1:]
+	return result
+}
@@ -0,0 +1,151 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/transactor"
+)
+
+// isNative reports whether the account sweeps its native balance instead of an ERC-20 token
+func isNative(account SourceAccount) bool {
+	return account.Token == ""
+}
+
+// collectNative sends the native balance of the account, minus the exact fee of the transfer, to the destination.
+// The account pays the fee itself, so it is never funded, and it is skipped when its balance does not exceed the
+// fee, so that no zero value transfer is sent. A set Amount is sent instead of the whole balance.
+func (c evmCollector) collectNative(ctx context.Context, b *batch, account SourceAccount, destinationAccount DestinationAccount) (result Result) {
+	sourceAddress := account.KeyProvider.GetAddress()
+	destinationAddress := destinationAccount.KeyProvider.GetAddress()
+	if sourceAddress == nil || destinationAddress == nil {
+		return handleError(ctx, account, PhaseValidation, ErrNilKeyProvider)
+	}
+
+	balance, err := c.transactor.BalanceAt(ctx, *sourceAddress)
+	if err != nil {
+		return handleError(ctx, account, PhaseBalanceCheck, err)
+	}
+	if balance.Sign() == 0 {
+		return getResult(ctx, account, StatusSkip, ReasonZeroBalance)
+	}
+	gasTipCapValue, gasFeeCapValue, err := c.transactor.GetGasCapValues(ctx)
+	if err != nil {
+		return handleError(ctx, account, PhaseGasFetch, err)
+	}
+	gasLimit := account.GasLimit
+	if gasLimit == 0 {
+		gasLimit = params.TxGas
+	}
+
+	txParams := transactor.TxParams{
+		SenderKeyProvider: account.KeyProvider,
+		ReceiverAddress:   destinationAddress,
+		GasTipCapValue:    gasTipCapValue,
+		GasFeeCapValue:    gasFeeCapValue,
+		GasLimit:          gasLimit,
+	}
+	tx, result, ok := c.createNativeTx(ctx, account, txParams, balance)
+	if !ok {
+		return result
+	}
+	// the transactor raises the caps up to the node minimum, the amount is then reduced by the raised fee
+	if tx.GasFeeCap().Cmp(gasFeeCapValue) != 0 {
+		txParams.GasTipCapValue = tx.GasTipCap()
+		txParams.GasFeeCapValue = tx.GasFeeCap()
+		txParams.Nonce = new(big.Int).SetUint64(tx.Nonce())
+		tx, result, ok = c.createNativeTx(ctx, account, txParams, balance)
+		if !ok {
+			return result
+		}
+	}
+
+	estimatedFee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap()).String()
+	if c.dryRun {
+		result = getResult(ctx, account, StatusSimulated, ReasonDryRun)
+		result.ResolvedAmount = tx.Value().String()
+		result.EstimatedFee = estimatedFee
+		return result
+	}
+	defer func() {
+		result.TxHash = tx.Hash().Hex()
+		result.EstimatedFee = estimatedFee
+	}()
+
+	err = c.transactor.Transfer(ctx, tx)
+	if err != nil {
+		return handleError(ctx, account, PhaseSweepSend, err)
+	}
+
+	receipt, err := c.verifyNative(ctx, tx)
+	if err != nil {
+		return handleError(ctx, account, PhaseSweepWait, err)
+	}
+	if !succeeded(receipt) {
+		return getResult(ctx, account, StatusPending, ReasonNotMined)
+	}
+
+	mined := c.receiptBlock(ctx, b, receipt)
+	if c.ledger != nil {
+		err = c.appendLedger(ctx, b, account, *sourceAddress, *destinationAddress, tx.Value().String(), tx.Hash().Hex(), mined)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("tx", tx.Hash().Hex()).Msg("ledger write failed")
+			if c.ledgerFailurePolicy == LedgerFailurePolicyFail {
+				return handleError(ctx, account, PhaseLedgerWrite, err)
+			}
+		}
+	}
+
+	result = getResult(ctx, account, StatusSuccess, ReasonNone)
+	result.CollectedAmount = tx.Value().String()
+	result.BlockNumber = mined.number
+	result.BlockTime = mined.time
+	result.GasUsed = receipt.GasUsed
+	result.Fee, result.FeeDerived = c.paidFeeString(ctx, tx, receipt)
+	return result
+}
+
+// createNativeTx builds the native transfer sending the balance minus the fee under the caps of the params,
+// or the Amount of the account when set. It returns false with the result of the account when nothing is left
+// once the fee is paid.
+func (c evmCollector) createNativeTx(ctx context.Context, account SourceAccount, txParams transactor.TxParams, balance *big.Int) (*types.Transaction, Result, bool) {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(txParams.GasLimit), txParams.GasFeeCapValue)
+	if balance.Cmp(fee) <= 0 {
+		return nil, getResult(ctx, account, StatusSkip, ReasonInsufficientFunds), false
+	}
+	amount := new(big.Int).Sub(balance, fee)
+	if account.Amount != "" {
+		requested, err := parseAmount(account.Amount)
+		if err != nil {
+			return nil, handleError(ctx, account, PhaseValidation, err), false
+		}
+		if requested.Sign() == 0 {
+			return nil, getResult(ctx, account, StatusSkip, ReasonZeroAmount), false
+		}
+		if requested.Cmp(amount) > 0 {
+			return nil, getResult(ctx, account, StatusSkip, ReasonInsufficientBalance), false
+		}
+		amount = requested
+	}
+	txParams.Amount = amount.String()
+
+	tx, err := c.transactor.CreateTx(ctx, txParams)
+	if err != nil {
+		return nil, handleError(ctx, account, PhaseSweepBuild, err), false
+	}
+	return tx, Result{}, true
+}
+
+// verifyNative waits for the native transfer and its confirmations
+func (c evmCollector) verifyNative(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	timeoutCtx, cancelFunc := context.WithTimeout(ctx, c.confirmationTimeout)
+	defer cancelFunc()
+	receipt, err := c.transactor.VerifyTx(timeoutCtx, tx.Hash().Hex())
+	if err != nil || !succeeded(receipt) {
+		return receipt, err
+	}
+	return c.awaitConfirmations(ctx, tx.Hash(), receipt)
+}
@@ -3,11 +3,13 @@ package nonce
 import (
 	"context"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/welthee/dobermann/client"
 	"math/big"
 )
 
-// Provider defines method to get a nonce value
+// Provider defines method to get a nonce value, it must be safe for concurrent use.
+// The nonce is the one of the mined transactions, so concurrent senders of the same address
+// have to allocate their nonces themselves.
 type Provider interface {
 	// GetNonce returns the nonce which will be associated with an account.
 	GetNonce(ctx context.Context, address *common.Address) (*big.Int, error)
@@ -32,7 +34,7 @@ func NewFixedNonceProvider(nonce *big.Int) Provider {
 }
 
 type networkNonceProvider struct {
-	client *ethclient.Client
+	client client.Client
 }
 
 func (f networkNonceProvider) GetNonce(ctx context.Context, address *common.Address) (*big.Int, error) {
@@ -45,6 +47,6 @@ func (f networkNonceProvider) GetNonce(ctx context.Context, address *common.Addr
 
 // NewNetworkNonceProvider utility method to create a nonce provider which will
 // interrogate the network for the nonce value
-func NewNetworkNonceProvider(client *ethclient.Client) Provider {
+func NewNetworkNonceProvider(client client.Client) Provider {
 	return networkNonceProvider{client: client}
 }
@@ -0,0 +1,59 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// paidFee returns the wei paid for the gas of the mined transaction, the gas used times the effective gas price.
+// Some nodes, e.g. older bor versions and some proxies, omit the effectiveGasPrice from their receipts, it is then
+// derived from the transaction and the base fee of its block and derived is true. The fee is nil when it can not be
+// derived, e.g. when the block can not be read.
+func (c evmCollector) paidFee(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) (fee *big.Int, derived bool) {
+	if tx == nil || receipt == nil {
+		return nil, false
+	}
+	price := receipt.EffectiveGasPrice
+	if price == nil {
+		var baseFee *big.Int
+		if tx.Type() != types.LegacyTxType {
+			if receipt.BlockNumber == nil {
+				return nil, false
+			}
+			header, err := c.client.HeaderByNumber(ctx, receipt.BlockNumber)
+			if err != nil {
+				log.Ctx(ctx).Warn().Err(err).Str("tx", tx.Hash().Hex()).Msg("failed to derive the effective gas price")
+				return nil, false
+			}
+			baseFee = header.BaseFee
+		}
+		price = effectiveGasPrice(tx, baseFee)
+		derived = true
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), price), derived
+}
+
+// paidFeeString returns the paidFee as the decimal string of the Result, empty when it can not be derived
+func (c evmCollector) paidFeeString(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) (string, bool) {
+	fee, derived := c.paidFee(ctx, tx, receipt)
+	if fee == nil {
+		return "", false
+	}
+	return fee.String(), derived
+}
+
+// effectiveGasPrice the gas price paid by the transaction in a block with the given base fee,
+// min(feeCap, baseFee + tip) for the dynamic fee transactions and the gas price for the others
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return new(big.Int).Set(tx.GasPrice())
+	}
+	price := new(big.Int).Add(baseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		price.Set(tx.GasFeeCap())
+	}
+	return price
+}
@@ -0,0 +1,136 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/key"
+	"github.com/welthee/dobermann/transactor"
+)
+
+// permitValidity how long after it is signed a permit can be used, the permit is sent right away
+const permitValidity = time.Hour
+
+// ErrPermitReverted the permit transaction was mined but reverted
+var ErrPermitReverted = errors.New("permit transaction reverted")
+
+// usesPermit reports whether the account is collected with a permit, which is only made for the transfers of
+// the tokens of a single Token held by the source itself
+func (c evmCollector) usesPermit(account SourceAccount) bool {
+	return account.UsePermit && account.Wallet == nil && len(account.Tokens) == 0 && !isNative(account) && !account.ERC721 &&
+		c.strategy != CollectStrategyApprove
+}
+
+// isPermitUnsupported reports whether the permit could not be made because of the token or the source key provider,
+// the account is then collected by funding it
+func isPermitUnsupported(err error) bool {
+	return errors.Is(err, transactor.ErrPermitUnsupported) || errors.Is(err, key.ErrHashSigningUnsupported)
+}
+
+// collectPermit sends the EIP-2612 permit signed by the source from the destination and pulls the tokens with a
+// transferFrom of the destination once it was mined, without funding the source. It returns false when the token
+// or the source key provider do not support permits, the account is then to be collected as the others.
+func (c evmCollector) collectPermit(ctx context.Context, b *batch, account SourceAccount, destinationAccount DestinationAccount) (Result, bool) {
+	sourceAddress := account.KeyProvider.GetAddress()
+	destinationAddress := destinationAccount.KeyProvider.GetAddress()
+	if destinationAddress == nil {
+		return handleError(ctx, account, PhaseValidation, ErrNilKeyProvider), true
+	}
+	if c.detectPausedTokens && b.isTokenPaused(account.Token) {
+		return getResult(ctx, account, StatusTokenPaused, ReasonTokenPaused), true
+	}
+
+	accountCtx, cancel := b.controller.accountContext(ctx, *sourceAddress)
+	defer cancel()
+	snapshot, err := c.amountSnapshot(accountCtx, account, *sourceAddress, *destinationAddress)
+	if err != nil {
+		return c.cancelledResult(ctx, b, account, handleError(ctx, account, PhaseBalanceCheck, err)), true
+	}
+	decision := c.planner().planAmount(snapshot)
+	if decision.err != nil {
+		return handleError(ctx, account, PhaseBalanceCheck, decision.err), true
+	}
+	if !decision.collect() {
+		return getResult(ctx, account, decision.status, decision.reason), true
+	}
+
+	eligible, err := c.isDestinationEligible(accountCtx, account, *sourceAddress, *destinationAddress)
+	if err != nil {
+		return c.cancelledResult(ctx, b, account, handleError(ctx, account, PhaseValidation, err)), true
+	}
+	if !eligible {
+		return getResult(ctx, account, StatusDestinationNotEligible, ReasonDestinationNotEligible), true
+	}
+
+	gasTipCapValue, gasFeeCapValue, err := c.transactor.GetGasCapValues(accountCtx)
+	if err != nil {
+		return c.cancelledResult(ctx, b, account, handleError(ctx, account, PhaseGasFetch, err)), true
+	}
+	params := transactor.TxParams{
+		TokenAddr:         account.Token,
+		SenderKeyProvider: destinationAccount.KeyProvider,
+		OwnerKeyProvider:  account.KeyProvider,
+		Amount:            decision.amount.String(),
+		GasTipCapValue:    gasTipCapValue,
+		GasFeeCapValue:    gasFeeCapValue,
+		Deadline:          big.NewInt(c.clock.Now().Add(permitValidity).Unix()),
+	}
+
+	release := b.destinationNonce.allocate(&params)
+	permitTx, err := c.transactor.CreateERC20PermitTx(accountCtx, params)
+	if err != nil {
+		release(nil)
+		if isPermitUnsupported(err) {
+			log.Ctx(ctx).Debug().Err(err).Str("account", addressHex(account)).Msg("permit unsupported, funding the account")
+			return Result{}, false
+		}
+		return c.cancelledResult(ctx, b, account, c.handleTransferError(ctx, b, account, PhaseSweepBuild, err)), true
+	}
+	if c.dryRun {
+		release(nil)
+		result := getResult(ctx, account, StatusSimulated, ReasonDryRun)
+		result.ResolvedAmount = decision.amount.String()
+		result.EstimatedFee = new(big.Int).Mul(new(big.Int).SetUint64(permitTx.Gas()), permitTx.GasFeeCap()).String()
+		return result, true
+	}
+	if b.controller.Cancelled(*sourceAddress) {
+		release(nil)
+		return getResult(ctx, account, StatusCancelled, ReasonCancelled), true
+	}
+	err = c.transactor.Transfer(ctx, permitTx)
+	if err != nil {
+		release(nil)
+		result := c.handleTransferError(ctx, b, account, PhaseSweepSend, err)
+		result.PermitTxHash = permitTx.Hash().Hex()
+		return result, true
+	}
+	release(permitTx)
+
+	result := c.awaitPermit(ctx, account, permitTx.Hash().Hex())
+	if result.Status == "" {
+		result = c.pull(ctx, b, account, destinationAccount)
+	}
+	result.PermitTxHash = permitTx.Hash().Hex()
+	return result, true
+}
+
+// awaitPermit waits for the sent permit, returning an empty result once it succeeded
+func (c evmCollector) awaitPermit(ctx context.Context, account SourceAccount, txHash string) Result {
+	timeoutCtx, cancelFunc := context.WithTimeout(ctx, c.confirmationTimeout)
+	defer cancelFunc()
+	receipt, err := c.transactor.VerifyTx(timeoutCtx, txHash)
+	if err != nil {
+		return handleError(ctx, account, PhaseSweepWait, err)
+	}
+	if receipt == nil {
+		return getResult(ctx, account, StatusPending, ReasonNotMined)
+	}
+	if !succeeded(receipt) {
+		return handleError(ctx, account, PhaseSweepWait, fmt.Errorf("%w: %s", ErrPermitReverted, txHash))
+	}
+	return Result{}
+}
@@ -0,0 +1,17 @@
+package dobermann
+
+// Phase identifies the step of the collection in which a Result was produced
+type Phase string
+
+const (
+	PhaseValidation   Phase = "validation"
+	PhaseBalanceCheck Phase = "balance-check"
+	PhaseGasFetch     Phase = "gas-fetch"
+	PhaseSweepBuild   Phase = "sweep-build"
+	PhaseFundingBuild Phase = "funding-build"
+	PhaseFundingSend  Phase = "funding-send"
+	PhaseFundingWait  Phase = "funding-wait"
+	PhaseSweepSend    Phase = "sweep-send"
+	PhaseSweepWait    Phase = "sweep-wait"
+	PhaseLedgerWrite  Phase = "ledger-write"
+)
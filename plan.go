@@ -0,0 +1,245 @@
+package dobermann
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrPlanHashMismatch = errors.New("plan hash mismatch")
+	ErrPlanMismatch     = errors.New("plan changed since approval")
+)
+
+// Plan is the canonical description of a collection, used to approve a run before executing it
+type Plan struct {
+	Destination common.Address `json:"destination"`
+	Entries     []PlanEntry    `json:"entries"`
+	Fees        PlanFees       `json:"fees"`
+}
+
+// PlanEntry the amount resolved for a SourceAccount, with the metadata of the token checked by PlanVerify
+type PlanEntry struct {
+	Source common.Address `json:"source"`
+	Token  string         `json:"token"`
+	Amount string         `json:"amount"`
+	// Decimals of the token, nil when the token does not return them
+	Decimals *uint8 `json:"decimals,omitempty"`
+	// CodeHash the keccak256 hash of the token code, e.g. changed by a proxy upgrade
+	CodeHash string `json:"codeHash,omitempty"`
+}
+
+// PlanFees the gas cap values in wei at the time the plan was made
+type PlanFees struct {
+	GasTipCap string `json:"gasTipCap"`
+	GasFeeCap string `json:"gasFeeCap"`
+}
+
+// PlanTolerance defines which changes are accepted between an approved plan and
+// the plan regenerated right before execution. Destination, sources and tokens must always match.
+type PlanTolerance struct {
+	// AllowAmountIncrease accepts resolved amounts higher than the approved ones,
+	// in which case only the approved amounts are collected
+	AllowAmountIncrease bool
+	// MaxFeeCapIncrease is the wei the gas fee cap may have risen since approval
+	MaxFeeCapIncrease *big.Int
+}
+
+// Hash returns the hex encoded SHA-256 of the canonical plan serialization
+func (p Plan) Hash() (string, error) {
+	canonical := Plan{
+		Destination: p.Destination,
+		Entries:     make([]PlanEntry, len(p.Entries)),
+		Fees:        p.Fees,
+	}
+	for i, entry := range p.Entries {
+		entry.Token = strings.ToLower(entry.Token)
+		canonical.Entries[i] = entry
+	}
+
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (c evmCollector) Plan(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount) (*Plan, error) {
+	err := validateKeyProvider(destinationAccount.KeyProvider)
+	if err != nil {
+		return nil, fmt.Errorf("destination: %w", err)
+	}
+	for i, account := range accounts {
+		err = validateKeyProvider(account.KeyProvider)
+		if err != nil {
+			return nil, fmt.Errorf("account %d: %w", i, err)
+		}
+	}
+
+	gasTipCapValue, gasFeeCapValue, err := c.transactor.GetGasCapValues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := Plan{
+		Destination: *destinationAccount.KeyProvider.GetAddress(),
+		Entries:     make([]PlanEntry, 0, len(accounts)),
+		Fees: PlanFees{
+			GasTipCap: gasTipCapValue.String(),
+			GasFeeCap: gasFeeCapValue.String(),
+		},
+	}
+
+	metadata := make(map[string]tokenMetadata)
+	for _, account := range accounts {
+		amount, err := c.resolveAmount(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		token, err := c.tokenMetadata(ctx, metadata, account.Token)
+		if err != nil {
+			return nil, err
+		}
+		plan.Entries = append(plan.Entries, PlanEntry{
+			Source:   *tokenHolder(account),
+			Token:    account.Token,
+			Amount:   amount.String(),
+			Decimals: token.decimals,
+			CodeHash: token.codeHash,
+		})
+	}
+
+	return &plan, nil
+}
+
+func (c evmCollector) CollectPlan(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount, approved Plan, expectedHash string) ([]Result, error) {
+	approvedHash, err := approved.Hash()
+	if err != nil {
+		return nil, err
+	}
+	if approvedHash != expectedHash {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrPlanHashMismatch, expectedHash, approvedHash)
+	}
+
+	if len(approved.Entries) != len(accounts) {
+		return nil, fmt.Errorf("%w: %d accounts approved, got %d", ErrPlanMismatch, len(approved.Entries), len(accounts))
+	}
+	drifts := &DriftReport{}
+	if c.driftPolicy.BeforeCollect {
+		drifts, err = c.PlanVerify(ctx, destinationAccount, accounts, approved)
+		if err != nil {
+			return nil, err
+		}
+		if drifts.Aborted() {
+			return nil, fmt.Errorf("%w: %d drifts", ErrPlanDrift, len(drifts.Drifts))
+		}
+	}
+
+	// the skipped accounts are left out of the comparison with the approved plan
+	results := make([]Result, len(accounts))
+	kept := make([]int, 0, len(accounts))
+	keptAccounts := make([]SourceAccount, 0, len(accounts))
+	keptPlan := Plan{Destination: approved.Destination, Fees: approved.Fees}
+	for i, account := range accounts {
+		if drifts.Skipped(i) {
+			results[i] = getResult(ctx, account, StatusSkip, ReasonPlanDrift)
+			continue
+		}
+		keptPlan.Entries = append(keptPlan.Entries, approved.Entries[i])
+		kept = append(kept, i)
+		keptAccounts = append(keptAccounts, account)
+	}
+
+	current, err := c.Plan(ctx, destinationAccount, keptAccounts)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyPlan(keptPlan, *current, c.planTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	approvedAccounts := make([]SourceAccount, len(keptAccounts))
+	for i, account := range keptAccounts {
+		account.Amount = keptPlan.Entries[i].Amount
+		approvedAccounts[i] = account
+	}
+	for i, result := range c.Collect(ctx, destinationAccount, approvedAccounts) {
+		results[kept[i]] = result
+	}
+	return results, nil
+}
+
+// resolveAmount returns the amount which would be collected from the account,
+// zero when there is nothing to collect
+func (c evmCollector) resolveAmount(ctx context.Context, account SourceAccount) (*big.Int, error) {
+	tokenBalance, err := c.getTokenBalance(ctx, tokenHolder(account), account)
+	if err != nil {
+		return nil, err
+	}
+	if account.Amount == "" {
+		return tokenBalance, nil
+	}
+
+	requestedAmount, err := parseAmount(account.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if tokenBalance.Cmp(requestedAmount) < 0 {
+		return big.NewInt(0), nil
+	}
+	return requestedAmount, nil
+}
+
+// verifyPlan compares the regenerated plan with the approved one, the amounts and the gas fee cap which drifted beyond
+// the tolerance failing with ErrPlanDrift as well
+func verifyPlan(approved Plan, current Plan, tolerance PlanTolerance) error {
+	if approved.Destination != current.Destination {
+		return fmt.Errorf("%w: destination %s != %s", ErrPlanMismatch, approved.Destination.Hex(), current.Destination.Hex())
+	}
+	if len(approved.Entries) != len(current.Entries) {
+		return fmt.Errorf("%w: %d accounts approved, got %d", ErrPlanMismatch, len(approved.Entries), len(current.Entries))
+	}
+
+	for i, a := range approved.Entries {
+		e := current.Entries[i]
+		if a.Source != e.Source || !strings.EqualFold(a.Token, e.Token) {
+			return fmt.Errorf("%w: entry %d account or token differs", ErrPlanMismatch, i)
+		}
+
+		approvedAmount, ok := new(big.Int).SetString(a.Amount, 10)
+		if !ok {
+			return fmt.Errorf("%w: entry %d invalid approved amount", ErrPlanMismatch, i)
+		}
+		currentAmount, err := parseAmount(e.Amount)
+		if err != nil {
+			return fmt.Errorf("%w: entry %d: %w", ErrPlanMismatch, i, err)
+		}
+		cmp := currentAmount.Cmp(approvedAmount)
+		if cmp < 0 || (cmp > 0 && !tolerance.AllowAmountIncrease) {
+			return fmt.Errorf("%w: %w: entry %d amount %s != %s", ErrPlanMismatch, ErrPlanDrift, i, a.Amount, e.Amount)
+		}
+	}
+
+	approvedFeeCap, ok := new(big.Int).SetString(approved.Fees.GasFeeCap, 10)
+	if !ok {
+		return fmt.Errorf("%w: invalid approved gas fee cap", ErrPlanMismatch)
+	}
+	if tolerance.MaxFeeCapIncrease != nil {
+		approvedFeeCap.Add(approvedFeeCap, tolerance.MaxFeeCapIncrease)
+	}
+	currentFeeCap, _ := new(big.Int).SetString(current.Fees.GasFeeCap, 10)
+	if currentFeeCap.Cmp(approvedFeeCap) > 0 {
+		return fmt.Errorf("%w: %w: gas fee cap %s above approved %s", ErrPlanMismatch, ErrPlanDrift, current.Fees.GasFeeCap, approvedFeeCap)
+	}
+
+	return nil
+}
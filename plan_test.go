@@ -0,0 +1,195 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/welthee/dobermann/dobermanntest"
+	"github.com/welthee/dobermann/key"
+)
+
+// planTest a destination holding 1 ETH and two accounts holding 100 tokens on the chain
+type planTest struct {
+	chain       *dobermanntest.Chain
+	gasTracker  *dobermanntest.GasTracker
+	destination key.Provider
+	accounts    []SourceAccount
+}
+
+func newPlanTest(t *testing.T) planTest {
+	p := planTest{
+		chain:       dobermanntest.NewChain(big.NewInt(1)),
+		gasTracker:  dobermanntest.NewGasTracker(30, 90),
+		destination: newTestKeyProvider(t),
+	}
+	p.chain.SetBalance(*p.destination.GetAddress(), big.NewInt(1e18))
+	for i := 0; i < 2; i++ {
+		account := SourceAccount{KeyProvider: newTestKeyProvider(t), Token: testToken}
+		p.chain.SetTokenBalance(common.HexToAddress(testToken), *account.KeyProvider.GetAddress(), big.NewInt(100))
+		p.accounts = append(p.accounts, account)
+	}
+	return p
+}
+
+func (p planTest) collector(t *testing.T, config EVMCollectorConfig) Collector {
+	t.Helper()
+	config.Client = p.chain
+	config.GasTracker = p.gasTracker
+	config.NonceProviderType = NonceProviderTypeNetwork
+	config.ReceiptPollInterval = time.Millisecond
+	collector, err := NewEVMCollector(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = collector.Close() })
+	return collector
+}
+
+// setBalance sets the token balance of the first account
+func (p planTest) setBalance(amount int64) {
+	p.chain.SetTokenBalance(common.HexToAddress(testToken), *p.accounts[0].KeyProvider.GetAddress(), big.NewInt(amount))
+}
+
+func TestPlanVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		config EVMCollectorConfig
+		// drift changes the chain or the approved plan after the plan was made
+		drift func(p planTest, plan *Plan)
+		want  []Drift
+	}{
+		{name: "no drift", drift: func(planTest, *Plan) {}},
+		{name: "balance decreased", drift: func(p planTest, _ *Plan) { p.setBalance(50) },
+			want: []Drift{{Index: 0, Type: DriftBalanceDecreased, Planned: "100", Current: "50", Action: DriftActionWarn}}},
+		{name: "balance within tolerance", config: EVMCollectorConfig{DriftPolicy: DriftPolicy{BalanceTolerance: 0.01}},
+			drift: func(p planTest, _ *Plan) { p.setBalance(99) }},
+		{name: "decimals changed", drift: func(_ planTest, plan *Plan) {
+			decimals := uint8(6)
+			plan.Entries[1].Decimals = &decimals
+		}, want: []Drift{{Index: 1, Type: DriftDecimalsChanged, Planned: "6", Current: "none", Action: DriftActionWarn}}},
+		{name: "code changed", drift: func(_ planTest, plan *Plan) { plan.Entries[0].CodeHash = common.Hash{}.Hex() },
+			want: []Drift{{Index: 0, Type: DriftCodeChanged, Planned: common.Hash{}.Hex(), Action: DriftActionWarn}}},
+		{name: "destination not eligible", config: EVMCollectorConfig{TokenPolicies: map[string]TokenPolicy{
+			testToken: {DestinationCheck: EligibilityCheck{Signature: "isWhitelisted(address)"}}}},
+			drift: func(planTest, *Plan) {},
+			want: []Drift{
+				{Index: 0, Type: DriftDestinationNotEligible, Planned: "eligible", Current: "not eligible", Action: DriftActionWarn},
+				{Index: 1, Type: DriftDestinationNotEligible, Planned: "eligible", Current: "not eligible", Action: DriftActionWarn},
+			}},
+		{name: "destination reserve", config: EVMCollectorConfig{DriftPolicy: DriftPolicy{DestinationReserve: big.NewInt(2e18),
+			Actions: map[DriftType]DriftAction{DriftDestinationReserve: DriftActionAbort}}},
+			drift: func(planTest, *Plan) {},
+			want: []Drift{{Index: -1, Type: DriftDestinationReserve, Planned: "2000000000000000000",
+				Current: "1000000000000000000", Action: DriftActionAbort}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := newPlanTest(t)
+			collector := p.collector(t, test.config)
+			destination := DestinationAccount{KeyProvider: p.destination}
+			plan, err := collector.Plan(context.Background(), destination, p.accounts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.drift(p, plan)
+
+			report, err := collector.PlanVerify(context.Background(), destination, p.accounts, *plan)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Drifts) != len(test.want) {
+				t.Fatalf("drifts %+v, want %+v", report.Drifts, test.want)
+			}
+			for i, drift := range report.Drifts {
+				want := test.want[i]
+				// the current code hash is the one of the chain, only checked for the planned one
+				if want.Type == DriftCodeChanged {
+					want.Current = drift.Current
+				}
+				if drift.Index != want.Index || drift.Type != want.Type || drift.Planned != want.Planned ||
+					drift.Current != want.Current || drift.Action != want.Action {
+					t.Fatalf("drift %+v, want %+v", drift, want)
+				}
+			}
+			if report.Aborted() != (len(test.want) > 0 && test.want[0].Action == DriftActionAbort) {
+				t.Fatalf("aborted %v", report.Aborted())
+			}
+		})
+	}
+}
+
+func TestCollectPlanDrift(t *testing.T) {
+	abortBalance := DriftPolicy{BeforeCollect: true, Actions: map[DriftType]DriftAction{DriftBalanceDecreased: DriftActionAbort}}
+	tests := []struct {
+		name   string
+		config EVMCollectorConfig
+		drift  func(p planTest)
+		// want the error of CollectPlan, or the statuses of the accounts when nil
+		want     error
+		statuses []Status
+	}{
+		{name: "no drift", drift: func(planTest) {}, statuses: []Status{StatusSuccess, StatusSuccess}},
+		{name: "balance decreased", drift: func(p planTest) { p.setBalance(50) }, want: ErrPlanDrift},
+		{name: "balance decreased aborting", config: EVMCollectorConfig{DriftPolicy: abortBalance},
+			drift: func(p planTest) { p.setBalance(50) }, want: ErrPlanDrift},
+		{name: "balance decreased skipped", config: EVMCollectorConfig{DriftPolicy: DriftPolicy{BeforeCollect: true,
+			Actions: map[DriftType]DriftAction{DriftBalanceDecreased: DriftActionSkip}}},
+			drift: func(p planTest) { p.setBalance(50) }, statuses: []Status{StatusSkip, StatusSuccess}},
+		{name: "balance increased", drift: func(p planTest) { p.setBalance(150) }, want: ErrPlanDrift},
+		{name: "balance increased within tolerance", config: EVMCollectorConfig{PlanTolerance: PlanTolerance{
+			AllowAmountIncrease: true}}, drift: func(p planTest) { p.setBalance(150) },
+			statuses: []Status{StatusSuccess, StatusSuccess}},
+		{name: "destination below reserve", config: EVMCollectorConfig{DriftPolicy: DriftPolicy{BeforeCollect: true,
+			DestinationReserve: big.NewInt(2e18), Actions: map[DriftType]DriftAction{DriftDestinationReserve: DriftActionAbort}}},
+			drift: func(planTest) {}, want: ErrPlanDrift},
+		{name: "destination not eligible", config: EVMCollectorConfig{
+			DriftPolicy: DriftPolicy{BeforeCollect: true,
+				Actions: map[DriftType]DriftAction{DriftDestinationNotEligible: DriftActionAbort}},
+			TokenPolicies: map[string]TokenPolicy{
+				testToken: {DestinationCheck: EligibilityCheck{Signature: "isWhitelisted(address)"}}}},
+			drift: func(planTest) {}, want: ErrPlanDrift},
+		{name: "fee cap risen", drift: func(p planTest) { p.gasTracker.SetFees(30, 200) }, want: ErrPlanDrift},
+		{name: "fee cap risen within tolerance", config: EVMCollectorConfig{PlanTolerance: PlanTolerance{
+			MaxFeeCapIncrease: big.NewInt(200_000_000_000)}}, drift: func(p planTest) { p.gasTracker.SetFees(30, 200) },
+			statuses: []Status{StatusSuccess, StatusSuccess}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := newPlanTest(t)
+			collector := p.collector(t, test.config)
+			destination := DestinationAccount{KeyProvider: p.destination}
+			plan, err := collector.Plan(context.Background(), destination, p.accounts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hash, err := plan.Hash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.drift(p)
+
+			results, err := collector.CollectPlan(context.Background(), destination, p.accounts, *plan, hash)
+			if !errors.Is(err, test.want) || (test.want == nil && err != nil) {
+				t.Fatalf("error %v, want %v", err, test.want)
+			}
+			if test.want != nil {
+				if sent := len(p.chain.Sent()); sent != 0 {
+					t.Fatalf("%d transactions sent for a drifted plan", sent)
+				}
+				return
+			}
+			for i, result := range results {
+				if result.Status != test.statuses[i] {
+					t.Fatalf("account %d: %s %s, want %s", i, result.Status, result.Reason, test.statuses[i])
+				}
+				if result.Status == StatusSkip && result.Reason != ReasonPlanDrift {
+					t.Fatalf("account %d skipped with %s, want %s", i, result.Reason, ReasonPlanDrift)
+				}
+			}
+		})
+	}
+}
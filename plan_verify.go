@@ -0,0 +1,274 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrPlanDrift the chain state drifted from the approved plan in a way the DriftPolicy aborts on, or the amounts or
+// the gas fee cap of the regenerated plan drifted beyond the PlanTolerance
+var ErrPlanDrift = errors.New("plan drift")
+
+// DriftType a change of the chain state since a Plan was made
+type DriftType string
+
+const (
+	// DriftBalanceDecreased the balance of the account fell below its planned amount beyond the BalanceTolerance
+	DriftBalanceDecreased DriftType = "balance_decreased"
+	// DriftDecimalsChanged the token returns other decimals, e.g. after a migration
+	DriftDecimalsChanged DriftType = "decimals_changed"
+	// DriftCodeChanged the code hash of the token changed, e.g. after a proxy upgrade
+	DriftCodeChanged DriftType = "code_changed"
+	// DriftDestinationNotEligible the destination does not pass the DestinationCheck of the token anymore
+	DriftDestinationNotEligible DriftType = "destination_not_eligible"
+	// DriftDestinationReserve the native balance of the destination is below the DestinationReserve
+	DriftDestinationReserve DriftType = "destination_reserve"
+)
+
+var driftTypes = []DriftType{
+	DriftBalanceDecreased,
+	DriftDecimalsChanged,
+	DriftCodeChanged,
+	DriftDestinationNotEligible,
+	DriftDestinationReserve,
+}
+
+// DriftAction what is done about a drift before the plan is collected
+type DriftAction string
+
+const (
+	// DriftActionWarn logs the drift and collects the account anyway
+	DriftActionWarn DriftAction = "warn"
+	// DriftActionSkip does not collect the account, or any account for the drifts of the destination
+	DriftActionSkip DriftAction = "skip"
+	// DriftActionAbort does not collect the plan at all
+	DriftActionAbort DriftAction = "abort"
+)
+
+// DriftPolicy defines the drifts PlanVerify detects and what CollectPlan does about them
+type DriftPolicy struct {
+	// BalanceTolerance the fraction an account balance may be below its planned amount, e.g. 0.01 for 1%.
+	// Any decrease is a drift when zero.
+	BalanceTolerance float64
+	// DestinationReserve the native wei the destination has to hold, not checked when nil
+	DestinationReserve *big.Int
+	// Actions the action per drift type, DriftActionWarn for the types not set
+	Actions map[DriftType]DriftAction
+	// BeforeCollect runs PlanVerify in CollectPlan, which then skips the accounts or aborts as configured
+	BeforeCollect bool
+}
+
+func (p DriftPolicy) action(driftType DriftType) DriftAction {
+	action, ok := p.Actions[driftType]
+	if !ok {
+		return DriftActionWarn
+	}
+	return action
+}
+
+// validate checks the policy only refers to known drift types and actions
+func (p DriftPolicy) validate() error {
+	if p.BalanceTolerance < 0 || p.BalanceTolerance > 1 {
+		return fmt.Errorf("invalid drift balance tolerance %v", p.BalanceTolerance)
+	}
+	for driftType, action := range p.Actions {
+		known := false
+		for _, t := range driftTypes {
+			known = known || t == driftType
+		}
+		if !known {
+			return fmt.Errorf("unknown drift type %s", driftType)
+		}
+		switch action {
+		case DriftActionWarn, DriftActionSkip, DriftActionAbort:
+		default:
+			return fmt.Errorf("invalid drift action %s of %s", action, driftType)
+		}
+	}
+	return nil
+}
+
+// Drift a difference between a plan and the current chain state
+type Drift struct {
+	// Index of the plan entry, -1 for the drifts of the destination
+	Index   int            `json:"index"`
+	Source  common.Address `json:"source,omitempty"`
+	Token   string         `json:"token,omitempty"`
+	Type    DriftType      `json:"type"`
+	Planned string         `json:"planned"`
+	Current string         `json:"current"`
+	Action  DriftAction    `json:"action"`
+}
+
+// DriftReport the drifts of a plan found by PlanVerify
+type DriftReport struct {
+	Drifts []Drift `json:"drifts"`
+}
+
+// Aborted reports whether a drift aborts the plan
+func (r DriftReport) Aborted() bool {
+	for _, drift := range r.Drifts {
+		if drift.Action == DriftActionAbort {
+			return true
+		}
+	}
+	return false
+}
+
+// Skipped reports whether the plan entry with the given index is not to be collected
+func (r DriftReport) Skipped(index int) bool {
+	for _, drift := range r.Drifts {
+		if drift.Action == DriftActionSkip && (drift.Index == index || drift.Index == -1) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenMetadata the decimals and the code hash of a token
+type tokenMetadata struct {
+	decimals *uint8
+	codeHash string
+}
+
+// tokenMetadata reads the metadata of the token once per cache, the tokens without decimals have nil decimals
+func (c evmCollector) tokenMetadata(ctx context.Context, cache map[string]tokenMetadata, token string) (tokenMetadata, error) {
+	if token == "" {
+		return tokenMetadata{}, nil
+	}
+	key := strings.ToLower(token)
+	if metadata, ok := cache[key]; ok {
+		return metadata, nil
+	}
+
+	var metadata tokenMetadata
+	code, err := c.transactor.CodeAt(ctx, common.HexToAddress(token))
+	if err != nil {
+		return metadata, err
+	}
+	metadata.codeHash = crypto.Keccak256Hash(code).Hex()
+	decimals, err := c.transactor.Decimals(ctx, token)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("token", token).Msg("failed to get decimals")
+	} else {
+		metadata.decimals = &decimals
+	}
+	cache[key] = metadata
+	return metadata, nil
+}
+
+// PlanVerify re-reads the balances, the token metadata and the destination eligibility of the planned accounts
+// and reports how they drifted from the plan, with the action the DriftPolicy takes for each drift
+func (c evmCollector) PlanVerify(ctx context.Context, destinationAccount DestinationAccount, accounts []SourceAccount, plan Plan) (*DriftReport, error) {
+	err := validateKeyProvider(destinationAccount.KeyProvider)
+	if err != nil {
+		return nil, fmt.Errorf("destination: %w", err)
+	}
+	destination := *destinationAccount.KeyProvider.GetAddress()
+	if plan.Destination != destination {
+		return nil, fmt.Errorf("%w: destination %s != %s", ErrPlanMismatch, plan.Destination.Hex(), destination.Hex())
+	}
+	if len(plan.Entries) != len(accounts) {
+		return nil, fmt.Errorf("%w: %d accounts planned, got %d", ErrPlanMismatch, len(plan.Entries), len(accounts))
+	}
+
+	report := &DriftReport{Drifts: make([]Drift, 0)}
+	add := func(index int, entry PlanEntry, driftType DriftType, planned string, current string) {
+		drift := Drift{
+			Index:   index,
+			Source:  entry.Source,
+			Token:   entry.Token,
+			Type:    driftType,
+			Planned: planned,
+			Current: current,
+			Action:  c.driftPolicy.action(driftType),
+		}
+		log.Ctx(ctx).Warn().
+			Int("index", index).
+			Str("token", entry.Token).
+			Str("drift", string(driftType)).
+			Str("planned", planned).
+			Str("current", current).
+			Str("action", string(drift.Action)).
+			Msg("plan drifted")
+		report.Drifts = append(report.Drifts, drift)
+	}
+
+	metadata := make(map[string]tokenMetadata)
+	for i, account := range accounts {
+		err = validateKeyProvider(account.KeyProvider)
+		if err != nil {
+			return nil, fmt.Errorf("account %d: %w", i, err)
+		}
+		entry := plan.Entries[i]
+		holder := tokenHolder(account)
+		if entry.Source != *holder || !strings.EqualFold(entry.Token, account.Token) {
+			return nil, fmt.Errorf("%w: entry %d account or token differs", ErrPlanMismatch, i)
+		}
+
+		planned, ok := new(big.Int).SetString(entry.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("%w: entry %d invalid planned amount", ErrPlanMismatch, i)
+		}
+		balance, err := c.getTokenBalance(ctx, holder, account)
+		if err != nil {
+			return nil, err
+		}
+		if balance.Cmp(c.driftPolicy.minimumBalance(planned)) < 0 {
+			add(i, entry, DriftBalanceDecreased, planned.String(), balance.String())
+		}
+
+		token, err := c.tokenMetadata(ctx, metadata, account.Token)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Decimals != nil && (token.decimals == nil || *token.decimals != *entry.Decimals) {
+			add(i, entry, DriftDecimalsChanged, fmt.Sprint(*entry.Decimals), formatDecimals(token.decimals))
+		}
+		if entry.CodeHash != "" && !strings.EqualFold(entry.CodeHash, token.codeHash) {
+			add(i, entry, DriftCodeChanged, entry.CodeHash, token.codeHash)
+		}
+
+		eligible, err := c.isDestinationEligible(ctx, account, *holder, destination)
+		if err != nil {
+			return nil, err
+		}
+		if !eligible {
+			add(i, entry, DriftDestinationNotEligible, "eligible", "not eligible")
+		}
+	}
+
+	if c.driftPolicy.DestinationReserve != nil {
+		balance, err := c.transactor.BalanceAt(ctx, destination)
+		if err != nil {
+			return nil, err
+		}
+		if balance.Cmp(c.driftPolicy.DestinationReserve) < 0 {
+			add(-1, PlanEntry{Source: destination}, DriftDestinationReserve, c.driftPolicy.DestinationReserve.String(), balance.String())
+		}
+	}
+	return report, nil
+}
+
+// minimumBalance the lowest balance accepted for the planned amount
+func (p DriftPolicy) minimumBalance(planned *big.Int) *big.Int {
+	if p.BalanceTolerance <= 0 {
+		return planned
+	}
+	tolerated, _ := new(big.Float).Mul(new(big.Float).SetInt(planned), big.NewFloat(p.BalanceTolerance)).Int(nil)
+	return new(big.Int).Sub(planned, tolerated)
+}
+
+func formatDecimals(decimals *uint8) string {
+	if decimals == nil {
+		return "none"
+	}
+	return fmt.Sprint(*decimals)
+}
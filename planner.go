@@ -0,0 +1,76 @@
+package dobermann
+
+import "math/big"
+
+// planner makes the collection decisions of an account from a snapshot of its chain state, without any I/O,
+// while the collector fetches the snapshots and executes the decisions
+type planner struct {
+	strategy             CollectStrategy
+	fundingBuffer        *big.Int
+	minimumFundingAmount *big.Int
+}
+
+// amountSnapshot the state the collected amount is decided from
+type amountSnapshot struct {
+	// requestedAmount the SourceAccount Amount, nil to collect the whole balance
+	requestedAmount *big.Int
+	tokenBalance    *big.Int
+	// allowance the amount the destination may already pull, only used by CollectStrategyApprove
+	allowance *big.Int
+}
+
+// amountDecision the amount to collect, or the outcome of the account when there is nothing to collect
+type amountDecision struct {
+	amount *big.Int
+	status Status
+	reason ReasonCode
+	// err the account fails in the balance check
+	err error
+	// approvedAmount the allowance of an account skipped with ReasonAlreadyApproved
+	approvedAmount *big.Int
+}
+
+// collect reports whether there is an amount to collect
+func (d amountDecision) collect() bool {
+	return d.amount != nil
+}
+
+func (p planner) planAmount(s amountSnapshot) amountDecision {
+	if s.requestedAmount != nil && s.requestedAmount.Sign() == 0 {
+		return amountDecision{status: StatusSkip, reason: ReasonZeroAmount}
+	}
+	if s.tokenBalance.Sign() == 0 {
+		return amountDecision{status: StatusSkip, reason: ReasonZeroBalance}
+	}
+
+	amount := s.tokenBalance
+	if s.requestedAmount != nil {
+		if s.tokenBalance.Cmp(s.requestedAmount) < 0 {
+			return amountDecision{status: StatusFail, err: ErrInsufficientBalance}
+		}
+		amount = s.requestedAmount
+	}
+
+	if p.strategy == CollectStrategyApprove && s.allowance != nil && s.allowance.Cmp(s.tokenBalance) >= 0 {
+		return amountDecision{status: StatusSkip, reason: ReasonAlreadyApproved, approvedAmount: s.allowance}
+	}
+	return amountDecision{amount: amount}
+}
+
+// planFunding returns the wei to be sent to a source account so it can pay estimatedFee:
+// the exact deficit plus the configured buffer, raised to the minimum funding amount.
+// Zero is returned when the account balance already covers the fee.
+func (p planner) planFunding(estimatedFee *big.Int, balance *big.Int) *big.Int {
+	deficit := new(big.Int).Sub(estimatedFee, balance)
+	if deficit.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+
+	if p.fundingBuffer != nil {
+		deficit.Add(deficit, p.fundingBuffer)
+	}
+	if p.minimumFundingAmount != nil && deficit.Cmp(p.minimumFundingAmount) < 0 {
+		deficit.Set(p.minimumFundingAmount)
+	}
+	return deficit
+}
@@ -0,0 +1,267 @@
+package dobermann
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/retry"
+)
+
+const defaultQuarantineThreshold = 3
+
+// QuarantineClass the kind of failure counted by the quarantine, only the failures wasting fees on every run
+// are counted, while the transient ones, e.g. node errors, are not
+type QuarantineClass string
+
+const (
+	// QuarantineClassTokenRejected the token rejected the transfer, e.g. because the source is blacklisted
+	QuarantineClassTokenRejected QuarantineClass = "token_rejected"
+	// QuarantineClassFundingReverted the funding transaction of the account reverted
+	QuarantineClassFundingReverted QuarantineClass = "funding_reverted"
+)
+
+// Quarantine skips the accounts which failed in a row in previous runs
+type Quarantine struct {
+	// Store keeps the failures across runs, the quarantine is disabled when nil
+	Store QuarantineStore
+	// Threshold the consecutive failures of a class after which an account is skipped, 3 when zero
+	Threshold int
+	// TTL after the last failure when the account is collected again, it stays quarantined
+	// until its entry is cleared when zero
+	TTL time.Duration
+}
+
+// QuarantineEntry the consecutive failures of a class of a source account and token
+type QuarantineEntry struct {
+	Address     string          `json:"address"`
+	Token       string          `json:"token"`
+	Class       QuarantineClass `json:"class"`
+	Failures    int             `json:"failures"`
+	LastFailure time.Time       `json:"lastFailure"`
+	// LastMessage the Result Message of the last failure
+	LastMessage string `json:"lastMessage,omitempty"`
+}
+
+// QuarantineStore persists the QuarantineEntry values across runs
+type QuarantineStore interface {
+	// List returns all the entries
+	List(ctx context.Context) ([]QuarantineEntry, error)
+	// Put creates or replaces the entry with the same address, token and class
+	Put(ctx context.Context, entry QuarantineEntry) error
+	// Clear removes the entries of the address and token, of all its tokens when the token is empty
+	Clear(ctx context.Context, address string, token string) error
+}
+
+// FileQuarantineStore is a QuarantineStore keeping all the entries in a JSON file
+type FileQuarantineStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileQuarantineStore utility method to create a FileQuarantineStore at the given path,
+// the file is created with the first entry
+func NewFileQuarantineStore(path string) *FileQuarantineStore {
+	return &FileQuarantineStore{path: path}
+}
+
+func (s *FileQuarantineStore) List(ctx context.Context) ([]QuarantineEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+func (s *FileQuarantineStore) Put(ctx context.Context, entry QuarantineEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i, existing := range entries {
+		if sameQuarantineAccount(existing, entry.Address, entry.Token) && existing.Class == entry.Class {
+			entries[i] = entry
+			return s.write(entries)
+		}
+	}
+	return s.write(append(entries, entry))
+}
+
+func (s *FileQuarantineStore) Clear(ctx context.Context, address string, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+	kept := make([]QuarantineEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !sameQuarantineAccount(entry, address, token) {
+			kept = append(kept, entry)
+		}
+	}
+	return s.write(kept)
+}
+
+func (s *FileQuarantineStore) read() ([]QuarantineEntry, error) {
+	entries := make([]QuarantineEntry, 0)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// write replaces the file through a temporary file, so that it is never left half written
+func (s *FileQuarantineStore) write(entries []QuarantineEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	closeErr := tmp.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// sameQuarantineAccount reports whether the entry belongs to the address and token, any token when empty
+func sameQuarantineAccount(entry QuarantineEntry, address string, token string) bool {
+	return strings.EqualFold(entry.Address, address) && (token == "" || strings.EqualFold(entry.Token, token))
+}
+
+// quarantineClass returns the class of the failed result counted by the quarantine, empty when it is not counted
+func quarantineClass(result Result) QuarantineClass {
+	switch {
+	case result.Status == StatusFundingReverted:
+		return QuarantineClassFundingReverted
+	case result.Status != StatusFail:
+		return ""
+	case result.Phase != PhaseSweepBuild && result.Phase != PhaseSweepSend && result.Phase != PhaseSweepWait:
+		return ""
+	case retry.IsTransient(errors.New(result.Message)):
+		return ""
+	default:
+		return QuarantineClassTokenRejected
+	}
+}
+
+// quarantined the entries of the quarantine loaded at the start of a run
+type quarantined struct {
+	store     QuarantineStore
+	threshold int
+	ttl       time.Duration
+	entries   []QuarantineEntry
+}
+
+// loadQuarantine reads the quarantine entries, nil when the quarantine is disabled or can not be read
+func (c evmCollector) loadQuarantine(ctx context.Context) *quarantined {
+	if c.quarantine.Store == nil {
+		return nil
+	}
+	entries, err := c.quarantine.Store.List(ctx)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to read the quarantine, no account is quarantined")
+		return nil
+	}
+	threshold := c.quarantine.Threshold
+	if threshold <= 0 {
+		threshold = defaultQuarantineThreshold
+	}
+	return &quarantined{
+		store:     c.quarantine.Store,
+		threshold: threshold,
+		ttl:       c.quarantine.TTL,
+		entries:   entries,
+	}
+}
+
+// isQuarantined reports whether a class of failures of the account reached the threshold within the TTL
+func (q *quarantined) isQuarantined(account SourceAccount, now time.Time) bool {
+	address := addressHex(account)
+	for _, entry := range q.entries {
+		if sameQuarantineAccount(entry, address, account.Token) && entry.Failures >= q.threshold &&
+			!q.expired(entry, now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *quarantined) expired(entry QuarantineEntry, now time.Time) bool {
+	return q.ttl > 0 && now.Sub(entry.LastFailure) > q.ttl
+}
+
+// record counts the failure of the result, or clears the failures of the account once it was collected
+func (q *quarantined) record(ctx context.Context, result Result, now time.Time) {
+	address := addressHex(result.SourceAccount)
+	token := result.SourceAccount.Token
+	if result.Status == StatusSuccess {
+		for _, entry := range q.entries {
+			if sameQuarantineAccount(entry, address, token) {
+				err := q.store.Clear(ctx, address, token)
+				if err != nil {
+					log.Ctx(ctx).Warn().Err(err).Str("account", address).Msg("failed to clear the quarantine")
+				}
+				return
+			}
+		}
+		return
+	}
+
+	class := quarantineClass(result)
+	if class == "" {
+		return
+	}
+	entry := QuarantineEntry{
+		Address: address,
+		Token:   token,
+		Class:   class,
+	}
+	for _, existing := range q.entries {
+		if sameQuarantineAccount(existing, address, token) && existing.Class == class && !q.expired(existing, now) {
+			entry.Failures = existing.Failures
+		}
+	}
+	entry.Failures++
+	entry.LastFailure = now
+	entry.LastMessage = result.Message
+	err := q.store.Put(ctx, entry)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("account", address).Msg("failed to record the quarantine failure")
+		return
+	}
+	q.entries = append(q.entries, entry)
+	if entry.Failures == q.threshold {
+		log.Ctx(ctx).Warn().
+			Str("account", address).
+			Str("token", token).
+			Str("class", string(class)).
+			Msg("account quarantined")
+	}
+}
@@ -0,0 +1,165 @@
+package dobermann
+
+import "sort"
+
+// ReasonCode is a machine-readable explanation attached to a Result. Its values are stable, new codes
+// have to be registered in reasonDescriptions
+type ReasonCode string
+
+const (
+	// ReasonNone no additional reason available
+	ReasonNone ReasonCode = ""
+	// ReasonZeroBalance the source account holds no tokens
+	ReasonZeroBalance ReasonCode = "zero_balance"
+	// ReasonZeroAmount the requested amount resolved to zero
+	ReasonZeroAmount ReasonCode = "zero_amount"
+	// ReasonNonceTooLow another transaction with the same nonce was already mined
+	ReasonNonceTooLow ReasonCode = "nonce_too_low"
+	// ReasonAlreadyPending the transfer is already known by the network
+	ReasonAlreadyPending ReasonCode = "already_pending"
+	// ReasonNotMined the transfer was sent but could not be verified
+	ReasonNotMined ReasonCode = "not_mined"
+	// ReasonInterrupted the collection was cancelled before the account was completed
+	ReasonInterrupted ReasonCode = "interrupted"
+	// ReasonAfterCollectAborted the run was aborted because the AfterCollect hook failed for a previous account
+	ReasonAfterCollectAborted ReasonCode = "after_collect_aborted"
+	// ReasonFeeWindowNotMet the fees were above the configured FeeWindow
+	ReasonFeeWindowNotMet ReasonCode = "fee_window_not_met"
+	// ReasonBudgetExceeded the estimated cost of the account exceeds the rest of the CostOrdering budget
+	ReasonBudgetExceeded ReasonCode = "budget_exceeded"
+	// ReasonFundingReverted the funding transaction of the account was mined but reverted
+	ReasonFundingReverted ReasonCode = "funding_reverted"
+	// ReasonDestinationNotEligible the TokenPolicy DestinationCheck returned false or reverted
+	ReasonDestinationNotEligible ReasonCode = "destination_not_eligible"
+	// ReasonBroadcastVetoed the PreBroadcast hook rejected a transaction of the account
+	ReasonBroadcastVetoed ReasonCode = "broadcast_vetoed"
+	// ReasonTokenPaused the token rejected the transfer because it is paused
+	ReasonTokenPaused ReasonCode = "token_paused"
+	// ReasonSelfCollection the source account is the destination account
+	ReasonSelfCollection ReasonCode = "self_collection"
+	// ReasonAlreadyApproved the destination is already allowed to pull the whole balance
+	ReasonAlreadyApproved ReasonCode = "already_approved"
+	// ReasonZeroAllowance the source account did not approve any tokens to the destination
+	ReasonZeroAllowance ReasonCode = "zero_allowance"
+	// ReasonInvalidKeyProvider the key provider of the source or the destination is not set or has no address
+	ReasonInvalidKeyProvider ReasonCode = "invalid_key_provider"
+	// ReasonSignerTypeMismatch a key provider was created for another signer type than the collector
+	ReasonSignerTypeMismatch ReasonCode = "signer_type_mismatch"
+	// ReasonNodeMinGasPriceAboveCap the gas price the node accepts is above the MaxGasFeeCap
+	ReasonNodeMinGasPriceAboveCap ReasonCode = "node_min_gas_price_above_cap"
+	// ReasonInsufficientBalance the source account holds less tokens than the requested amount
+	ReasonInsufficientBalance ReasonCode = "insufficient_balance"
+	// ReasonInsufficientAllowance the source account approved less tokens than the requested amount
+	ReasonInsufficientAllowance ReasonCode = "insufficient_allowance"
+	// ReasonSourceIsContract the source is a contract for which no ExecutorCalldata is configured
+	ReasonSourceIsContract ReasonCode = "source_is_contract"
+	// ReasonWalletNotContract the SourceAccount Wallet has no contract code
+	ReasonWalletNotContract ReasonCode = "wallet_not_contract"
+	// ReasonInnerTransferMissing the wallet call was mined but it did not transfer the tokens
+	ReasonInnerTransferMissing ReasonCode = "inner_transfer_missing"
+	// ReasonLedgerWriteFailed the collection was made but it could not be written to the ledger
+	ReasonLedgerWriteFailed ReasonCode = "ledger_write_failed"
+	// ReasonInsufficientDestinationFunds the destination could not fund the account within the DestinationFundsWait
+	ReasonInsufficientDestinationFunds ReasonCode = "insufficient_destination_funds"
+	// ReasonQuarantined the account failed too many times in a row in previous runs, see Quarantine
+	ReasonQuarantined ReasonCode = "quarantined"
+	// ReasonInvalidAmount the Amount of the account is not a decimal integer fitting in uint256
+	ReasonInvalidAmount ReasonCode = "invalid_amount"
+	// ReasonInsufficientFunds the sender of a transaction can not pay its gas and value
+	ReasonInsufficientFunds ReasonCode = "insufficient_funds"
+	// ReasonCancelled the collection of the account was cancelled through the RunController
+	ReasonCancelled ReasonCode = "cancelled"
+	// ReasonPlanDrift the chain state drifted from the approved plan, see DriftPolicy
+	ReasonPlanDrift ReasonCode = "plan_drift"
+	// ReasonPreviousTokenFailed a previous token of the same account was not collected, see SourceAccount Tokens
+	ReasonPreviousTokenFailed ReasonCode = "previous_token_failed"
+	// ReasonPermitReverted the permit transaction sent instead of the funding was mined but reverted
+	ReasonPermitReverted ReasonCode = "permit_reverted"
+	// ReasonChainStalled no new block was seen for the ChainStall Threshold, a sent transaction may still be mined
+	ReasonChainStalled ReasonCode = "chain_stalled"
+	// ReasonTokenIDsRequired the ERC-721 collection is not enumerable, the SourceAccount TokenIDs have to be set
+	ReasonTokenIDsRequired ReasonCode = "token_ids_required"
+	// ReasonNotTokenOwner the account does not own the ERC-721 token, see SourceAccount TokenIDs
+	ReasonNotTokenOwner ReasonCode = "not_token_owner"
+	// ReasonScreeningDenied the Screener denied the source or the destination address
+	ReasonScreeningDenied ReasonCode = "screening_denied"
+	// ReasonScreeningFailed the Screener could not check an address and the Screening fails closed
+	ReasonScreeningFailed ReasonCode = "screening_failed"
+	// ReasonSourceBusy the source has transactions pending before it was funded, see SourceBacklogSkip
+	ReasonSourceBusy ReasonCode = "source_busy"
+	// ReasonDryRun the account would be collected, nothing was sent as DryRun is enabled
+	ReasonDryRun ReasonCode = "dry_run"
+	// ReasonError any other error, see the Result Message
+	ReasonError ReasonCode = "error"
+)
+
+// reasonDescriptions the registry of all the reason codes with their human readable description
+var reasonDescriptions = map[ReasonCode]string{
+	ReasonNone:                         "",
+	ReasonZeroBalance:                  "the source account holds no tokens",
+	ReasonZeroAmount:                   "the requested amount is zero",
+	ReasonNonceTooLow:                  "another transaction with the same nonce was already mined",
+	ReasonAlreadyPending:               "the transfer is already pending",
+	ReasonNotMined:                     "the transfer was sent but could not be verified",
+	ReasonInterrupted:                  "the collection was cancelled",
+	ReasonAfterCollectAborted:          "the run was aborted by the AfterCollect hook",
+	ReasonFeeWindowNotMet:              "the fees were above the fee window",
+	ReasonBudgetExceeded:               "the estimated cost exceeds the remaining budget",
+	ReasonFundingReverted:              "the funding transaction reverted",
+	ReasonDestinationNotEligible:       "the destination is not eligible for the token",
+	ReasonBroadcastVetoed:              "a transaction was vetoed by the PreBroadcast hook",
+	ReasonTokenPaused:                  "the token is paused",
+	ReasonSelfCollection:               "the source account is the destination",
+	ReasonAlreadyApproved:              "the destination is already approved for the whole balance",
+	ReasonZeroAllowance:                "the source account approved no tokens",
+	ReasonInvalidKeyProvider:           "the key provider is not set",
+	ReasonSignerTypeMismatch:           "the key provider signer type does not match the collector",
+	ReasonNodeMinGasPriceAboveCap:      "the node minimum gas price is above the max gas fee cap",
+	ReasonInsufficientBalance:          "the balance is lower than the requested amount",
+	ReasonInsufficientAllowance:        "the allowance is lower than the requested amount",
+	ReasonSourceIsContract:             "the source is a contract without executor",
+	ReasonWalletNotContract:            "the wallet is not a contract",
+	ReasonInnerTransferMissing:         "the wallet call did not transfer the tokens",
+	ReasonLedgerWriteFailed:            "the ledger write failed",
+	ReasonInsufficientDestinationFunds: "the destination balance does not cover the funding",
+	ReasonQuarantined:                  "the account is quarantined after repeated failures",
+	ReasonInvalidAmount:                "the amount is not a valid uint256",
+	ReasonInsufficientFunds:            "the sender can not pay the gas and value of a transaction",
+	ReasonCancelled:                    "the collection of the account was cancelled",
+	ReasonPreviousTokenFailed:          "a previous token of the account was not collected",
+	ReasonPlanDrift:                    "the chain state drifted from the approved plan",
+	ReasonPermitReverted:               "the permit transaction reverted",
+	ReasonChainStalled:                 "the chain stopped producing blocks",
+	ReasonTokenIDsRequired:             "the collection is not enumerable, the token ids are required",
+	ReasonNotTokenOwner:                "the source account does not own the token",
+	ReasonScreeningDenied:              "an address of the collection was denied by the screening",
+	ReasonScreeningFailed:              "an address of the collection could not be screened",
+	ReasonSourceBusy:                   "the source has pending transactions",
+	ReasonDryRun:                       "the collection was simulated without sending transactions",
+	ReasonError:                        "the collection failed",
+}
+
+// ReasonCodes returns all the registered reason codes, sorted, without ReasonNone
+func ReasonCodes() []ReasonCode {
+	codes := make([]ReasonCode, 0, len(reasonDescriptions))
+	for code := range reasonDescriptions {
+		if code != ReasonNone {
+			codes = append(codes, code)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i] < codes[j]
+	})
+	return codes
+}
+
+// Registered reports whether the reason code is declared in the registry
+func (r ReasonCode) Registered() bool {
+	_, ok := reasonDescriptions[r]
+	return ok
+}
+
+// Description returns the human readable description of the reason code, empty when it is not registered
+func (r ReasonCode) Description() string {
+	return reasonDescriptions[r]
+}
@@ -0,0 +1,173 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrRunBlocksMissing the report has no start or end block, e.g. it was created by an older version
+	ErrRunBlocksMissing = errors.New("run report without start and end block")
+	// ErrRunDestinationMissing the report has no valid destination address
+	ErrRunDestinationMissing = errors.New("run report without destination")
+)
+
+// ReconciliationStatus the outcome of the verification of a token
+type ReconciliationStatus string
+
+const (
+	// ReconciliationMatched the destination received exactly the collected amount
+	ReconciliationMatched ReconciliationStatus = "matched"
+	// ReconciliationShortfall the destination received less than the collected amount, e.g. because
+	// the token takes a fee on transfer, or the destination sent tokens during the run
+	ReconciliationShortfall ReconciliationStatus = "shortfall"
+	// ReconciliationSurplus the destination received more than the collected amount, e.g. because
+	// of deposits made by others during the run
+	ReconciliationSurplus ReconciliationStatus = "surplus"
+)
+
+// ReconciliationReport the outcome of VerifyRun
+type ReconciliationReport struct {
+	Destination string `json:"destination"`
+	StartBlock  uint64 `json:"startBlock"`
+	EndBlock    uint64 `json:"endBlock"`
+	// Tokens one entry per token of the run, in the order the tokens first appear in the report
+	Tokens []TokenReconciliation `json:"tokens"`
+	// Discrepancies the number of tokens which did not match
+	Discrepancies int `json:"discrepancies"`
+}
+
+// TokenReconciliation compares the collected amount of a token with the balance increase of the destination
+type TokenReconciliation struct {
+	Token string `json:"token"`
+	// Expected the sum of the amounts collected successfully
+	Expected Wei `json:"expected"`
+	// Observed the balance of the destination at the end block minus the one at the start block
+	Observed Wei `json:"observed"`
+	// Diff Observed minus Expected
+	Diff   Wei                  `json:"diff"`
+	Status ReconciliationStatus `json:"status"`
+}
+
+// VerifyRun reads the token balances of the destination at the start and at the end block of the run,
+// which may need an archive node when the run is old. Tokens moved to or from the destination by
+// others during the run can not be told apart from the collected ones, such differences are
+// reported as a ReconciliationSurplus or a ReconciliationShortfall.
+func (c evmCollector) VerifyRun(ctx context.Context, report RunReport) (ReconciliationReport, error) {
+	reconciliation := ReconciliationReport{
+		Destination: report.Destination,
+		StartBlock:  report.StartBlock,
+		EndBlock:    report.EndBlock,
+		Tokens:      make([]TokenReconciliation, 0),
+	}
+	if report.StartBlock == 0 || report.EndBlock < report.StartBlock {
+		return reconciliation, ErrRunBlocksMissing
+	}
+	if !common.IsHexAddress(report.Destination) {
+		return reconciliation, ErrRunDestinationMissing
+	}
+	destination := common.HexToAddress(report.Destination)
+
+	expected := make(map[string]*big.Int)
+	tokens := make([]string, 0)
+	for _, entry := range flattenEntries(report.Results) {
+		// the native sweeps are not token transfers
+		if entry.Token == "" {
+			continue
+		}
+		key := strings.ToLower(entry.Token)
+		if _, ok := expected[key]; !ok {
+			expected[key] = new(big.Int)
+			tokens = append(tokens, entry.Token)
+		}
+		if entry.Status == StatusSuccess && entry.CollectedAmount != nil && entry.CollectedAmount.Int != nil {
+			expected[key].Add(expected[key], entry.CollectedAmount.Int)
+		}
+	}
+
+	startBlock := new(big.Int).SetUint64(report.StartBlock)
+	endBlock := new(big.Int).SetUint64(report.EndBlock)
+	for _, token := range tokens {
+		startBalance, err := c.transactor.BalanceOfAt(ctx, destination, token, startBlock)
+		if err != nil {
+			return reconciliation, fmt.Errorf("balance of %s at block %d: %w", token, report.StartBlock, err)
+		}
+		endBalance, err := c.transactor.BalanceOfAt(ctx, destination, token, endBlock)
+		if err != nil {
+			return reconciliation, fmt.Errorf("balance of %s at block %d: %w", token, report.EndBlock, err)
+		}
+
+		tokenExpected := expected[strings.ToLower(token)]
+		observed := new(big.Int).Sub(endBalance, startBalance)
+		diff := new(big.Int).Sub(observed, tokenExpected)
+		status := ReconciliationMatched
+		switch diff.Sign() {
+		case -1:
+			status = ReconciliationShortfall
+		case 1:
+			status = ReconciliationSurplus
+		}
+		if status != ReconciliationMatched {
+			reconciliation.Discrepancies++
+			log.Ctx(ctx).Warn().
+				Str("token", token).
+				Str("expected", tokenExpected.String()).
+				Str("observed", observed.String()).
+				Str("status", string(status)).
+				Msg("run discrepancy")
+		}
+		reconciliation.Tokens = append(reconciliation.Tokens, TokenReconciliation{
+			Token:    token,
+			Expected: NewWei(tokenExpected),
+			Observed: NewWei(observed),
+			Diff:     NewWei(diff),
+			Status:   status,
+		})
+	}
+	return reconciliation, nil
+}
+
+// blockNumber returns the latest block, zero when it can not be read
+func (c evmCollector) blockNumber(ctx context.Context) uint64 {
+	block, err := c.client.BlockNumber(ctx)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to get block number")
+		return 0
+	}
+	return block
+}
+
+// setRun sets the destination and the block range of the run on all the results
+func (c evmCollector) setRun(ctx context.Context, results []Result, destinationAccount DestinationAccount, startBlock uint64) {
+	destination := destinationHex(destinationAccount)
+	endBlock := c.blockNumber(ctx)
+	for i := range results {
+		results[i].Destination = destination
+		results[i].StartBlock = startBlock
+		results[i].EndBlock = endBlock
+	}
+}
+
+// destinationHex returns the hex address of the destination, empty when it has no key provider
+func destinationHex(destinationAccount DestinationAccount) string {
+	if validateKeyProvider(destinationAccount.KeyProvider) != nil {
+		return ""
+	}
+	return destinationAccount.KeyProvider.GetAddress().Hex()
+}
+
+// flattenEntries returns the entries with the entries of their further tokens
+func flattenEntries(entries []ReportEntry) []ReportEntry {
+	flat := make([]ReportEntry, 0, len(entries))
+	for _, entry := range entries {
+		flat = append(flat, entry)
+		flat = append(flat, flattenEntries(entry.Tokens)...)
+	}
+	return flat
+}
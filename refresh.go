@@ -0,0 +1,49 @@
+package dobermann
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// chainIdCache keeps the chain ID shared by the copies of the collector, updated by Refresh
+type chainIdCache struct {
+	mu      sync.RWMutex
+	chainId *big.Int
+}
+
+func (c *chainIdCache) get() *big.Int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return new(big.Int).Set(c.chainId)
+}
+
+func (c *chainIdCache) set(chainId *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.chainId = chainId
+}
+
+func (c evmCollector) Refresh(ctx context.Context) error {
+	chainId, err := c.client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to refresh chain id: %w", err)
+	}
+	previous := c.chainId.get()
+	if previous.Cmp(chainId) != 0 {
+		log.Ctx(ctx).Warn().
+			Str("previous", previous.String()).
+			Str("current", chainId.String()).
+			Msg("chain id changed, key providers have to be recreated")
+	}
+	c.chainId.set(chainId)
+
+	_, err = c.gasTracker.GetSuggestedGasPrice(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to refresh gas tracker: %w", err)
+	}
+	return nil
+}
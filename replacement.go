@@ -0,0 +1,84 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/client"
+	"github.com/welthee/dobermann/transactor"
+)
+
+// replacementBumpPercent is the minimum fee increase accepted by the nodes for a replacement transaction
+const replacementBumpPercent = 10
+
+// replaceTransfer sends a replacement for the transaction pending in the node under the nonce of tx, only when it is
+// a direct ERC-20 transfer of the account for the same token and destination whose amount differs. Otherwise, and
+// when the node can not tell its pending transactions, tx and the original sendErr are returned.
+func (c evmCollector) replaceTransfer(ctx context.Context, params transactor.TxParams, tx *types.Transaction, sendErr error) (*types.Transaction, error) {
+	if params.Wallet != nil || params.Owner != nil {
+		return tx, sendErr
+	}
+	inFlight, err := client.PendingTransaction(ctx, c.client, *params.SenderKeyProvider.GetAddress(), tx.Nonce())
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("in-flight transaction not found, not replacing")
+		return tx, sendErr
+	}
+	amount, ok := inFlightTransferAmount(inFlight, params)
+	if !ok {
+		log.Ctx(ctx).Debug().Str("tx", inFlight.Hash().Hex()).
+			Msg("in-flight transaction is not a transfer of the token to the destination, not replacing")
+		return tx, sendErr
+	}
+	if amount.String() == params.Amount {
+		return tx, sendErr
+	}
+
+	params.Nonce = new(big.Int).SetUint64(tx.Nonce())
+	params.GasTipCapValue = maxBigInt(bumpFee(inFlight.GasTipCap()), tx.GasTipCap())
+	params.GasFeeCapValue = maxBigInt(bumpFee(inFlight.GasFeeCap()), tx.GasFeeCap())
+	replacement, err := c.transactor.CreateERC20Tx(ctx, params)
+	if err != nil {
+		return tx, err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("replaced", inFlight.Hash().Hex()).
+		Str("tx", replacement.Hash().Hex()).
+		Msg("replacing in-flight transfer with changed amount")
+	err = c.transactor.Transfer(ctx, replacement)
+	if err != nil {
+		return tx, err
+	}
+	return replacement, nil
+}
+
+// inFlightTransferAmount returns the amount of the in-flight transaction when it is an ERC-20 transfer of the token
+// of the params to their receiver, without native value
+func inFlightTransferAmount(inFlight *types.Transaction, params transactor.TxParams) (*big.Int, bool) {
+	if inFlight.To() == nil || !strings.EqualFold(inFlight.To().Hex(), common.HexToAddress(params.TokenAddr).Hex()) ||
+		inFlight.Value().Sign() != 0 {
+		return nil, false
+	}
+	receiver, amount, err := transactor.DecodeERC20Transfer(inFlight.Data())
+	if err != nil || receiver != *params.ReceiverKeyProvider.GetAddress() {
+		return nil, false
+	}
+	return amount, true
+}
+
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+replacementBumpPercent))
+	bumped.Div(bumped, big.NewInt(100))
+	return bumped.Add(bumped, big.NewInt(1))
+}
+
+func maxBigInt(a *big.Int, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
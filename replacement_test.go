@@ -0,0 +1,133 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/welthee/dobermann/client"
+	"github.com/welthee/dobermann/transactor"
+)
+
+// txpoolClient a node whose pool holds the pending transaction, when set
+type txpoolClient struct {
+	client.Client
+	pending *types.Transaction
+}
+
+func (c txpoolClient) PendingTransaction(ctx context.Context, from common.Address, nonce uint64) (*types.Transaction, error) {
+	if c.pending == nil || c.pending.Nonce() != nonce {
+		return nil, ethereum.NotFound
+	}
+	return c.pending, nil
+}
+
+// replacingTransactor builds and sends the replacements, recording them
+type replacingTransactor struct {
+	transactor.Transactor
+	created []transactor.TxParams
+	sent    []*types.Transaction
+}
+
+func (t *replacingTransactor) CreateERC20Tx(ctx context.Context, params transactor.TxParams) (*types.Transaction, error) {
+	t.created = append(t.created, params)
+	return newTestTransfer(params.TokenAddr, params.ReceiverKeyProvider.GetAddress(), params.Amount, params.Nonce.Uint64(),
+		params.GasTipCapValue, params.GasFeeCapValue), nil
+}
+
+func (t *replacingTransactor) Transfer(ctx context.Context, tx *types.Transaction) error {
+	t.sent = append(t.sent, tx)
+	return nil
+}
+
+func newTestTransfer(token string, receiver *common.Address, amount string, nonce uint64, gasTipCap *big.Int, gasFeeCap *big.Int) *types.Transaction {
+	data, err := transactor.ERC20TransferData(*receiver, amount)
+	if err != nil {
+		panic(err)
+	}
+	to := common.HexToAddress(token)
+	return types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: nonce, GasTipCap: gasTipCap, GasFeeCap: gasFeeCap,
+		Gas: 60_000, To: &to, Data: data})
+}
+
+func TestReplaceTransfer(t *testing.T) {
+	source := newTestKeyProvider(t)
+	destination := newTestKeyProvider(t)
+	other := newTestKeyProvider(t)
+	tip, feeCap := big.NewInt(30_000_000_000), big.NewInt(60_000_000_000)
+	const otherToken = "0x00000000000000000000000000000000000000bb"
+	tests := []struct {
+		name    string
+		pending *types.Transaction
+		replace bool
+	}{
+		{
+			name:    "different amount",
+			pending: newTestTransfer(testToken, destination.GetAddress(), "1000", 7, tip, feeCap),
+			replace: true,
+		},
+		{
+			name:    "same amount",
+			pending: newTestTransfer(testToken, destination.GetAddress(), "500", 7, tip, feeCap),
+		},
+		{
+			name:    "foreign token at the same nonce",
+			pending: newTestTransfer(otherToken, destination.GetAddress(), "1000", 7, tip, feeCap),
+		},
+		{
+			name:    "foreign receiver at the same nonce",
+			pending: newTestTransfer(testToken, other.GetAddress(), "1000", 7, tip, feeCap),
+		},
+		{
+			name: "native transfer at the same nonce",
+			pending: types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 7, GasTipCap: tip, GasFeeCap: feeCap,
+				Gas: 21_000, To: destination.GetAddress(), Value: big.NewInt(1)}),
+		},
+		{
+			name: "nothing pending",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := &replacingTransactor{}
+			c := evmCollector{transactor: recorder, client: txpoolClient{pending: test.pending}}
+			params := transactor.TxParams{
+				TokenAddr:           testToken,
+				SenderKeyProvider:   source,
+				ReceiverKeyProvider: destination,
+				Amount:              "500",
+				GasTipCapValue:      tip,
+				GasFeeCapValue:      feeCap,
+			}
+			tx := newTestTransfer(testToken, destination.GetAddress(), "500", 7, tip, feeCap)
+
+			got, err := c.replaceTransfer(context.Background(), params, tx, transactor.ErrReplacementUnderpriced)
+			if !test.replace {
+				if !errors.Is(err, transactor.ErrReplacementUnderpriced) || got != tx || len(recorder.sent) != 0 {
+					t.Fatalf("replaced: error %v, %d sent", err, len(recorder.sent))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(recorder.sent) != 1 || got != recorder.sent[0] {
+				t.Fatalf("%d replacements sent", len(recorder.sent))
+			}
+			if got.Nonce() != 7 {
+				t.Fatalf("replacement nonce %d, want 7", got.Nonce())
+			}
+			// the fees are bumped by 10% over the pending transaction
+			if got.GasTipCap().String() != "33000000001" || got.GasFeeCap().String() != "66000000001" {
+				t.Fatalf("replacement fees %s, %s", got.GasTipCap(), got.GasFeeCap())
+			}
+			if recorder.created[0].Amount != "500" {
+				t.Fatalf("replacement amount %s, want 500", recorder.created[0].Amount)
+			}
+		})
+	}
+}
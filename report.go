@@ -0,0 +1,164 @@
+package dobermann
+
+import "time"
+
+const unknownAddress = "unknown"
+
+// RunReport is the serializable outcome of a collection run
+type RunReport struct {
+	// Destination the address the tokens were collected to
+	Destination string `json:"destination,omitempty"`
+	// StartBlock and EndBlock the block range the run was made in, used by VerifyRun
+	StartBlock uint64 `json:"startBlock,omitempty"`
+	EndBlock   uint64 `json:"endBlock,omitempty"`
+	// Collector the configuration of the collector which made the run, set by the caller
+	Collector *CollectorInfo `json:"collector,omitempty"`
+	Summary   Summary        `json:"summary"`
+	Results   []ReportEntry  `json:"results"`
+}
+
+// Summary the number of accounts per Status and ReasonCode
+type Summary struct {
+	Total    int                `json:"total"`
+	Statuses map[Status]int     `json:"statuses"`
+	Reasons  map[ReasonCode]int `json:"reasons"`
+	// DryRun the totals of the accounts which would be collected, set when some have StatusSimulated
+	DryRun *DryRunSummary `json:"dryRun,omitempty"`
+}
+
+// ReportEntry is the serializable outcome of the collection for a SourceAccount
+type ReportEntry struct {
+	Account            string     `json:"account"`
+	Token              string     `json:"token"`
+	Amount             *Wei       `json:"amount,omitempty"`
+	Status             Status     `json:"status"`
+	Reason             ReasonCode `json:"reason,omitempty"`
+	Message            string     `json:"message,omitempty"`
+	Phase              Phase      `json:"phase,omitempty"`
+	ReclaimStatus      Status     `json:"reclaimStatus,omitempty"`
+	AfterCollectError  string     `json:"afterCollectError,omitempty"`
+	ApprovedAmount     *Wei       `json:"approvedAmount,omitempty"`
+	FundingTxTag       string     `json:"fundingTxTag,omitempty"`
+	CollectedAmount    *Wei       `json:"collectedAmount,omitempty"`
+	BlockNumber        uint64     `json:"blockNumber,omitempty"`
+	BlockTime          *time.Time `json:"blockTime,omitempty"`
+	FundingBlockNumber uint64     `json:"fundingBlockNumber,omitempty"`
+	FundingBlockTime   *time.Time `json:"fundingBlockTime,omitempty"`
+	GasUsed            uint64     `json:"gasUsed,omitempty"`
+	Fee                *Wei       `json:"fee,omitempty"`
+	FeeDerived         bool       `json:"feeDerived,omitempty"`
+	ResolvedAmount     *Wei       `json:"resolvedAmount,omitempty"`
+	EstimatedFee       *Wei       `json:"estimatedFee,omitempty"`
+	FundingAmount      *Wei       `json:"fundingAmount,omitempty"`
+	TxHash             string     `json:"txHash,omitempty"`
+	FundingTxHash      string     `json:"fundingTxHash,omitempty"`
+	PermitTxHash       string     `json:"permitTxHash,omitempty"`
+	Bundled            bool       `json:"bundled,omitempty"`
+	// SourceBacklog the transactions of the source found pending before it was funded
+	SourceBacklog uint64 `json:"sourceBacklog,omitempty"`
+	// TokenID the id of the ERC-721 token of the entry
+	TokenID string `json:"tokenId,omitempty"`
+	// Tokens the entries of the further tokens collected from the account
+	Tokens []ReportEntry `json:"tokens,omitempty"`
+}
+
+// NewRunReport utility method to create a RunReport from the results of a collection
+func NewRunReport(results []Result) RunReport {
+	report := newRunReport()
+	report.Results = make([]ReportEntry, 0, len(results))
+	for _, result := range results {
+		report.add(result)
+		report.Results = append(report.Results, newReportEntry(result))
+	}
+	return report
+}
+
+// newRunReport an empty report without results
+func newRunReport() RunReport {
+	return RunReport{
+		Summary: Summary{
+			Statuses: make(map[Status]int),
+			Reasons:  make(map[ReasonCode]int),
+		},
+	}
+}
+
+// add counts the result in the summary and widens the run to its blocks
+func (r *RunReport) add(result Result) {
+	if r.Destination == "" {
+		r.Destination = result.Destination
+	}
+	if result.StartBlock > 0 && (r.StartBlock == 0 || result.StartBlock < r.StartBlock) {
+		r.StartBlock = result.StartBlock
+	}
+	if result.EndBlock > r.EndBlock {
+		r.EndBlock = result.EndBlock
+	}
+	r.Summary.Total++
+	r.Summary.Statuses[result.Status]++
+	if result.Reason != ReasonNone {
+		r.Summary.Reasons[result.Reason]++
+	}
+	if result.Status == StatusSimulated {
+		if r.Summary.DryRun == nil {
+			r.Summary.DryRun = newDryRunSummary()
+		}
+		r.Summary.DryRun.add(result)
+	}
+}
+
+// newReportEntry the entry of the result, with the entries of its further tokens
+func newReportEntry(result Result) ReportEntry {
+	afterCollectError := ""
+	if result.AfterCollectErr != nil {
+		afterCollectError = result.AfterCollectErr.Error()
+	}
+	entry := ReportEntry{
+		Account:            addressHex(result.SourceAccount),
+		Token:              result.SourceAccount.Token,
+		Amount:             parseWei(result.SourceAccount.Amount),
+		Status:             result.Status,
+		Reason:             result.Reason,
+		Message:            result.Message,
+		Phase:              result.Phase,
+		ReclaimStatus:      result.ReclaimStatus,
+		AfterCollectError:  afterCollectError,
+		ApprovedAmount:     parseWei(result.ApprovedAmount),
+		FundingTxTag:       result.FundingTxTag,
+		CollectedAmount:    parseWei(result.CollectedAmount),
+		BlockNumber:        result.BlockNumber,
+		BlockTime:          timePointer(result.BlockTime),
+		FundingBlockNumber: result.FundingBlockNumber,
+		FundingBlockTime:   timePointer(result.FundingBlockTime),
+		GasUsed:            result.GasUsed,
+		Fee:                parseWei(result.Fee),
+		FeeDerived:         result.FeeDerived,
+		ResolvedAmount:     parseWei(result.ResolvedAmount),
+		EstimatedFee:       parseWei(result.EstimatedFee),
+		FundingAmount:      parseWei(result.FundingAmount),
+		TxHash:             result.TxHash,
+		FundingTxHash:      result.FundingTxHash,
+		PermitTxHash:       result.PermitTxHash,
+		Bundled:            result.Bundled,
+		SourceBacklog:      result.SourceBacklog,
+	}
+	if result.SourceAccount.ERC721 && len(result.SourceAccount.TokenIDs) == 1 {
+		entry.TokenID = result.SourceAccount.TokenIDs[0]
+	}
+	for _, token := range result.Tokens {
+		entry.Tokens = append(entry.Tokens, newReportEntry(token))
+	}
+	return entry
+}
+
+// addressHex returns the hex address of the account, or "unknown" when it can not be derived
+func addressHex(account SourceAccount) string {
+	if account.KeyProvider == nil {
+		return unknownAddress
+	}
+	address := account.KeyProvider.GetAddress()
+	if address == nil {
+		return unknownAddress
+	}
+	return address.Hex()
+}
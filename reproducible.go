@@ -0,0 +1,150 @@
+package dobermann
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// the timestamps of the report entries, replaced by their offset from the run start in the reproducible form
+var reportTimeKeys = map[string]string{
+	"blockTime":        "blockTimeOffset",
+	"fundingBlockTime": "fundingBlockTimeOffset",
+}
+
+// ReportOptions how WriteReport writes a RunReport
+type ReportOptions struct {
+	// Reproducible writes the canonical form of the report, so that the same run gives the same bytes: the keys of
+	// all the objects are sorted, the results keep the order of the collected accounts and carry their index, and
+	// the block times are replaced by their offset from the earliest block time of the report,
+	// e.g. "blockTimeOffset": "24s". The report is wrapped as {"report": ..., "sha256": ...}, the hash being the
+	// hex SHA-256 of the compact canonical report.
+	Reproducible bool
+}
+
+// WriteReport writes the report as indented JSON, in its canonical form when the options are Reproducible
+func WriteReport(w io.Writer, report RunReport, options ReportOptions) error {
+	var data []byte
+	var err error
+	if options.Reproducible {
+		data, err = reproducibleReport(report)
+	} else {
+		data, err = json.Marshal(report)
+	}
+	if err != nil {
+		return err
+	}
+	var indented bytes.Buffer
+	err = json.Indent(&indented, data, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = indented.WriteTo(w)
+	return err
+}
+
+// reproducibleReport returns the compact canonical report wrapped with its hash
+func reproducibleReport(report RunReport) ([]byte, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	// encoding/json sorts the keys of the maps, the report is decoded as such to sort the keys of its structs too
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var canonical map[string]interface{}
+	err = decoder.Decode(&canonical)
+	if err != nil {
+		return nil, err
+	}
+
+	results, _ := canonical["results"].([]interface{})
+	for i, result := range results {
+		if entry, ok := result.(map[string]interface{}); ok {
+			entry["index"] = i
+		}
+	}
+	start, err := earliestTime(results)
+	if err != nil {
+		return nil, err
+	}
+	err = replaceTimes(results, start)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(canonical)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	return json.Marshal(struct {
+		Report json.RawMessage `json:"report"`
+		SHA256 string          `json:"sha256"`
+	}{body, hex.EncodeToString(sum[:])})
+}
+
+// earliestTime returns the earliest block time of the entries and their tokens, zero when none is set
+func earliestTime(entries []interface{}) (time.Time, error) {
+	var earliest time.Time
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key := range reportTimeKeys {
+			value, ok := entry[key].(string)
+			if !ok {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%s of the report: %w", key, err)
+			}
+			if earliest.IsZero() || t.Before(earliest) {
+				earliest = t
+			}
+		}
+		tokens, _ := entry["tokens"].([]interface{})
+		t, err := earliestTime(tokens)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !t.IsZero() && (earliest.IsZero() || t.Before(earliest)) {
+			earliest = t
+		}
+	}
+	return earliest, nil
+}
+
+// replaceTimes replaces the block times of the entries and their tokens by their offset from the start
+func replaceTimes(entries []interface{}, start time.Time) error {
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, offsetKey := range reportTimeKeys {
+			value, ok := entry[key].(string)
+			if !ok {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return fmt.Errorf("%s of the report: %w", key, err)
+			}
+			delete(entry, key)
+			entry[offsetKey] = t.Sub(start).String()
+		}
+		tokens, _ := entry["tokens"].([]interface{})
+		err := replaceTimes(tokens, start)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Package retry provides the retry policy shared by the retrying calls of dobermann
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultMultiplier = 2
+
+// Component identifies the calls a Policy applies to in Policies
+type Component string
+
+const (
+	// ComponentGasTracker the gas tracker requests
+	ComponentGasTracker Component = "gas_tracker"
+	// ComponentRPC the read-only requests to the blockchain node
+	ComponentRPC Component = "rpc"
+	// ComponentKMS the KMS decryptions
+	ComponentKMS Component = "kms"
+)
+
+// Classifier reports whether the error of an attempt is worth retrying
+type Classifier func(err error) bool
+
+// Policy describes how a call is retried. The zero Policy makes a single attempt.
+//
+// The delay before the attempt n+1 is InitialDelay * Multiplier^(n-1), limited to MaxDelay when set, minus a
+// random part of up to Jitter of it. As the jitter only shortens the delays, the total time waited between
+// the attempts never exceeds MaxWait.
+type Policy struct {
+	// MaxAttempts the most attempts including the first one, one when zero
+	MaxAttempts int
+	// InitialDelay before the second attempt
+	InitialDelay time.Duration
+	// Multiplier of the delay after each attempt, 2 when zero
+	Multiplier float64
+	// MaxDelay the longest delay between two attempts, unlimited when zero
+	MaxDelay time.Duration
+	// Jitter the largest fraction, between 0 and 1, randomly removed from each delay
+	Jitter float64
+	// Retryable the errors to retry, all of them except the context ones when nil
+	Retryable Classifier
+}
+
+// Policies a default Policy with overrides per Component
+type Policies struct {
+	Default   Policy
+	Overrides map[Component]Policy
+}
+
+// For returns the Policy of the component, the Default one when not overridden
+func (p Policies) For(component Component) Policy {
+	if policy, ok := p.Overrides[component]; ok {
+		return policy
+	}
+	return p.Default
+}
+
+var (
+	randMu sync.Mutex
+	random = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// delay returns the delay before the attempt following the given one, without jitter
+func (p Policy) delay(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = defaultMultiplier
+	}
+	delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// MaxWait the longest total time waited between the attempts
+func (p Policy) MaxWait() time.Duration {
+	var total time.Duration
+	for attempt := 1; attempt < p.MaxAttempts; attempt++ {
+		total += p.delay(attempt)
+	}
+	return total
+}
+
+func (p Policy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if p.Retryable == nil {
+		return true
+	}
+	return p.Retryable(err)
+}
+
+func (p Policy) jittered(delay time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return delay
+	}
+	jitter := p.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+	randMu.Lock()
+	defer randMu.Unlock()
+	return delay - time.Duration(random.Float64()*jitter*float64(delay))
+}
+
+// Do calls fn until it succeeds, returns an error which is not retryable or the attempts are exhausted,
+// returning the last error. The waits end early with the context error when the context is done.
+func Do(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) {
+			return err
+		}
+		timer := time.NewTimer(policy.jittered(policy.delay(attempt)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// IsTransient reports whether the error is likely to go away on its own: network failures, timeouts,
+// rate limits and unavailable servers. It is the default Classifier of the node requests.
+func IsTransient(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	for _, transient := range []string{"connection reset", "connection refused", "too many requests", "429", "502", "503", "504"} {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+)
+
+// RunController cancels the collection of single source accounts while the rest of the run goes on,
+// e.g. when a compliance hold arrives for one address. It is safe for concurrent use.
+type RunController struct {
+	mu        sync.Mutex
+	cancelled map[common.Address]chan struct{}
+}
+
+// NewRunController utility method to create a RunController, given to CollectWithController
+func NewRunController() *RunController {
+	return &RunController{
+		cancelled: make(map[common.Address]chan struct{}),
+	}
+}
+
+// Cancel cancels the collection of the accounts of the source address at their next safe point. Accounts which
+// did not start yet are not collected, and the waits before the funding is sent are interrupted. Once the funding
+// was sent, it is waited for and its native balance is reclaimed when ReclaimNative or CollectNative is set,
+// otherwise the account is swept as the funding would be stranded. A sent sweep is always waited for.
+func (r *RunController) Cancel(address common.Address) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	done := r.channel(address)
+	select {
+	case <-done:
+	default:
+		close(done)
+	}
+}
+
+// Cancelled reports whether Cancel was called for the source address
+func (r *RunController) Cancelled(address common.Address) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	select {
+	case <-r.channel(address):
+		return true
+	default:
+		return false
+	}
+}
+
+// channel returns the channel closed when the address is cancelled, the lock must be held
+func (r *RunController) channel(address common.Address) chan struct{} {
+	done, ok := r.cancelled[address]
+	if !ok {
+		done = make(chan struct{})
+		r.cancelled[address] = done
+	}
+	return done
+}
+
+// accountContext returns a context cancelled as well when the address is cancelled
+func (r *RunController) accountContext(ctx context.Context, address common.Address) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if r == nil {
+		return ctx, cancel
+	}
+	r.mu.Lock()
+	done := r.channel(address)
+	r.mu.Unlock()
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// cancelledResult returns the result of a cancelled account, or the given result when the account was not cancelled
+// through the controller, e.g. when the whole run was cancelled
+func (c evmCollector) cancelledResult(ctx context.Context, b *batch, account SourceAccount, result Result) Result {
+	if ctx.Err() != nil || !b.controller.Cancelled(*account.KeyProvider.GetAddress()) {
+		return result
+	}
+	if result.Status != StatusInterrupted && !errors.Is(result.Err, context.Canceled) {
+		return result
+	}
+	return getResult(ctx, account, StatusCancelled, ReasonCancelled)
+}
+
+// cancelFunded ends a funded account cancelled before its sweep was sent by reclaiming its native balance,
+// when ReclaimNative or CollectNative is set. It returns false when the account is to be swept.
+func (c evmCollector) cancelFunded(ctx context.Context, b *batch, col *collection) (Result, bool) {
+	if !b.controller.Cancelled(*col.sourceAddress) {
+		return Result{}, false
+	}
+	if !c.reclaimNative && !col.account.CollectNative {
+		log.Ctx(ctx).Warn().Str("account", addressHex(col.account)).
+			Msg("account cancelled once funded, sweeping it as the funding can not be reclaimed")
+		return Result{}, false
+	}
+	result := getResult(ctx, col.account, StatusCancelled, ReasonCancelled)
+	result.ReclaimStatus = c.reclaim(ctx, col.account, *col.sourceAddress, *col.destinationAddress, col.gasTipCapValue, col.gasFeeCapValue)
+	result.FundingTxHash = txHashHex(col.fundingTxHash)
+	result.FundingAmount = col.fundingAmount.String()
+	return result, true
+}
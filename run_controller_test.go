@@ -0,0 +1,158 @@
+package dobermann
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/welthee/dobermann/dobermanntest"
+)
+
+// cancellingChain a chain cancelling the collection of an account through the controller once its funding was mined
+type cancellingChain struct {
+	*dobermanntest.Chain
+	controller *RunController
+	account    common.Address
+}
+
+func (c cancellingChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	err := c.Chain.SendTransaction(ctx, tx)
+	if err == nil && tx.To() != nil && *tx.To() == c.account {
+		c.controller.Cancel(c.account)
+	}
+	return err
+}
+
+func TestRunController(t *testing.T) {
+	cancelled, other := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	tests := []struct {
+		name       string
+		controller *RunController
+		// cancels the number of calls of Cancel for the cancelled address
+		cancels int
+		want    bool
+	}{
+		{name: "not cancelled", controller: NewRunController()},
+		{name: "cancelled", controller: NewRunController(), cancels: 1, want: true},
+		{name: "cancelled twice", controller: NewRunController(), cancels: 2, want: true},
+		{name: "no controller"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := test.controller.accountContext(context.Background(), cancelled)
+			defer cancel()
+			otherCtx, otherCancel := test.controller.accountContext(context.Background(), other)
+			defer otherCancel()
+			for i := 0; i < test.cancels; i++ {
+				test.controller.Cancel(cancelled)
+			}
+
+			if got := test.controller.Cancelled(cancelled); got != test.want {
+				t.Fatalf("cancelled %v, want %v", got, test.want)
+			}
+			if test.controller.Cancelled(other) {
+				t.Fatal("other address cancelled")
+			}
+			// the context of the account is cancelled as well, the one of the other address is left running
+			select {
+			case <-ctx.Done():
+				if !test.want {
+					t.Fatal("context of the account cancelled")
+				}
+			case <-time.After(50 * time.Millisecond):
+				if test.want {
+					t.Fatal("context of the account not cancelled")
+				}
+			}
+			if otherCtx.Err() != nil {
+				t.Fatal("context of the other address cancelled")
+			}
+		})
+	}
+}
+
+func TestCollectWithController(t *testing.T) {
+	tests := []struct {
+		name string
+		// beforeRun cancels the first account before the run, otherwise it is cancelled once its funding was mined
+		beforeRun     bool
+		reclaimNative bool
+		status        Status
+		reclaim       Status
+		// sent the transactions of the first account, its funding then its sweep or the reclaim of its native balance
+		sent  int
+		swept bool
+	}{
+		{name: "cancelled before its start", beforeRun: true, status: StatusCancelled},
+		{name: "cancelled once funded", status: StatusSuccess, sent: 2, swept: true},
+		{name: "cancelled once funded with reclaim", reclaimNative: true, status: StatusCancelled,
+			reclaim: StatusSuccess, sent: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			destination := newTestKeyProvider(t)
+			accounts := []SourceAccount{{KeyProvider: newTestKeyProvider(t), Token: testToken},
+				{KeyProvider: newTestKeyProvider(t), Token: testToken}}
+			controller := NewRunController()
+			cancelledAddress := *accounts[0].KeyProvider.GetAddress()
+			chain := cancellingChain{Chain: dobermanntest.NewChain(big.NewInt(1)), controller: controller}
+			if test.beforeRun {
+				controller.Cancel(cancelledAddress)
+			} else {
+				chain.account = cancelledAddress
+			}
+			chain.SetBalance(*destination.GetAddress(), big.NewInt(1e18))
+			for _, account := range accounts {
+				chain.SetTokenBalance(common.HexToAddress(testToken), *account.KeyProvider.GetAddress(), big.NewInt(100))
+			}
+			collector, err := NewEVMCollector(EVMCollectorConfig{
+				Client:              chain,
+				GasTracker:          dobermanntest.NewGasTracker(30, 90),
+				NonceProviderType:   NonceProviderTypeNetwork,
+				ReceiptPollInterval: time.Millisecond,
+				ReclaimNative:       test.reclaimNative,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer collector.Close()
+
+			results := collector.CollectWithController(context.Background(), controller,
+				DestinationAccount{KeyProvider: destination}, accounts)
+			if results[0].Status != test.status || results[0].ReclaimStatus != test.reclaim {
+				t.Fatalf("status %s reclaim %s, want %s reclaim %s (%s)", results[0].Status, results[0].ReclaimStatus,
+					test.status, test.reclaim, results[0].Message)
+			}
+			if test.status == StatusCancelled && results[0].Reason != ReasonCancelled {
+				t.Fatalf("reason %s, want %s", results[0].Reason, ReasonCancelled)
+			}
+			// the other account of the run is collected
+			if results[1].Status != StatusSuccess {
+				t.Fatalf("other account %s, want %s", results[1].Status, StatusSuccess)
+			}
+			sent := 0
+			for _, tx := range chain.Sent() {
+				from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if from == cancelledAddress || (tx.To() != nil && *tx.To() == cancelledAddress) {
+					sent++
+				}
+			}
+			if sent != test.sent {
+				t.Fatalf("%d transactions of the cancelled account, want %d", sent, test.sent)
+			}
+			want := int64(100)
+			if test.swept {
+				want = 200
+			}
+			if got := chain.TokenBalance(common.HexToAddress(testToken), *destination.GetAddress()); got.Int64() != want {
+				t.Fatalf("%s tokens collected, want %d", got, want)
+			}
+		})
+	}
+}
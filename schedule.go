@@ -0,0 +1,186 @@
+package dobermann
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule the schedule is neither a positive interval nor a valid cron expression
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// cronSearchLimit how far ahead a cron schedule is searched for its next run, e.g. "0 0 30 2 *" never runs
+const cronSearchLimit = 5
+
+// Schedule decides when the runs of a ScheduledCollector start
+type Schedule interface {
+	// Next returns the first start after the given time, the zero time when there is none
+	Next(after time.Time) time.Time
+}
+
+// Every returns a Schedule starting a run every interval, it panics when the interval is not positive
+func Every(interval time.Duration) Schedule {
+	if interval <= 0 {
+		panic("non-positive interval for Every")
+	}
+	return intervalSchedule(interval)
+}
+
+type intervalSchedule time.Duration
+
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+// ParseSchedule returns the Schedule of an interval, e.g. "15m", or of a cron expression, e.g. "0 3 * * *"
+func ParseSchedule(value string) (Schedule, error) {
+	if interval, err := time.ParseDuration(value); err == nil {
+		if interval <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSchedule, value)
+		}
+		return Every(interval), nil
+	}
+	return ParseCron(value)
+}
+
+// cronSchedule the allowed values of each field as bit sets
+type cronSchedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// anyDay whether the day of month or the day of week is a wildcard, otherwise a day matching either runs
+	anyDay bool
+}
+
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// ParseCron returns the Schedule of a standard five field cron expression: minute, hour, day of month, month
+// and day of week, where Sunday is 0 or 7. Fields accept *, values, ranges, steps and lists, e.g. "*/15 8-18 * * 1-5".
+// The runs are computed in the location of the time given to Next.
+func ParseCron(expr string) (Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%w: %q needs %d fields", ErrInvalidSchedule, expr, len(cronFields))
+	}
+	sets := make([]uint64, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %q: %s", ErrInvalidSchedule, cronFields[i].name, field, err)
+		}
+		sets[i] = set
+	}
+	dayOfWeek := sets[4]
+	if dayOfWeek&(1<<7) != 0 {
+		dayOfWeek = dayOfWeek&^(1<<7) | 1
+	}
+	return cronSchedule{
+		minute:     sets[0],
+		hour:       sets[1],
+		dayOfMonth: sets[2],
+		month:      sets[3],
+		dayOfWeek:  dayOfWeek,
+		anyDay:     strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(field string, min int, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		low, high := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			low, err = parseCronValue(lowPart, min, max)
+			if err != nil {
+				return 0, err
+			}
+			high, err = parseCronValue(highPart, min, max)
+			if err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			var err error
+			low, err = parseCronValue(rangePart, min, max)
+			if err != nil {
+				return 0, err
+			}
+			// a single value with a step runs from the value to the end of the range, like cron
+			if !hasStep {
+				high = low
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			set |= 1 << uint(value)
+		}
+	}
+	return set, nil
+}
+
+func parseCronValue(value string, min int, max int) (int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < min || parsed > max {
+		return 0, fmt.Errorf("value %q not in %d-%d", value, min, max)
+	}
+	return parsed, nil
+}
+
+func (s cronSchedule) Next(after time.Time) time.Time {
+	location := after.Location()
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute()+1, 0, 0, location)
+	limit := t.AddDate(cronSearchLimit, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.anyDay {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
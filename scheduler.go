@@ -0,0 +1,302 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const defaultRunHistorySize = 100
+
+var (
+	// ErrSchedulerStarted Start was called on a running ScheduledCollector
+	ErrSchedulerStarted = errors.New("scheduler already started")
+	// ErrRunInProgress the run was skipped because the previous one is still running
+	ErrRunInProgress = errors.New("previous run still in progress")
+)
+
+// OverlapPolicy decides what happens to a run due while the previous one is still running
+type OverlapPolicy string
+
+const (
+	// OverlapSkip skips the run, it is recorded in the history as skipped
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapQueue starts the run once the previous one finished, at most one run waits,
+	// the following ones are skipped
+	OverlapQueue OverlapPolicy = "queue"
+)
+
+// RunTrigger what started a run of a ScheduledCollector
+type RunTrigger string
+
+const (
+	RunTriggerSchedule RunTrigger = "schedule"
+	RunTriggerManual   RunTrigger = "manual"
+)
+
+// AccountFetcher returns the accounts collected by each run of a ScheduledCollector,
+// e.g. the deposit addresses which received tokens since the previous run
+type AccountFetcher interface {
+	Fetch(ctx context.Context) (DestinationAccount, []SourceAccount, error)
+}
+
+// AccountFetcherFunc adapts a function to an AccountFetcher
+type AccountFetcherFunc func(ctx context.Context) (DestinationAccount, []SourceAccount, error)
+
+func (f AccountFetcherFunc) Fetch(ctx context.Context) (DestinationAccount, []SourceAccount, error) {
+	return f(ctx)
+}
+
+// RunHandler is called with the results of each run which collected, e.g. to write its report
+type RunHandler func(ctx context.Context, run ScheduledRun, results []Result)
+
+// ScheduledRun the outcome of a run of a ScheduledCollector
+type ScheduledRun struct {
+	Trigger RunTrigger `json:"trigger"`
+	// ScheduledAt when the run was due, when it was requested for manual runs
+	ScheduledAt time.Time `json:"scheduledAt"`
+	// StartedAt and FinishedAt are nil for the skipped runs
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Skipped    bool       `json:"skipped,omitempty"`
+	// Error the accounts could not be fetched
+	Error   string  `json:"error,omitempty"`
+	Summary Summary `json:"summary"`
+}
+
+// SchedulerOption configures a ScheduledCollector
+type SchedulerOption func(s *ScheduledCollector)
+
+// WithSchedulerClock sets the clock the schedule is followed with, the real clock by default
+func WithSchedulerClock(clock Clock) SchedulerOption {
+	return func(s *ScheduledCollector) {
+		s.clock = clock
+	}
+}
+
+// WithOverlapPolicy sets what happens to a run due while the previous one is running, OverlapSkip by default
+func WithOverlapPolicy(policy OverlapPolicy) SchedulerOption {
+	return func(s *ScheduledCollector) {
+		s.overlap = policy
+	}
+}
+
+// WithRunHistory sets how many runs History returns, the latest 100 by default
+func WithRunHistory(size int) SchedulerOption {
+	return func(s *ScheduledCollector) {
+		s.historySize = size
+	}
+}
+
+// WithRunHandler sets the handler called with the results of each run
+func WithRunHandler(handler RunHandler) SchedulerOption {
+	return func(s *ScheduledCollector) {
+		s.onRun = handler
+	}
+}
+
+// ScheduledCollector runs Collect periodically in a long-lived process. All the runs share the same collector,
+// so that its caches, e.g. the chain ID and the fee quotes, stay warm between runs. Only one run collects
+// at a time, whether it was started by the schedule or by Trigger.
+type ScheduledCollector struct {
+	collector   Collector
+	fetcher     AccountFetcher
+	schedule    Schedule
+	clock       Clock
+	overlap     OverlapPolicy
+	historySize int
+	onRun       RunHandler
+
+	// slot is held by the collecting run
+	slot chan struct{}
+	wg   sync.WaitGroup
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	queued  bool
+	history []ScheduledRun
+}
+
+// NewScheduledCollector utility method to create a ScheduledCollector, which does not run until started
+func NewScheduledCollector(collector Collector, fetcher AccountFetcher, schedule Schedule,
+	options ...SchedulerOption) (*ScheduledCollector, error) {
+	if collector == nil || fetcher == nil || schedule == nil {
+		return nil, errors.New("collector, fetcher and schedule are required")
+	}
+	s := &ScheduledCollector{
+		collector:   collector,
+		fetcher:     fetcher,
+		schedule:    schedule,
+		clock:       realClock{},
+		overlap:     OverlapSkip,
+		historySize: defaultRunHistorySize,
+		slot:        make(chan struct{}, 1),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	switch s.overlap {
+	case OverlapSkip, OverlapQueue:
+	default:
+		return nil, fmt.Errorf("invalid overlap policy %s", s.overlap)
+	}
+	if s.historySize <= 0 {
+		s.historySize = defaultRunHistorySize
+	}
+	return s, nil
+}
+
+// Start follows the schedule in the background until Stop is called or the context is cancelled
+func (s *ScheduledCollector) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return ErrSchedulerStarted
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.wg.Add(1)
+	go s.loop(ctx)
+	return nil
+}
+
+// Stop stops following the schedule, cancels the scheduled run in progress and waits for it to finish.
+// Runs started by Trigger are cancelled through their own context. The collector is not closed.
+func (s *ScheduledCollector) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+}
+
+// Trigger runs a collection right away and returns once it finished, following the OverlapPolicy
+// when a run is in progress
+func (s *ScheduledCollector) Trigger(ctx context.Context) (ScheduledRun, error) {
+	return s.execute(ctx, RunTriggerManual, s.clock.Now())
+}
+
+// History returns the latest runs, oldest first
+func (s *ScheduledCollector) History() []ScheduledRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history := make([]ScheduledRun, len(s.history))
+	copy(history, s.history)
+	return history
+}
+
+func (s *ScheduledCollector) loop(ctx context.Context) {
+	defer s.wg.Done()
+	var last time.Time
+	for {
+		now := s.clock.Now()
+		// a clock behind the last run, e.g. a test clock, must not start the same run twice
+		if now.Before(last) {
+			now = last
+		}
+		next := s.schedule.Next(now)
+		if next.IsZero() {
+			log.Ctx(ctx).Error().Msg("schedule has no next run, stopping")
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(next.Sub(now)):
+		}
+		last = next
+
+		s.wg.Add(1)
+		go func(scheduledAt time.Time) {
+			defer s.wg.Done()
+			_, _ = s.execute(ctx, RunTriggerSchedule, scheduledAt)
+		}(next)
+	}
+}
+
+// execute collects once it holds the slot, the run is skipped when it can not get it
+func (s *ScheduledCollector) execute(ctx context.Context, trigger RunTrigger, scheduledAt time.Time) (ScheduledRun, error) {
+	run := ScheduledRun{
+		Trigger:     trigger,
+		ScheduledAt: scheduledAt,
+	}
+	err := s.acquire(ctx)
+	if err != nil {
+		if errors.Is(err, ErrRunInProgress) {
+			log.Ctx(ctx).Warn().Str("trigger", string(trigger)).Time("scheduledAt", scheduledAt).
+				Msg("previous run still in progress, skipping")
+			run.Skipped = true
+			s.record(run)
+		}
+		return run, err
+	}
+	defer func() { <-s.slot }()
+
+	run.StartedAt = timePointer(s.clock.Now())
+	destination, accounts, err := s.fetcher.Fetch(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to fetch the accounts")
+		run.Error = err.Error()
+		run.FinishedAt = timePointer(s.clock.Now())
+		s.record(run)
+		return run, err
+	}
+
+	results := s.collector.Collect(ctx, destination, accounts)
+	run.Summary = NewRunReport(results).Summary
+	run.FinishedAt = timePointer(s.clock.Now())
+	s.record(run)
+	if s.onRun != nil {
+		s.onRun(ctx, run, results)
+	}
+	return run, nil
+}
+
+// acquire takes the slot, waiting for the run in progress with OverlapQueue unless another run already waits
+func (s *ScheduledCollector) acquire(ctx context.Context) error {
+	select {
+	case s.slot <- struct{}{}:
+		return nil
+	default:
+	}
+	if s.overlap != OverlapQueue {
+		return ErrRunInProgress
+	}
+
+	s.mu.Lock()
+	if s.queued {
+		s.mu.Unlock()
+		return ErrRunInProgress
+	}
+	s.queued = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.queued = false
+		s.mu.Unlock()
+	}()
+
+	select {
+	case s.slot <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *ScheduledCollector) record(run ScheduledRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = append(s.history, run)
+	if len(s.history) > s.historySize {
+		s.history = s.history[len(s.history)-s.historySize:]
+	}
+}
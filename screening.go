@@ -0,0 +1,175 @@
+package dobermann
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrScreeningDenied the Screener denied an address of the collection
+	ErrScreeningDenied = errors.New("address denied by screening")
+	// ErrScreeningFailed the Screener could not check an address while the ScreeningFailurePolicy is fail-closed
+	ErrScreeningFailed = errors.New("address screening failed")
+)
+
+// ScreeningRole the role of a screened address in the collection
+type ScreeningRole string
+
+const (
+	// ScreeningRoleSource the address holding the collected tokens, i.e. the SourceAccount Wallet when set
+	ScreeningRoleSource ScreeningRole = "source"
+	// ScreeningRoleDestination the address of the DestinationAccount
+	ScreeningRoleDestination ScreeningRole = "destination"
+)
+
+// ScreeningDecision whether the tokens may be moved from or to an address
+type ScreeningDecision string
+
+const (
+	ScreeningAllow ScreeningDecision = "allow"
+	ScreeningDeny  ScreeningDecision = "deny"
+)
+
+// ScreeningFailurePolicy what happens to an account whose address could not be checked
+type ScreeningFailurePolicy string
+
+const (
+	// ScreeningFailClosed the account is failed with ReasonScreeningFailed, the default
+	ScreeningFailClosed ScreeningFailurePolicy = "fail_closed"
+	// ScreeningFailOpen the error is logged and the account is collected
+	ScreeningFailOpen ScreeningFailurePolicy = "fail_open"
+)
+
+// Screener checks the addresses of a collection, e.g. against a sanctions list
+type Screener interface {
+	Check(ctx context.Context, address common.Address, role ScreeningRole) (ScreeningDecision, error)
+}
+
+// Screening checks the destination once when a run starts and each source before any of its transactions.
+// A denied source ends with StatusDenied and ReasonScreeningDenied, a denied destination aborts the run, all the
+// accounts ending the same way. The decisions are cached for the run.
+type Screening struct {
+	// Screener checks the addresses, the screening is disabled when nil
+	Screener Screener
+	// FailurePolicy ScreeningFailClosed when empty
+	FailurePolicy ScreeningFailurePolicy
+}
+
+type screeningKey struct {
+	address common.Address
+	role    ScreeningRole
+}
+
+// runScreening the screening of a run with its cached decisions, it is not safe for concurrent use
+type runScreening struct {
+	Screening
+	decisions map[screeningKey]ScreeningDecision
+}
+
+// newRunScreening returns the screening of a run, nil when the screening is disabled
+func (c evmCollector) newRunScreening() *runScreening {
+	if c.screening.Screener == nil {
+		return nil
+	}
+	return &runScreening{Screening: c.screening, decisions: make(map[screeningKey]ScreeningDecision)}
+}
+
+// check returns ErrScreeningDenied when the address is denied, ErrScreeningFailed when it could not be checked and
+// the policy is fail-closed, nil otherwise and for a nil screening
+func (s *runScreening) check(ctx context.Context, address common.Address, role ScreeningRole) error {
+	if s == nil {
+		return nil
+	}
+	key := screeningKey{address: address, role: role}
+	decision, ok := s.decisions[key]
+	if !ok {
+		var err error
+		decision, err = s.Screener.Check(ctx, address, role)
+		if err != nil {
+			if s.FailurePolicy == ScreeningFailOpen {
+				log.Ctx(ctx).Warn().Err(err).Str("address", address.Hex()).Str("role", string(role)).
+					Msg("screening failed, failing open")
+				return nil
+			}
+			// the failures are not cached, the next account of the address checks it again
+			return fmt.Errorf("%w: %s %s: %w", ErrScreeningFailed, role, address.Hex(), err)
+		}
+		s.decisions[key] = decision
+	}
+	if decision == ScreeningDeny {
+		return fmt.Errorf("%w: %s %s", ErrScreeningDenied, role, address.Hex())
+	}
+	return nil
+}
+
+// checkSource screens the holder of the tokens of the account
+func (s *runScreening) checkSource(ctx context.Context, account SourceAccount) error {
+	return s.check(ctx, *tokenHolder(account), ScreeningRoleSource)
+}
+
+// screeningResult the result of an account whose screening failed or whose addresses were denied
+func screeningResult(ctx context.Context, account SourceAccount, err error) Result {
+	if !errors.Is(err, ErrScreeningDenied) {
+		return handleError(ctx, account, PhaseValidation, err)
+	}
+	result := getResult(ctx, account, StatusDenied, ReasonScreeningDenied)
+	result.Phase = PhaseValidation
+	result.Message = err.Error()
+	result.Err = err
+	return result
+}
+
+// StaticScreener denies a fixed list of addresses, whatever their role
+type StaticScreener struct {
+	denied map[common.Address]bool
+}
+
+// NewStaticScreener utility method to create a StaticScreener denying the given addresses
+func NewStaticScreener(denied ...common.Address) *StaticScreener {
+	s := &StaticScreener{denied: make(map[common.Address]bool, len(denied))}
+	for _, address := range denied {
+		s.denied[address] = true
+	}
+	return s
+}
+
+// NewFileScreener utility method to create a StaticScreener denying the addresses of the file, one per line.
+// The empty lines and the lines starting with # are ignored.
+func NewFileScreener(path string) (*StaticScreener, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	denied := make([]common.Address, 0)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if !common.IsHexAddress(text) {
+			return nil, fmt.Errorf("invalid address %q on line %d of %s", text, line, path)
+		}
+		denied = append(denied, common.HexToAddress(text))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewStaticScreener(denied...), nil
+}
+
+func (s *StaticScreener) Check(ctx context.Context, address common.Address, role ScreeningRole) (ScreeningDecision, error) {
+	if s.denied[address] {
+		return ScreeningDeny, nil
+	}
+	return ScreeningAllow, nil
+}
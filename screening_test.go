@@ -0,0 +1,182 @@
+package dobermann
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/welthee/dobermann/dobermanntest"
+)
+
+// fakeScreener denies the given addresses and fails for the failing ones, counting its checks
+type fakeScreener struct {
+	denied  map[common.Address]bool
+	failing map[common.Address]bool
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *fakeScreener) Check(ctx context.Context, address common.Address, role ScreeningRole) (ScreeningDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.failing[address] {
+		return "", errors.New("screening service unavailable")
+	}
+	if s.denied[address] {
+		return ScreeningDeny, nil
+	}
+	return ScreeningAllow, nil
+}
+
+func TestRunScreening(t *testing.T) {
+	address := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	tests := []struct {
+		name     string
+		screener *fakeScreener
+		policy   ScreeningFailurePolicy
+		want     error
+		// calls of the screener for two checks of the address
+		calls int
+	}{
+		{name: "allowed", screener: &fakeScreener{}, calls: 1},
+		{name: "denied", screener: &fakeScreener{denied: map[common.Address]bool{address: true}},
+			want: ErrScreeningDenied, calls: 1},
+		{name: "failing closed", screener: &fakeScreener{failing: map[common.Address]bool{address: true}},
+			want: ErrScreeningFailed, calls: 2},
+		{name: "failing open", screener: &fakeScreener{failing: map[common.Address]bool{address: true}},
+			policy: ScreeningFailOpen, calls: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := evmCollector{screening: Screening{Screener: test.screener, FailurePolicy: test.policy}}
+			screening := c.newRunScreening()
+			for i := 0; i < 2; i++ {
+				err := screening.check(context.Background(), address, ScreeningRoleSource)
+				if !errors.Is(err, test.want) || (test.want == nil && err != nil) {
+					t.Fatalf("check %d: error %v, want %v", i+1, err, test.want)
+				}
+			}
+			// the decisions are cached for the run, the failures are checked again
+			if test.screener.calls != test.calls {
+				t.Fatalf("%d screener calls, want %d", test.screener.calls, test.calls)
+			}
+		})
+	}
+
+	var disabled *runScreening
+	if screening := (evmCollector{}).newRunScreening(); screening != disabled {
+		t.Fatal("screening enabled without a screener")
+	}
+	if err := disabled.check(context.Background(), address, ScreeningRoleSource); err != nil {
+		t.Fatalf("disabled screening error %v", err)
+	}
+}
+
+func TestCollectScreening(t *testing.T) {
+	tests := []struct {
+		name string
+		// deniedDestination denies the destination, the sources being denied or failing by index
+		deniedDestination bool
+		denied            map[int]bool
+		failing           map[int]bool
+		policy            ScreeningFailurePolicy
+		want              []Status
+		reasons           []ReasonCode
+	}{
+		{name: "allowed", want: []Status{StatusSuccess, StatusSuccess}, reasons: []ReasonCode{"", ""}},
+		{name: "denied source", denied: map[int]bool{0: true}, want: []Status{StatusDenied, StatusSuccess},
+			reasons: []ReasonCode{ReasonScreeningDenied, ""}},
+		{name: "denied destination", deniedDestination: true, want: []Status{StatusDenied, StatusDenied},
+			reasons: []ReasonCode{ReasonScreeningDenied, ReasonScreeningDenied}},
+		{name: "failing closed", failing: map[int]bool{1: true}, want: []Status{StatusSuccess, StatusFail},
+			reasons: []ReasonCode{"", ReasonScreeningFailed}},
+		{name: "failing open", failing: map[int]bool{1: true}, policy: ScreeningFailOpen,
+			want: []Status{StatusSuccess, StatusSuccess}, reasons: []ReasonCode{"", ""}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			destination := newTestKeyProvider(t)
+			chain := dobermanntest.NewChain(big.NewInt(1))
+			chain.SetBalance(*destination.GetAddress(), big.NewInt(1e18))
+			screener := &fakeScreener{denied: map[common.Address]bool{*destination.GetAddress(): test.deniedDestination},
+				failing: make(map[common.Address]bool)}
+			accounts := make([]SourceAccount, 2)
+			for i := range accounts {
+				source := newTestKeyProvider(t)
+				accounts[i] = SourceAccount{KeyProvider: source, Token: testToken}
+				chain.SetTokenBalance(common.HexToAddress(testToken), *source.GetAddress(), big.NewInt(100))
+				screener.denied[*source.GetAddress()] = test.denied[i]
+				screener.failing[*source.GetAddress()] = test.failing[i]
+			}
+			collector, err := NewEVMCollector(EVMCollectorConfig{
+				Client:              chain,
+				GasTracker:          dobermanntest.NewGasTracker(30, 90),
+				NonceProviderType:   NonceProviderTypeNetwork,
+				ReceiptPollInterval: time.Millisecond,
+				Screening:           Screening{Screener: screener, FailurePolicy: test.policy},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer collector.Close()
+
+			results := collector.Collect(context.Background(), DestinationAccount{KeyProvider: destination}, accounts)
+			sweeps := 0
+			for i, result := range results {
+				if result.Status != test.want[i] || result.Reason != test.reasons[i] {
+					t.Fatalf("account %d: %s %s, want %s %s", i, result.Status, result.Reason, test.want[i], test.reasons[i])
+				}
+				if result.Status == StatusSuccess {
+					sweeps++
+				}
+			}
+			// nothing is sent from or to a denied address, the funding of each swept account and its sweep only
+			if got := len(chain.Sent()); got != 2*sweeps {
+				t.Fatalf("%d transactions sent, want %d", got, 2*sweeps)
+			}
+		})
+	}
+}
+
+func TestNewFileScreener(t *testing.T) {
+	denied := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "addresses", content: "# sanctions list\n\n" + denied.Hex() + "\n"},
+		{name: "lower case address", content: "  0x" + common.Bytes2Hex(denied.Bytes()) + "  \n"},
+		{name: "invalid address", content: denied.Hex() + "\nnot an address\n", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "denied.txt")
+			if err := os.WriteFile(path, []byte(test.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			screener, err := NewFileScreener(path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error %v, want error %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			for address, want := range map[common.Address]ScreeningDecision{denied: ScreeningDeny,
+				common.HexToAddress(testToken): ScreeningAllow} {
+				decision, err := screener.Check(context.Background(), address, ScreeningRoleDestination)
+				if err != nil || decision != want {
+					t.Fatalf("decision %s error %v for %s, want %s", decision, err, address.Hex(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package dobermann
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AccountSource yields the source accounts of a streamed run chunk by chunk, so that the accounts of a chunk,
+// their key providers included, are only built when the chunk begins
+type AccountSource interface {
+	// NextChunk returns the next accounts, none once all of them were returned
+	NextChunk(ctx context.Context) ([]SourceAccount, error)
+}
+
+// AccountSourceFunc adapts a function to an AccountSource
+type AccountSourceFunc func(ctx context.Context) ([]SourceAccount, error)
+
+func (f AccountSourceFunc) NextChunk(ctx context.Context) ([]SourceAccount, error) {
+	return f(ctx)
+}
+
+// AccountBuilder builds the source account with the given index, e.g. creating its key provider
+type AccountBuilder func(ctx context.Context, index int) (SourceAccount, error)
+
+type chunkedAccountSource struct {
+	total     int
+	chunkSize int
+	build     AccountBuilder
+	next      int
+}
+
+// NewChunkedAccountSource returns the total accounts in chunks of chunkSize, building the accounts of each chunk
+// only when it is read. The source is not safe for concurrent use.
+func NewChunkedAccountSource(total int, chunkSize int, build AccountBuilder) AccountSource {
+	if chunkSize <= 0 {
+		chunkSize = total
+	}
+	return &chunkedAccountSource{total: total, chunkSize: chunkSize, build: build}
+}
+
+func (s *chunkedAccountSource) NextChunk(ctx context.Context) ([]SourceAccount, error) {
+	end := s.next + s.chunkSize
+	if end > s.total {
+		end = s.total
+	}
+	accounts := make([]SourceAccount, 0, end-s.next)
+	for i := s.next; i < end; i++ {
+		account, err := s.build(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	s.next = end
+	return accounts, nil
+}
+
+// ResultSink receives the results of a streamed run once each chunk was collected
+type ResultSink interface {
+	Write(ctx context.Context, results []Result) error
+}
+
+// ResultSinkFunc adapts a function to a ResultSink
+type ResultSinkFunc func(ctx context.Context, results []Result) error
+
+func (f ResultSinkFunc) Write(ctx context.Context, results []Result) error {
+	return f(ctx, results)
+}
+
+type jsonLinesSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONLinesSink writes every result as a ReportEntry on its own line, e.g. to a file kept open for the run
+func NewJSONLinesSink(w io.Writer) ResultSink {
+	return &jsonLinesSink{encoder: json.NewEncoder(w)}
+}
+
+func (s *jsonLinesSink) Write(ctx context.Context, results []Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, result := range results {
+		err := s.encoder.Encode(newReportEntry(result))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CollectStream collects the accounts of the source one chunk after the other, handing the results of each chunk
+// to the sink before the next chunk is read. Only the summary is kept, so the memory is bounded by the size of the
+// chunks. The returned report has no Results, and is returned with what was collected until then when the source,
+// the sink or the context fails.
+func (c evmCollector) CollectStream(ctx context.Context, destinationAccount DestinationAccount, source AccountSource, sink ResultSink) (RunReport, error) {
+	report := newRunReport()
+	for chunk := 0; ; chunk++ {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		accounts, err := source.NextChunk(ctx)
+		if err != nil {
+			return report, err
+		}
+		if len(accounts) == 0 {
+			return report, nil
+		}
+
+		results := c.Collect(ctx, destinationAccount, accounts)
+		for _, result := range results {
+			report.add(result)
+		}
+		err = sink.Write(ctx, results)
+		if err != nil {
+			return report, err
+		}
+		log.Ctx(ctx).Debug().
+			Int("chunk", chunk).
+			Int("accounts", len(accounts)).
+			Int("total", report.Summary.Total).
+			Msg("chunk collected")
+	}
+}
@@ -0,0 +1,70 @@
+package dobermann
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CheckArg an argument of an EligibilityCheck call
+type CheckArg string
+
+const (
+	// CheckArgDestination the destination address
+	CheckArgDestination CheckArg = "destination"
+	// CheckArgSource the address holding the tokens
+	CheckArgSource CheckArg = "source"
+)
+
+// TokenPolicy the checks made for the accounts of a token, see EVMCollectorConfig.TokenPolicies
+type TokenPolicy struct {
+	// DestinationCheck is called before the account is funded, disabled when its Signature is empty
+	DestinationCheck EligibilityCheck
+}
+
+// EligibilityCheck a view method of the token, e.g. isWhitelisted(address), telling whether the tokens
+// may be transferred. A false result or a revert skips the account with StatusDestinationNotEligible.
+type EligibilityCheck struct {
+	// Signature of the method, e.g. "isWhitelisted(address)", every argument has to be an address
+	Signature string
+	// Args the addresses passed to the method, only the destination when empty
+	Args []CheckArg
+}
+
+// normalizeTokenPolicies validates the policies and returns them keyed by the lowercase token address
+func normalizeTokenPolicies(policies map[string]TokenPolicy) (map[string]TokenPolicy, error) {
+	normalized := make(map[string]TokenPolicy, len(policies))
+	for token, policy := range policies {
+		for _, arg := range policy.DestinationCheck.Args {
+			if arg != CheckArgDestination && arg != CheckArgSource {
+				return nil, fmt.Errorf("invalid token policy %s: unknown check argument %s", token, arg)
+			}
+		}
+		normalized[strings.ToLower(token)] = policy
+	}
+	return normalized, nil
+}
+
+// isDestinationEligible runs the DestinationCheck of the account token, true when there is none
+func (c evmCollector) isDestinationEligible(ctx context.Context, account SourceAccount, holder common.Address, destination common.Address) (bool, error) {
+	check := c.tokenPolicies[strings.ToLower(account.Token)].DestinationCheck
+	if check.Signature == "" {
+		return true, nil
+	}
+
+	args := make([]common.Address, 0, len(check.Args))
+	for _, arg := range check.Args {
+		switch arg {
+		case CheckArgSource:
+			args = append(args, holder)
+		default:
+			args = append(args, destination)
+		}
+	}
+	if len(check.Args) == 0 {
+		args = append(args, destination)
+	}
+	return c.transactor.CallAddressCheck(ctx, common.HexToAddress(account.Token), check.Signature, args...)
+}
@@ -0,0 +1,58 @@
+package tokens
+
+import "encoding/hex"
+
+// Selector the first 4 bytes of the keccak256 hash of a method signature, which select the method in the call data
+type Selector [4]byte
+
+// Hex returns the selector with the 0x prefix, e.g. 0xa9059cbb
+func (s Selector) Hex() string {
+	return "0x" + hex.EncodeToString(s[:])
+}
+
+// The selectors of the ERC-20 methods
+var (
+	// SelectorTransfer transfer(address,uint256)
+	SelectorTransfer = Selector{0xa9, 0x05, 0x9c, 0xbb}
+	// SelectorTransferFrom transferFrom(address,address,uint256)
+	SelectorTransferFrom = Selector{0x23, 0xb8, 0x72, 0xdd}
+	// SelectorApprove approve(address,uint256)
+	SelectorApprove = Selector{0x09, 0x5e, 0xa7, 0xb3}
+	// SelectorBalanceOf balanceOf(address)
+	SelectorBalanceOf = Selector{0x70, 0xa0, 0x82, 0x31}
+	// SelectorAllowance allowance(address,address)
+	SelectorAllowance = Selector{0xdd, 0x62, 0xed, 0x3e}
+	// SelectorDecimals decimals()
+	SelectorDecimals = Selector{0x31, 0x3c, 0xe5, 0x67}
+)
+
+// The selectors of the EIP-2612 methods
+var (
+	// SelectorPermit permit(address,address,uint256,uint256,uint8,bytes32,bytes32)
+	SelectorPermit = Selector{0xd5, 0x05, 0xac, 0xcf}
+	// SelectorNonces nonces(address)
+	SelectorNonces = Selector{0x7e, 0xce, 0xbe, 0x00}
+	// SelectorDomainSeparator DOMAIN_SEPARATOR()
+	SelectorDomainSeparator = Selector{0x36, 0x44, 0xe5, 0x15}
+)
+
+// The selectors of the ERC-721 methods
+var (
+	// SelectorSafeTransferFrom safeTransferFrom(address,address,uint256)
+	SelectorSafeTransferFrom = Selector{0x42, 0x84, 0x2e, 0x0e}
+	// SelectorOwnerOf ownerOf(uint256)
+	SelectorOwnerOf = Selector{0x63, 0x52, 0x21, 0x1e}
+	// SelectorTokenOfOwnerByIndex tokenOfOwnerByIndex(address,uint256)
+	SelectorTokenOfOwnerByIndex = Selector{0x2f, 0x74, 0x5c, 0x59}
+	// SelectorSupportsInterface supportsInterface(bytes4) of ERC-165
+	SelectorSupportsInterface = Selector{0x01, 0xff, 0xc9, 0xa7}
+)
+
+// The selectors of the ERC-1155 methods
+var (
+	// SelectorSafeBatchTransferFrom safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)
+	SelectorSafeBatchTransferFrom = Selector{0x2e, 0xb2, 0xc2, 0xd6}
+)
+
+// InterfaceERC721Enumerable the ERC-165 interface id of the ERC-721 enumeration extension
+var InterfaceERC721Enumerable = Selector{0x78, 0x0e, 0x9d, 0x63}
@@ -0,0 +1,118 @@
+// Package tokens the addresses of well-known tokens per chain and the canonical ERC-20 selectors, so that they
+// are not declared again in every configuration
+package tokens
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The IDs of the chains with known tokens
+const (
+	ChainIDEthereum uint64 = 1
+	ChainIDPolygon  uint64 = 137
+)
+
+// Token a well-known token deployed on a chain
+type Token struct {
+	ChainID    uint64
+	Symbol     string
+	Address    common.Address
+	Decimals   uint8
+	Stablecoin bool
+}
+
+// The well-known tokens. The addresses are written with their EIP-55 checksum, which is verified when the
+// package is loaded.
+var (
+	EthereumUSDC = newToken(ChainIDEthereum, "USDC", "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", 6, true)
+	EthereumUSDT = newToken(ChainIDEthereum, "USDT", "0xdAC17F958D2ee523a2206206994597C13D831ec7", 6, true)
+	EthereumDAI  = newToken(ChainIDEthereum, "DAI", "0x6B175474E89094C44Da98b954EedeAC495271d0F", 18, true)
+	EthereumWETH = newToken(ChainIDEthereum, "WETH", "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", 18, false)
+
+	// PolygonUSDC the native USDC issued by Circle
+	PolygonUSDC = newToken(ChainIDPolygon, "USDC", "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359", 6, true)
+	// PolygonUSDCe the USDC bridged from Ethereum
+	PolygonUSDCe  = newToken(ChainIDPolygon, "USDC.e", "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", 6, true)
+	PolygonUSDT   = newToken(ChainIDPolygon, "USDT", "0xc2132D05D31c914a87C6611C10748AEb04B58e8F", 6, true)
+	PolygonDAI    = newToken(ChainIDPolygon, "DAI", "0x8f3Cf7ad23Cd3CaDbD9735AFf958023239c6A063", 18, true)
+	PolygonWETH   = newToken(ChainIDPolygon, "WETH", "0x7ceB23fD6bC0adD59E62ac25578270cFf1b9f619", 18, false)
+	PolygonWMATIC = newToken(ChainIDPolygon, "WMATIC", "0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270", 18, false)
+)
+
+// known the tokens by chain ID and address
+var known = index(
+	EthereumUSDC, EthereumUSDT, EthereumDAI, EthereumWETH,
+	PolygonUSDC, PolygonUSDCe, PolygonUSDT, PolygonDAI, PolygonWETH, PolygonWMATIC,
+)
+
+// newToken panics when the address is not written with its checksum, so that a mistyped address never loads
+func newToken(chainID uint64, symbol string, address string, decimals uint8, stablecoin bool) Token {
+	if !common.IsHexAddress(address) || common.HexToAddress(address).Hex() != address {
+		panic(fmt.Sprintf("tokens: invalid checksum address %s of %s on chain %d", address, symbol, chainID))
+	}
+	return Token{
+		ChainID:    chainID,
+		Symbol:     symbol,
+		Address:    common.HexToAddress(address),
+		Decimals:   decimals,
+		Stablecoin: stablecoin,
+	}
+}
+
+// index panics when two tokens of the same chain share an address or a symbol
+func index(tokens ...Token) map[uint64]map[common.Address]Token {
+	chains := make(map[uint64]map[common.Address]Token)
+	symbols := make(map[uint64]map[string]bool)
+	for _, token := range tokens {
+		if chains[token.ChainID] == nil {
+			chains[token.ChainID] = make(map[common.Address]Token)
+			symbols[token.ChainID] = make(map[string]bool)
+		}
+		symbol := strings.ToUpper(token.Symbol)
+		if _, ok := chains[token.ChainID][token.Address]; ok || symbols[token.ChainID][symbol] {
+			panic(fmt.Sprintf("tokens: %s %s declared twice on chain %d", token.Symbol, token.Address.Hex(), token.ChainID))
+		}
+		chains[token.ChainID][token.Address] = token
+		symbols[token.ChainID][symbol] = true
+	}
+	return chains
+}
+
+// Lookup returns the known token at the address of the chain
+func Lookup(chainID uint64, address common.Address) (Token, bool) {
+	token, ok := known[chainID][address]
+	return token, ok
+}
+
+// BySymbol returns the known token of the chain with the symbol, compared case-insensitively
+func BySymbol(chainID uint64, symbol string) (Token, bool) {
+	for _, token := range known[chainID] {
+		if strings.EqualFold(token.Symbol, symbol) {
+			return token, true
+		}
+	}
+	return Token{}, false
+}
+
+// ForChain returns the known tokens of the chain sorted by symbol, none for unknown chains
+func ForChain(chainID uint64) []Token {
+	tokens := make([]Token, 0, len(known[chainID]))
+	for _, token := range known[chainID] {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].Symbol < tokens[j].Symbol
+	})
+	return tokens
+}
+
+// IsKnownStablecoin reports whether the address is a known stablecoin of the chain. The same address on another
+// chain is not a stablecoin, as it can hold any other contract there.
+func IsKnownStablecoin(chainID uint64, address common.Address) bool {
+	token, ok := Lookup(chainID, address)
+	return ok && token.Stablecoin
+}
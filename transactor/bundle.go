@@ -0,0 +1,79 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/welthee/dobermann/internal/redact"
+)
+
+// methodNotFound the JSON-RPC error code of the endpoints which do not know eth_sendBundle
+const methodNotFound = -32601
+
+// ErrBundleUnsupported no BundleSubmitter is configured or the endpoint does not accept bundles
+var ErrBundleUnsupported = errors.New("bundle submission not supported")
+
+// BundleSubmitter submits transactions to be included in order in the given block, all of them or none
+type BundleSubmitter interface {
+	SendBundle(ctx context.Context, txs []*types.Transaction, blockNumber uint64) error
+}
+
+type rpcBundleSubmitter struct {
+	client *rpc.Client
+}
+
+// bundleParams the eth_sendBundle parameters shared by the Flashbots relay and most private builder endpoints
+type bundleParams struct {
+	Txs         []string `json:"txs"`
+	BlockNumber string   `json:"blockNumber"`
+}
+
+// NewRPCBundleSubmitter utility method to create a BundleSubmitter calling eth_sendBundle on the given endpoint
+func NewRPCBundleSubmitter(url string) (BundleSubmitter, error) {
+	client, err := rpc.Dial(url)
+	if err != nil {
+		return nil, redact.Error(err)
+	}
+	return rpcBundleSubmitter{client: client}, nil
+}
+
+func (s rpcBundleSubmitter) SendBundle(ctx context.Context, txs []*types.Transaction, blockNumber uint64) error {
+	params := bundleParams{
+		Txs:         make([]string, 0, len(txs)),
+		BlockNumber: hexutil.EncodeUint64(blockNumber),
+	}
+	for _, tx := range txs {
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		params.Txs = append(params.Txs, hexutil.Encode(raw))
+	}
+
+	var result interface{}
+	err := s.client.CallContext(ctx, &result, "eth_sendBundle", params)
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFound {
+		return fmt.Errorf("%w: %v", ErrBundleUnsupported, err)
+	}
+	return redact.Error(err)
+}
+
+func (t evmTransactor) TransferBundle(ctx context.Context, transactions []*types.Transaction, blockNumber uint64) error {
+	if t.bundleSubmitter == nil {
+		return ErrBundleUnsupported
+	}
+	if t.preBroadcast != nil {
+		for _, transaction := range transactions {
+			err := t.preBroadcast(ctx, transaction)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrBroadcastVetoed, err)
+			}
+		}
+	}
+	return t.bundleSubmitter.SendBundle(ctx, transactions, blockNumber)
+}
@@ -0,0 +1,147 @@
+package transactor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// bundleRequest an eth_sendBundle request received by the stub endpoint
+type bundleRequest struct {
+	Method string         `json:"method"`
+	Params []bundleParams `json:"params"`
+}
+
+// newBundleEndpoint returns an endpoint recording the bundle requests and answering them with the JSON-RPC error,
+// or with a bundle hash when nil
+func newBundleEndpoint(t *testing.T, rpcErr map[string]interface{}, requests *[]bundleRequest) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			bundleRequest
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		*requests = append(*requests, request.bundleRequest)
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": request.ID}
+		if rpcErr != nil {
+			response["error"] = rpcErr
+		} else {
+			response["result"] = map[string]string{"bundleHash": "0x01"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newBundleTxs(t *testing.T) []*types.Transaction {
+	t.Helper()
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.NewLondonSigner(big.NewInt(137))
+	txs := make([]*types.Transaction, 2)
+	for i := range txs {
+		txs[i], err = types.SignNewTx(privateKey, signer, &types.DynamicFeeTx{ChainID: big.NewInt(137),
+			Nonce: uint64(i), GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21_000})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return txs
+}
+
+func TestRPCBundleSubmitter(t *testing.T) {
+	tests := []struct {
+		name            string
+		rpcErr          map[string]interface{}
+		wantErr         bool
+		wantUnsupported bool
+	}{
+		{name: "submitted"},
+		{name: "method not found", rpcErr: map[string]interface{}{"code": methodNotFound, "message": "the method eth_sendBundle does not exist"},
+			wantErr: true, wantUnsupported: true},
+		{name: "refused", rpcErr: map[string]interface{}{"code": -32000, "message": "bundle already submitted"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests []bundleRequest
+			endpoint := newBundleEndpoint(t, test.rpcErr, &requests)
+			submitter, err := NewRPCBundleSubmitter(endpoint.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			txs := newBundleTxs(t)
+
+			err = submitter.SendBundle(context.Background(), txs, 42)
+			if (err != nil) != test.wantErr || errors.Is(err, ErrBundleUnsupported) != test.wantUnsupported {
+				t.Fatalf("error %v, want error %v, unsupported %v", err, test.wantErr, test.wantUnsupported)
+			}
+			// the transactions are submitted in order, encoded like eth_sendRawTransaction, for the block in hex
+			if len(requests) != 1 || requests[0].Method != "eth_sendBundle" || len(requests[0].Params) != 1 {
+				t.Fatalf("requests %+v, want a single eth_sendBundle", requests)
+			}
+			params := requests[0].Params[0]
+			if params.BlockNumber != "0x2a" || len(params.Txs) != len(txs) {
+				t.Fatalf("params %+v, want %d transactions for block 0x2a", params, len(txs))
+			}
+			for i, tx := range txs {
+				raw, err := tx.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if params.Txs[i] != hexutil.Encode(raw) {
+					t.Fatalf("transaction %d %s, want %s", i, params.Txs[i], hexutil.Encode(raw))
+				}
+			}
+		})
+	}
+}
+
+func TestTransferBundle(t *testing.T) {
+	var requests []bundleRequest
+	endpoint := newBundleEndpoint(t, nil, &requests)
+	submitter, err := NewRPCBundleSubmitter(endpoint.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	veto := errors.New("sanctioned destination")
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		want     error
+		requests int
+	}{
+		{name: "submitted", opts: []Option{WithBundleSubmitter(submitter)}, requests: 1},
+		{name: "no submitter", want: ErrBundleUnsupported},
+		{name: "vetoed", want: ErrBroadcastVetoed, opts: []Option{WithBundleSubmitter(submitter),
+			WithPreBroadcast(func(ctx context.Context, tx *types.Transaction) error { return veto })}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests = nil
+			tr := newTestTransactor(t, newFakeClient(), test.opts...)
+			err := tr.TransferBundle(context.Background(), newBundleTxs(t), 42)
+			if !errors.Is(err, test.want) || (test.want == nil && err != nil) {
+				t.Fatalf("error %v, want %v", err, test.want)
+			}
+			if len(requests) != test.requests {
+				t.Fatalf("%d bundles submitted, want %d", len(requests), test.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/client"
+)
+
+const defaultPollInterval = 10 * time.Second
+
+// ConfirmationStrategy defines how the transactor waits for a transaction to be confirmed,
+// it must be safe for concurrent use
+type ConfirmationStrategy interface {
+	// WaitConfirmed blocks until the transaction with the given hash is confirmed and returns its receipt,
+	// or until the context is done
+	WaitConfirmed(ctx context.Context, txHash string) (*types.Receipt, error)
+}
+
+// ReceiptBackoff the intervals the receipts are polled at, starting at Initial and growing by Multiplier after each
+// poll not finding the receipt, up to Max. The zero value polls every 10 seconds.
+type ReceiptBackoff struct {
+	// Initial the first interval, 10 seconds when zero
+	Initial time.Duration
+	// Multiplier applied to the interval after each poll, 1 when below 1, e.g. 2 to double it
+	Multiplier float64
+	// Max the longest interval, 10 seconds when zero and never below Initial
+	Max time.Duration
+}
+
+func (b ReceiptBackoff) withDefaults() ReceiptBackoff {
+	if b.Initial <= 0 {
+		b.Initial = defaultPollInterval
+	}
+	if b.Multiplier < 1 {
+		b.Multiplier = 1
+	}
+	if b.Max <= 0 {
+		b.Max = defaultPollInterval
+	}
+	if b.Max < b.Initial {
+		b.Max = b.Initial
+	}
+	return b
+}
+
+// next returns the interval following the given one
+func (b ReceiptBackoff) next(interval time.Duration) time.Duration {
+	next := time.Duration(float64(interval) * b.Multiplier)
+	if next > b.Max || next <= 0 {
+		return b.Max
+	}
+	return next
+}
+
+type pollingConfirmationStrategy struct {
+	client  client.Client
+	backoff ReceiptBackoff
+}
+
+// NewPollingConfirmationStrategy utility method to create a ConfirmationStrategy which polls
+// the node for the transaction receipt at the given interval, 10 seconds when zero
+func NewPollingConfirmationStrategy(client client.Client, pollInterval time.Duration) ConfirmationStrategy {
+	return NewBackoffConfirmationStrategy(client, ReceiptBackoff{Initial: pollInterval})
+}
+
+// NewBackoffConfirmationStrategy utility method to create a ConfirmationStrategy which polls
+// the node for the transaction receipt at the intervals of the backoff
+func NewBackoffConfirmationStrategy(client client.Client, backoff ReceiptBackoff) ConfirmationStrategy {
+	return pollingConfirmationStrategy{
+		client:  client,
+		backoff: backoff.withDefaults(),
+	}
+}
+
+func (p pollingConfirmationStrategy) WaitConfirmed(ctx context.Context, txHash string) (*types.Receipt, error) {
+	if txHash == "" {
+		return nil, errors.New("tx is empty")
+	}
+
+	interval := p.backoff.Initial
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		receipt, err := p.client.TransactionReceipt(ctx, common.HexToHash(txHash))
+		if receipt != nil {
+			log.Ctx(ctx).Debug().Msgf("found transaction receipt for tx=%s: status=%d", txHash, receipt.Status)
+			return receipt, nil
+		}
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("tx", txHash).Msg("failed to get receipt for tx")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Ctx(ctx).Warn().Err(ctx.Err()).Str("tx", txHash).Msg("failed to get receipt status")
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		interval = p.backoff.next(interval)
+		timer.Reset(interval)
+	}
+}
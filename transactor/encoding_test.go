@@ -0,0 +1,204 @@
+package transactor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// referenceERC20ABI the ERC-20 functions as specified by EIP-20, independent of the generated binding
+const referenceERC20ABI = `[
+{"name":"transfer","type":"function","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"type":"bool"}]},
+{"name":"transferFrom","type":"function","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"type":"bool"}]},
+{"name":"approve","type":"function","inputs":[{"name":"spender","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"type":"bool"}]}]`
+
+var referenceERC20 = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(referenceERC20ABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// checkERC20Calldata checks the calldata of the method against the reference ABI encoding, and that decoding it
+// gives back the arguments
+func checkERC20Calldata(t *testing.T, method string, data []byte, value *big.Int, addresses ...common.Address) {
+	t.Helper()
+	args := make([]interface{}, 0, len(addresses)+1)
+	for _, address := range addresses {
+		args = append(args, address)
+	}
+	args = append(args, value)
+	want, err := referenceERC20.Pack(method, args...)
+	if err != nil {
+		t.Fatalf("%s: reference pack: %v", method, err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("%s: calldata %x, want %x", method, data, want)
+	}
+
+	decoded, err := referenceERC20.Methods[method].Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatalf("%s: unpack: %v", method, err)
+	}
+	for i, address := range addresses {
+		if decoded[i].(common.Address) != address {
+			t.Fatalf("%s: decoded address %d %s, want %s", method, i, decoded[i], address)
+		}
+	}
+	if decoded[len(addresses)].(*big.Int).Cmp(value) != 0 {
+		t.Fatalf("%s: decoded value %s, want %s", method, decoded[len(addresses)], value)
+	}
+}
+
+func FuzzERC20Calldata(f *testing.F) {
+	f.Add([]byte{0xaa}, []byte{0xbb}, "0")
+	f.Add(bytes.Repeat([]byte{0xff}, 20), bytes.Repeat([]byte{0x01}, 20), "1000000000000000000")
+	f.Add([]byte{}, []byte{}, MaxUint256.String())
+	f.Add([]byte{0x01}, []byte{0x02}, new(big.Int).Add(MaxUint256, big.NewInt(1)).String())
+	f.Add([]byte{0x01}, []byte{0x02}, "-1")
+	f.Add([]byte{0x01}, []byte{0x02}, "+1")
+	f.Add([]byte{0x01}, []byte{0x02}, "1e18")
+	f.Fuzz(func(t *testing.T, first []byte, second []byte, amount string) {
+		owner := common.BytesToAddress(first)
+		to := common.BytesToAddress(second)
+
+		transfer, transferErr := ERC20TransferData(to, amount)
+		transferFrom, transferFromErr := ERC20TransferFromData(owner, to, amount)
+		approve, approveErr := ERC20ApproveData(to, amount)
+
+		value, err := ParseUint256(amount)
+		if err != nil {
+			if transferErr == nil || transferFromErr == nil || approveErr == nil {
+				t.Fatalf("invalid amount %q packed", amount)
+			}
+			return
+		}
+		if transferErr != nil || transferFromErr != nil || approveErr != nil {
+			t.Fatalf("amount %q: %v, %v, %v", amount, transferErr, transferFromErr, approveErr)
+		}
+		checkERC20Calldata(t, "transfer", transfer, value, to)
+		checkERC20Calldata(t, "transferFrom", transferFrom, value, owner, to)
+		checkERC20Calldata(t, "approve", approve, value, to)
+	})
+}
+
+func TestERC20CalldataSnapshot(t *testing.T) {
+	owner := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	tests := []struct {
+		name string
+		data func() ([]byte, error)
+		want string
+	}{
+		{
+			name: "transfer",
+			data: func() ([]byte, error) { return ERC20TransferData(to, "1000000") },
+			want: "a9059cbb" +
+				"0000000000000000000000002222222222222222222222222222222222222222" +
+				"00000000000000000000000000000000000000000000000000000000000f4240",
+		},
+		{
+			name: "transferFrom",
+			data: func() ([]byte, error) { return ERC20TransferFromData(owner, to, "1000000") },
+			want: "23b872dd" +
+				"0000000000000000000000001111111111111111111111111111111111111111" +
+				"0000000000000000000000002222222222222222222222222222222222222222" +
+				"00000000000000000000000000000000000000000000000000000000000f4240",
+		},
+		{
+			name: "approve max",
+			data: func() ([]byte, error) { return ERC20ApproveData(to, MaxUint256.String()) },
+			want: "095ea7b3" +
+				"0000000000000000000000002222222222222222222222222222222222222222" +
+				"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.data()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(data); got != test.want {
+				t.Fatalf("calldata %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPermitDigestSnapshot(t *testing.T) {
+	owner := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	spender := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	token := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	value := big.NewInt(1000000)
+	nonce := big.NewInt(7)
+	deadline := big.NewInt(1700000000)
+
+	// the reference digest is computed by the EIP-712 implementation of go-ethereum
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "USD Coin",
+			Version:           "2",
+			ChainId:           math.NewHexOrDecimal256(137),
+			VerifyingContract: token.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"owner":    owner.Hex(),
+			"spender":  spender.Hex(),
+			"value":    value.String(),
+			"nonce":    nonce.String(),
+			"deadline": deadline.String(),
+		},
+	}
+	reference, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := common.BytesToHash(reference)
+	// the token returns its DOMAIN_SEPARATOR
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valueWord, err := uint256Word(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonceWord, err := uint256Word(nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := permitDigest(domainSeparator, owner, spender, valueWord, nonceWord, deadline)
+	if digest != want {
+		t.Fatalf("digest %s, want %s", digest.Hex(), want.Hex())
+	}
+	const pinned = "0xfa3d6103821bb483a1d52d771bfcd19627199660b39899bce69643b59cb62d68"
+	if digest.Hex() != pinned {
+		t.Fatalf("digest %s, pinned %s", digest.Hex(), pinned)
+	}
+}
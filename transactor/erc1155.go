@@ -0,0 +1,76 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// erc1155ABI the safeBatchTransferFrom function of ERC-1155
+const erc1155ABI = `[{"name":"safeBatchTransferFrom","type":"function","stateMutability":"nonpayable","inputs":[
+{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"ids","type":"uint256[]"},
+{"name":"amounts","type":"uint256[]"},{"name":"data","type":"bytes"}],"outputs":[]}]`
+
+var erc1155 = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(erc1155ABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// ErrMissingTokenAmounts the ERC-1155 transfer has no TokenAmounts
+var ErrMissingTokenAmounts = errors.New("token amounts not set")
+
+func (t evmTransactor) CreateERC1155BatchTx(ctx context.Context, params TxParams) (*types.Transaction, error) {
+	from, err := getSenderAddress(params)
+	if err != nil {
+		return nil, err
+	}
+	if params.Wallet != nil {
+		from = params.Wallet
+	}
+	receiverAddress, err := getReceiverAddress(params)
+	if err != nil {
+		return nil, err
+	}
+	ids, amounts, err := splitTokenAmounts(params.TokenAmounts)
+	if err != nil {
+		return nil, err
+	}
+	data, err := erc1155.Pack("safeBatchTransferFrom", *from, *receiverAddress, ids, amounts, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	return t.createERC20Call(ctx, params, data)
+}
+
+// splitTokenAmounts returns the ids and the amounts of the pairs, which all have to be valid uint256
+func splitTokenAmounts(tokenAmounts []TokenAmount) ([]*big.Int, []*big.Int, error) {
+	if len(tokenAmounts) == 0 {
+		return nil, nil, ErrMissingTokenAmounts
+	}
+	ids := make([]*big.Int, len(tokenAmounts))
+	amounts := make([]*big.Int, len(tokenAmounts))
+	for i, tokenAmount := range tokenAmounts {
+		if tokenAmount.ID == nil || tokenAmount.Amount == nil {
+			return nil, nil, fmt.Errorf("%w: id or amount %d not set", ErrMissingTokenAmounts, i)
+		}
+		err := CheckUint256(tokenAmount.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = CheckUint256(tokenAmount.Amount)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids[i] = tokenAmount.ID
+		amounts[i] = tokenAmount.Amount
+	}
+	return ids, amounts, nil
+}
@@ -0,0 +1,66 @@
+package transactor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ierc20 the ABI of the generated IERC20 binding, which packs the calldata of the token calls
+var ierc20 = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(IERC20MetaData.ABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// ERC20TransferData packs the transfer of the decimal wei amount to the receiver
+func ERC20TransferData(to common.Address, amount string) ([]byte, error) {
+	return packERC20("transfer", amount, to)
+}
+
+// ERC20TransferFromData packs the transferFrom of the decimal wei amount of the owner to the receiver
+func ERC20TransferFromData(owner common.Address, to common.Address, amount string) ([]byte, error) {
+	return packERC20("transferFrom", amount, owner, to)
+}
+
+// ERC20ApproveData packs the approve of the decimal wei amount to the spender
+func ERC20ApproveData(spender common.Address, amount string) ([]byte, error) {
+	return packERC20("approve", amount, spender)
+}
+
+// ErrNotERC20Transfer the calldata is not the one of an ERC-20 transfer
+var ErrNotERC20Transfer = errors.New("not an ERC-20 transfer")
+
+// DecodeERC20Transfer returns the receiver and the wei amount of the calldata of an ERC-20 transfer
+func DecodeERC20Transfer(data []byte) (common.Address, *big.Int, error) {
+	method := ierc20.Methods["transfer"]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return common.Address{}, nil, ErrNotERC20Transfer
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("%w: %v", ErrNotERC20Transfer, err)
+	}
+	return args[0].(common.Address), args[1].(*big.Int), nil
+}
+
+// packERC20 packs the call of the method with the addresses followed by the amount, which has to be a valid uint256
+// as the ABI encoding would silently truncate it
+func packERC20(method string, amount string, addresses ...common.Address) ([]byte, error) {
+	value, err := ParseUint256(amount)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]interface{}, 0, len(addresses)+1)
+	for _, address := range addresses {
+		args = append(args, address)
+	}
+	return ierc20.Pack(method, append(args, value)...)
+}
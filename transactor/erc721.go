@@ -0,0 +1,119 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/welthee/dobermann/tokens"
+)
+
+// maxEnumeratedTokens the most token ids ERC721TokensOfOwner reads for an owner
+const maxEnumeratedTokens = 1000
+
+var (
+	// ErrNotEnumerable the ERC-721 collection does not implement the enumeration extension,
+	// the token ids of an owner can not be listed
+	ErrNotEnumerable = errors.New("collection not enumerable")
+	// ErrMissingTokenID the ERC-721 transfer has no TokenID
+	ErrMissingTokenID = errors.New("token id not set")
+)
+
+func (t evmTransactor) CreateERC721Tx(ctx context.Context, params TxParams) (*types.Transaction, error) {
+	if params.TokenID == nil {
+		return nil, ErrMissingTokenID
+	}
+	from, err := getSenderAddress(params)
+	if err != nil {
+		return nil, err
+	}
+	if params.Wallet != nil {
+		from = params.Wallet
+	}
+	receiverAddress, err := getReceiverAddress(params)
+	if err != nil {
+		return nil, err
+	}
+	tokenID, err := uint256Word(params.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	return t.createERC20Call(ctx, params, concat(tokens.SelectorSafeTransferFrom[:],
+		common.LeftPadBytes(from.Bytes(), 32),
+		common.LeftPadBytes(receiverAddress.Bytes(), 32), tokenID))
+}
+
+func (t evmTransactor) ERC721TokensOfOwner(ctx context.Context, owner common.Address, collection string) ([]*big.Int, error) {
+	contract := common.HexToAddress(collection)
+	enumerable, err := t.supportsInterface(ctx, contract, tokens.InterfaceERC721Enumerable)
+	if err != nil {
+		return nil, err
+	}
+	if !enumerable {
+		return nil, fmt.Errorf("%w: %s", ErrNotEnumerable, collection)
+	}
+
+	balance, err := t.BalanceOf(ctx, owner, collection)
+	if err != nil {
+		return nil, err
+	}
+	if !balance.IsInt64() || balance.Int64() > maxEnumeratedTokens {
+		return nil, fmt.Errorf("%w: %s owns %s tokens, more than %d", ErrNotEnumerable, owner.Hex(), balance, maxEnumeratedTokens)
+	}
+	ids := make([]*big.Int, 0, balance.Int64())
+	for i := int64(0); i < balance.Int64(); i++ {
+		result, err := t.client.CallContract(ctx, ethereum.CallMsg{
+			To: &contract,
+			Data: concat(tokens.SelectorTokenOfOwnerByIndex[:],
+				common.LeftPadBytes(owner.Bytes(), 32),
+				common.LeftPadBytes(big.NewInt(i).Bytes(), 32)),
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(result) != 32 {
+			return nil, fmt.Errorf("%w: %s returned no token id", ErrNotEnumerable, collection)
+		}
+		ids = append(ids, new(big.Int).SetBytes(result))
+	}
+	return ids, nil
+}
+
+func (t evmTransactor) ERC721OwnerOf(ctx context.Context, collection string, tokenID *big.Int) (common.Address, error) {
+	contract := common.HexToAddress(collection)
+	id, err := uint256Word(tokenID)
+	if err != nil {
+		return common.Address{}, err
+	}
+	result, err := t.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &contract,
+		Data: concat(tokens.SelectorOwnerOf[:], id),
+	}, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(result) != 32 {
+		return common.Address{}, fmt.Errorf("invalid ownerOf response of %s", collection)
+	}
+	return common.BytesToAddress(result), nil
+}
+
+// supportsInterface calls the ERC-165 supportsInterface of the contract, false when it reverts
+func (t evmTransactor) supportsInterface(ctx context.Context, contract common.Address, interfaceID tokens.Selector) (bool, error) {
+	result, err := t.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &contract,
+		Data: concat(tokens.SelectorSupportsInterface[:], common.RightPadBytes(interfaceID[:], 32)),
+	}, nil)
+	if err != nil {
+		if strings.Contains(err.Error(), executionReverted) {
+			return false, nil
+		}
+		return false, err
+	}
+	return len(result) == 32 && new(big.Int).SetBytes(result).Sign() != 0, nil
+}
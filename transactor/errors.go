@@ -0,0 +1,16 @@
+package transactor
+
+import "github.com/welthee/dobermann/client"
+
+// The errors a node returns when it refuses a transaction, Transfer wraps the error of the node with the matching
+// one. They are the errors of client.ClassifySendError, which the secondary broadcasts are classified with as well.
+var (
+	// ErrNonceTooLow another transaction of the sender with the same nonce was already mined
+	ErrNonceTooLow = client.ErrNonceTooLow
+	// ErrAlreadyKnown the same transaction is already in the pool of the node
+	ErrAlreadyKnown = client.ErrAlreadyKnown
+	// ErrReplacementUnderpriced a pending transaction has the same nonce and the fees are not high enough to replace it
+	ErrReplacementUnderpriced = client.ErrReplacementUnderpriced
+	// ErrInsufficientFunds the sender can not pay the gas and the value of the transaction
+	ErrInsufficientFunds = client.ErrInsufficientFunds
+)
@@ -0,0 +1,124 @@
+package transactor
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/crypto/sha3"
+)
+
+// ExecutorCalldata builds the calldata of a contract wallet call which makes the wallet
+// call target with the given value and inner calldata. The call is sent by the owner,
+// the operator key allowed to execute calls through the wallet.
+type ExecutorCalldata func(owner common.Address, target common.Address, value *big.Int, data []byte) ([]byte, error)
+
+// safeExecTransactionABI the execTransaction function of the Safe wallets
+const safeExecTransactionABI = `[{"name":"execTransaction","type":"function","stateMutability":"payable","inputs":[
+{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},
+{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},
+{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},
+{"name":"signatures","type":"bytes"}],"outputs":[{"name":"success","type":"bool"}]}]`
+
+// ExecutorArgs returns the arguments of the wallet method for the inner call, in the order of the method inputs
+type ExecutorArgs func(owner common.Address, target common.Address, value *big.Int, data []byte) ([]interface{}, error)
+
+// NewABIExecutorCalldata utility method to create an ExecutorCalldata for any wallet method, packing
+// the method of the given JSON ABI with the arguments built by args
+func NewABIExecutorCalldata(walletABI string, method string, args ExecutorArgs) (ExecutorCalldata, error) {
+	parsed, err := abi.JSON(strings.NewReader(walletABI))
+	if err != nil {
+		return nil, fmt.Errorf("invalid wallet abi: %w", err)
+	}
+	if _, ok := parsed.Methods[method]; !ok {
+		return nil, fmt.Errorf("method %s not found in the wallet abi", method)
+	}
+	return func(owner common.Address, target common.Address, value *big.Int, data []byte) ([]byte, error) {
+		if value == nil {
+			value = new(big.Int)
+		}
+		values, err := args(owner, target, value, data)
+		if err != nil {
+			return nil, err
+		}
+		return parsed.Pack(method, values...)
+	}, nil
+}
+
+// NewSafeExecCalldata utility method to create an ExecutorCalldata for Safe wallets with a threshold of one.
+// The owner sending the execTransaction is approved with a pre-validated signature, which the Safe accepts
+// when the signing owner is the sender, so no additional signing is needed.
+func NewSafeExecCalldata() ExecutorCalldata {
+	executor, err := NewABIExecutorCalldata(safeExecTransactionABI, "execTransaction",
+		func(owner common.Address, target common.Address, value *big.Int, data []byte) ([]interface{}, error) {
+			return []interface{}{target, value, data, uint8(0), big.NewInt(0), big.NewInt(0), big.NewInt(0),
+				common.Address{}, common.Address{}, safePreValidatedSignature(owner)}, nil
+		})
+	if err != nil {
+		panic(err)
+	}
+	return executor
+}
+
+// safePreValidatedSignature the Safe signature of type 1: the owner address as r, a zero s and v set to 1
+func safePreValidatedSignature(owner common.Address) []byte {
+	signature := common.LeftPadBytes(owner.Bytes(), 32)
+	signature = append(signature, make([]byte, 32)...)
+	return append(signature, 1)
+}
+
+// NewExecuteCalldata utility method to create an ExecutorCalldata for wallets exposing
+// an execute function shaped as execute(address,uint256,bytes), e.g. "execute(address,uint256,bytes)"
+func NewExecuteCalldata(signature string) ExecutorCalldata {
+	methodID := keccak256([]byte(signature))[:4]
+	return func(owner common.Address, target common.Address, value *big.Int, data []byte) ([]byte, error) {
+		if value == nil {
+			value = new(big.Int)
+		}
+		valueWord, err := uint256Word(value)
+		if err != nil {
+			return nil, err
+		}
+		var calldata []byte
+		calldata = append(calldata, methodID...)
+		calldata = append(calldata, common.LeftPadBytes(target.Bytes(), 32)...)
+		calldata = append(calldata, valueWord...)
+		// offset of the dynamic bytes argument, right after the three head words
+		calldata = append(calldata, common.LeftPadBytes(big.NewInt(3*32).Bytes(), 32)...)
+		calldata = append(calldata, common.LeftPadBytes(big.NewInt(int64(len(data))).Bytes(), 32)...)
+		calldata = append(calldata, common.RightPadBytes(data, (len(data)+31)/32*32)...)
+		return calldata, nil
+	}
+}
+
+// transferEventTopic is the topic of the ERC-20 Transfer(address,address,uint256) event
+var transferEventTopic = common.BytesToHash(keccak256([]byte("Transfer(address,address,uint256)")))
+
+// HasTransferLog checks if the receipt contains the ERC-20 Transfer event of the given
+// token, sender, receiver and amount
+func HasTransferLog(receipt *types.Receipt, token common.Address, from common.Address, to common.Address, amount *big.Int) bool {
+	if receipt == nil {
+		return false
+	}
+	for _, l := range receipt.Logs {
+		if l.Address != token || len(l.Topics) != 3 || l.Topics[0] != transferEventTopic {
+			continue
+		}
+		if common.BytesToAddress(l.Topics[1].Bytes()) != from || common.BytesToAddress(l.Topics[2].Bytes()) != to {
+			continue
+		}
+		if new(big.Int).SetBytes(l.Data).Cmp(amount) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func keccak256(data []byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(data)
+	return hash.Sum(nil)
+}
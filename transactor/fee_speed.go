@@ -0,0 +1,44 @@
+package transactor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FeeSpeed the tier of the gas tracker suggestion the fees are taken from
+type FeeSpeed string
+
+const (
+	FeeSpeedSafeLow  FeeSpeed = "safeLow"
+	FeeSpeedStandard FeeSpeed = "standard"
+	FeeSpeedFast     FeeSpeed = "fast"
+)
+
+// ErrUnknownFeeSpeed the value is not one of the FeeSpeed values
+var ErrUnknownFeeSpeed = errors.New("unknown fee speed")
+
+// ParseFeeSpeed returns the FeeSpeed of the value ignoring its case, e.g. "Fast", FeeSpeedSafeLow when empty
+func ParseFeeSpeed(value string) (FeeSpeed, error) {
+	if strings.TrimSpace(value) == "" {
+		return FeeSpeedSafeLow, nil
+	}
+	for _, speed := range []FeeSpeed{FeeSpeedSafeLow, FeeSpeedStandard, FeeSpeedFast} {
+		if strings.EqualFold(strings.TrimSpace(value), string(speed)) {
+			return speed, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFeeSpeed, value)
+}
+
+// Tier returns the gwei tip and max fee suggested for the speed, the safe low ones for unknown speeds
+func (r GasTrackerResponse) Tier(speed FeeSpeed) (maxPriorityFee float64, maxFee float64) {
+	switch speed {
+	case FeeSpeedStandard:
+		return r.Standard.MaxPriorityFee, r.Standard.MaxFee
+	case FeeSpeedFast:
+		return r.Fast.MaxPriorityFee, r.Fast.MaxFee
+	default:
+		return r.SafeLow.MaxPriorityFee, r.SafeLow.MaxFee
+	}
+}
@@ -6,11 +6,17 @@ import (
 	"errors"
 	"fmt"
 	"github.com/rs/zerolog/log"
-	"io/ioutil"
+	"github.com/welthee/dobermann/internal/httpx"
+	"github.com/welthee/dobermann/internal/redact"
+	"github.com/welthee/dobermann/retry"
 	"net/http"
 )
 
-var ErrFailToGetResponseFromGasTracker = errors.New("failed to get a response from the gas tracker")
+var (
+	ErrFailToGetResponseFromGasTracker = errors.New("failed to get a response from the gas tracker")
+	// ErrResponseTooLarge the gas tracker response is larger than the configured maximum size
+	ErrResponseTooLarge = httpx.ErrBodyTooLarge
+)
 
 // GasTracker provides methods for gas tracking
 type GasTracker interface {
@@ -48,22 +54,47 @@ func (r GasTrackerResponse) String() string {
 }
 
 type polygonGasTracker struct {
-	gasTrackerURL string
+	gasTrackerURL   string
+	maxResponseSize int64
+}
+
+// GasTrackerOption configures the gas tracker created by NewPolygonGasTracker
+type GasTrackerOption func(o *polygonGasTracker)
+
+// WithMaxResponseSize limits the bytes read from a gas tracker response, 1MB by default.
+// Larger responses fail with ErrResponseTooLarge.
+func WithMaxResponseSize(size int64) GasTrackerOption {
+	return func(o *polygonGasTracker) {
+		if size > 0 {
+			o.maxResponseSize = size
+		}
+	}
 }
 
-func NewPolygonGasTracker(url string) GasTracker {
-	return polygonGasTracker{gasTrackerURL: url}
+func NewPolygonGasTracker(url string, options ...GasTrackerOption) GasTracker {
+	tracker := polygonGasTracker{
+		gasTrackerURL:   url,
+		maxResponseSize: httpx.DefaultMaxBodySize,
+	}
+	for _, option := range options {
+		option(&tracker)
+	}
+	return tracker
 }
 
 func (o polygonGasTracker) GetSuggestedGasPrice(ctx context.Context) (*GasTrackerResponse, error) {
-	resp, err := http.Get(o.gasTrackerURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.gasTrackerURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", ErrFailToGetResponseFromGasTracker, err)
 	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrFailToGetResponseFromGasTracker, redact.Error(err))
+	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := httpx.ReadBody(resp.Body, o.maxResponseSize)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s: %w", ErrFailToGetResponseFromGasTracker, err)
 	}
 
 	var result GasTrackerResponse
@@ -75,3 +106,30 @@ func (o polygonGasTracker) GetSuggestedGasPrice(ctx context.Context) (*GasTracke
 	log.Ctx(ctx).Info().Str("response", result.String()).Msg("got from gas tracker")
 	return &result, nil
 }
+
+type retryGasTracker struct {
+	GasTracker
+	policy retry.Policy
+}
+
+// WithGasTrackerRetry utility method to wrap a GasTracker so its requests are retried with the policy.
+// The gas tracker is returned unchanged when the policy makes a single attempt.
+func WithGasTrackerRetry(tracker GasTracker, policy retry.Policy) GasTracker {
+	if policy.MaxAttempts <= 1 {
+		return tracker
+	}
+	return retryGasTracker{
+		GasTracker: tracker,
+		policy:     policy,
+	}
+}
+
+func (r retryGasTracker) GetSuggestedGasPrice(ctx context.Context) (*GasTrackerResponse, error) {
+	var response *GasTrackerResponse
+	err := retry.Do(ctx, r.policy, func(ctx context.Context) error {
+		var err error
+		response, err = r.GasTracker.GetSuggestedGasPrice(ctx)
+		return err
+	})
+	return response, err
+}
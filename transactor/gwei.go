@@ -0,0 +1,58 @@
+package transactor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// ErrInvalidGwei the gwei value of the gas tracker is negative, NaN or infinite
+var ErrInvalidGwei = errors.New("invalid gwei value")
+
+var weiPerGwei = big.NewRat(1_000_000_000, 1)
+
+// GweiToWei converts the gwei value of the gas tracker to wei exactly, through its shortest decimal representation,
+// the one sent by the gas tracker. Values with more than 9 decimals are rounded to the nearest wei.
+func GweiToWei(gwei float64) (*big.Int, error) {
+	value, err := exactRat(gwei)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGwei, gwei)
+	}
+	return roundRat(value.Mul(value, weiPerGwei)), nil
+}
+
+// gweiToWeiMultiplied converts the gwei value to wei multiplied by the multiplier, both taken exactly from their
+// shortest decimal representation, so that e.g. 30.1 gwei multiplied by 1.2 is 36.12 gwei
+func gweiToWeiMultiplied(gwei float64, multiplier float64) (*big.Int, error) {
+	value, err := exactRat(gwei)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGwei, gwei)
+	}
+	factor, err := exactRat(multiplier)
+	if err != nil {
+		return nil, fmt.Errorf("invalid multiplier %v", multiplier)
+	}
+	value.Mul(value, factor)
+	return roundRat(value.Mul(value, weiPerGwei)), nil
+}
+
+// exactRat returns the non-negative value as the rational number of its shortest decimal representation
+func exactRat(value float64) (*big.Rat, error) {
+	if value < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, errors.New("not a non-negative finite number")
+	}
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'f', -1, 64))
+	if !ok {
+		return nil, errors.New("not a decimal number")
+	}
+	return r, nil
+}
+
+// roundRat rounds the non-negative value to the nearest integer, halves up
+func roundRat(value *big.Rat) *big.Int {
+	numerator := new(big.Int).Mul(value.Num(), big.NewInt(2))
+	numerator.Add(numerator, value.Denom())
+	return numerator.Quo(numerator, new(big.Int).Mul(value.Denom(), big.NewInt(2)))
+}
@@ -0,0 +1,93 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestGweiToWei(t *testing.T) {
+	tests := []struct {
+		gwei float64
+		want string
+	}{
+		{gwei: 0, want: "0"},
+		{gwei: 30, want: "30000000000"},
+		{gwei: 1.5, want: "1500000000"},
+		{gwei: 30.000000012, want: "30000000012"},
+		// float arithmetic noise past the 9 decimals is rounded away
+		{gwei: 0.1 + 0.2, want: "300000000"},
+		{gwei: 0.0000000005, want: "1"},
+		{gwei: 0.0000000004, want: "0"},
+		// large values keep the decimals of their shortest representation, multiplying by 1e9 would not
+		{gwei: 123456789.12345679, want: "123456789123456790"},
+		{gwei: 1e21, want: "1000000000000000000000000000000"},
+	}
+	for _, test := range tests {
+		wei, err := GweiToWei(test.gwei)
+		if err != nil {
+			t.Fatalf("%v gwei: %v", test.gwei, err)
+		}
+		if wei.String() != test.want {
+			t.Fatalf("%v gwei: %s wei, want %s", test.gwei, wei, test.want)
+		}
+	}
+}
+
+func TestGweiToWeiRejectsInvalidValues(t *testing.T) {
+	for _, gwei := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if _, err := GweiToWei(gwei); !errors.Is(err, ErrInvalidGwei) {
+			t.Fatalf("%v gwei: error %v, want %v", gwei, err, ErrInvalidGwei)
+		}
+	}
+}
+
+// fixedGasTracker returns the same response
+type fixedGasTracker GasTrackerResponse
+
+func (t fixedGasTracker) GetSuggestedGasPrice(ctx context.Context) (*GasTrackerResponse, error) {
+	response := GasTrackerResponse(t)
+	return &response, nil
+}
+
+func TestTrackerGasCapValuesExact(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxFee        float64
+		multiplier    float64
+		wantGasFeeCap string
+	}{
+		{name: "no multiplier", maxFee: 123456789.12345679, multiplier: 1, wantGasFeeCap: "123456789123456790"},
+		{name: "multiplied", maxFee: 30.1, multiplier: 1.2, wantGasFeeCap: "36120000000"},
+		{name: "multiplied past 9 decimals", maxFee: 30.000000001, multiplier: 1.5, wantGasFeeCap: "45000000002"},
+		{name: "large multiplied", maxFee: 123456789.12345679, multiplier: 1.1, wantGasFeeCap: "135802468035802469"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var response GasTrackerResponse
+			response.SafeLow.MaxPriorityFee = 30.000000012
+			response.SafeLow.MaxFee = test.maxFee
+			transactor, err := NewEvmTransactor(newFakeClient(), fixedGasTracker(response), nil,
+				WithMaxFeeCapMultiplier(test.multiplier))
+			if err != nil {
+				t.Fatal(err)
+			}
+			gasTipCap, gasFeeCap, err := transactor.GetGasCapValues(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the caps are the ones quoted by the exact conversion
+			wantGasTipCap, err := GweiToWei(response.SafeLow.MaxPriorityFee)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gasTipCap.Cmp(wantGasTipCap) != 0 {
+				t.Fatalf("tip %s, want %s", gasTipCap, wantGasTipCap)
+			}
+			if gasFeeCap.String() != test.wantGasFeeCap {
+				t.Fatalf("fee cap %s, want %s", gasFeeCap, test.wantGasFeeCap)
+			}
+		})
+	}
+}
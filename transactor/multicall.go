@@ -0,0 +1,124 @@
+package transactor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/welthee/dobermann/tokens"
+)
+
+// Multicall3Address the address Multicall3 is deployed at on most EVM chains, see https://www.multicall3.com
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// multicallBatchSize the most balances read with a single Multicall3 call, bounding the size of the response
+const multicallBatchSize = 500
+
+// multicall3ABI the aggregate3 function of Multicall3
+const multicall3ABI = `[{"name":"aggregate3","type":"function","stateMutability":"payable","inputs":[
+{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},
+{"name":"callData","type":"bytes"}]}],"outputs":[{"name":"returnData","type":"tuple[]","components":[
+{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]}]`
+
+var multicall3 = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// multicallCall a call of aggregate3, its fields match the tuple components
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicallResult a result of aggregate3, its fields match the tuple components
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+func (t evmTransactor) BalancesOf(ctx context.Context, accounts []common.Address, erc20Address string) ([]*big.Int, error) {
+	balances := make([]*big.Int, 0, len(accounts))
+	if t.multicall == nil {
+		for _, account := range accounts {
+			balance, err := t.BalanceOf(ctx, account, erc20Address)
+			if err != nil {
+				return nil, err
+			}
+			balances = append(balances, balance)
+		}
+		return balances, nil
+	}
+
+	for start := 0; start < len(accounts); start += multicallBatchSize {
+		end := start + multicallBatchSize
+		if end > len(accounts) {
+			end = len(accounts)
+		}
+		batch, err := t.multicallBalances(ctx, accounts[start:end], erc20Address)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, batch...)
+	}
+	return balances, nil
+}
+
+// multicallBalances reads the balances with a single aggregate3 call, the balances whose call failed
+// are read on their own so that the error of the token is returned
+func (t evmTransactor) multicallBalances(ctx context.Context, accounts []common.Address, erc20Address string) ([]*big.Int, error) {
+	token := common.HexToAddress(erc20Address)
+	calls := make([]multicallCall, 0, len(accounts))
+	for _, account := range accounts {
+		calls = append(calls, multicallCall{
+			Target:       token,
+			AllowFailure: true,
+			CallData:     concat(tokens.SelectorBalanceOf[:], common.LeftPadBytes(account.Bytes(), 32)),
+		})
+	}
+	data, err := multicall3.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, err
+	}
+	output, err := t.client.CallContract(ctx, ethereum.CallMsg{
+		To:   t.multicall,
+		Data: data,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("multicall failed: %w", err)
+	}
+	unpacked, err := multicall3.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, fmt.Errorf("invalid multicall response: %w", err)
+	}
+	var results []multicallResult
+	err = multicall3.Methods["aggregate3"].Outputs.Copy(&results, unpacked)
+	if err != nil {
+		return nil, fmt.Errorf("invalid multicall response: %w", err)
+	}
+	if len(results) != len(accounts) {
+		return nil, fmt.Errorf("invalid multicall response: %d results for %d calls", len(results), len(accounts))
+	}
+
+	balances := make([]*big.Int, 0, len(accounts))
+	for i, result := range results {
+		if !result.Success || len(result.ReturnData) != 32 {
+			balance, err := t.BalanceOf(ctx, accounts[i], erc20Address)
+			if err != nil {
+				return nil, err
+			}
+			balances = append(balances, balance)
+			continue
+		}
+		balances = append(balances, new(big.Int).SetBytes(result.ReturnData))
+	}
+	return balances, nil
+}
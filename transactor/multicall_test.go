@@ -0,0 +1,126 @@
+package transactor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/welthee/dobermann/client"
+	"github.com/welthee/dobermann/tokens"
+)
+
+// multicallClient a node holding the token balances, read with balanceOf or through Multicall3Address, counting
+// the calls of each kind
+type multicallClient struct {
+	client.Client
+	balances map[common.Address]*big.Int
+	// skipped the accounts whose balanceOf fails within aggregate3 only
+	skipped map[common.Address]bool
+	// reverted the accounts whose balanceOf always fails
+	reverted map[common.Address]bool
+	calls    map[string]int
+}
+
+func (c *multicallClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if *msg.To != Multicall3Address {
+		c.calls["balanceOf"]++
+		account := common.BytesToAddress(msg.Data[4:])
+		if !bytes.Equal(msg.Data[:4], tokens.SelectorBalanceOf[:]) || c.reverted[account] {
+			return nil, errors.New(executionReverted)
+		}
+		return common.LeftPadBytes(c.balances[account].Bytes(), 32), nil
+	}
+
+	c.calls["aggregate3"]++
+	method := multicall3.Methods["aggregate3"]
+	if !bytes.Equal(msg.Data[:4], method.ID) {
+		return nil, errors.New(executionReverted)
+	}
+	unpacked, err := method.Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	var calls []multicallCall
+	err = method.Inputs.Copy(&calls, unpacked)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]multicallResult, 0, len(calls))
+	for _, call := range calls {
+		account := common.BytesToAddress(call.CallData[4:])
+		if c.skipped[account] || c.reverted[account] {
+			results = append(results, multicallResult{})
+			continue
+		}
+		results = append(results, multicallResult{Success: true,
+			ReturnData: common.LeftPadBytes(c.balances[account].Bytes(), 32)})
+	}
+	return method.Outputs.Pack(results)
+}
+
+func TestBalancesOf(t *testing.T) {
+	multicall := Multicall3Address
+	tests := []struct {
+		name      string
+		multicall *common.Address
+		accounts  int
+		skipped   []int
+		reverted  []int
+		wantErr   bool
+		// calls the balanceOf calls made on their own and the aggregate3 calls
+		calls map[string]int
+	}{
+		{name: "one call per account", accounts: 3, calls: map[string]int{"balanceOf": 3}},
+		{name: "multicall", multicall: &multicall, accounts: 3, calls: map[string]int{"aggregate3": 1}},
+		{name: "multicall batches", multicall: &multicall, accounts: multicallBatchSize + 1,
+			calls: map[string]int{"aggregate3": 2}},
+		{name: "failed call read on its own", multicall: &multicall, accounts: 3, skipped: []int{1},
+			calls: map[string]int{"aggregate3": 1, "balanceOf": 1}},
+		{name: "reverting token", multicall: &multicall, accounts: 3, reverted: []int{2}, wantErr: true,
+			calls: map[string]int{"aggregate3": 1, "balanceOf": 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := &multicallClient{balances: make(map[common.Address]*big.Int), skipped: make(map[common.Address]bool),
+				reverted: make(map[common.Address]bool), calls: make(map[string]int)}
+			accounts := make([]common.Address, test.accounts)
+			for i := range accounts {
+				accounts[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+				// every third account holds no tokens
+				node.balances[accounts[i]] = big.NewInt(int64(i % 3 * 100))
+			}
+			for _, i := range test.skipped {
+				node.skipped[accounts[i]] = true
+			}
+			for _, i := range test.reverted {
+				node.reverted[accounts[i]] = true
+			}
+			tr := newTestTransactor(t, node, WithMulticall(test.multicall))
+
+			balances, err := tr.BalancesOf(context.Background(), accounts, "0x3333333333333333333333333333333333333333")
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error %v, want error %v", err, test.wantErr)
+			}
+			for method, want := range test.calls {
+				if node.calls[method] != want {
+					t.Fatalf("%d %s calls, want %d", node.calls[method], method, want)
+				}
+			}
+			if test.wantErr {
+				return
+			}
+			if len(balances) != len(accounts) {
+				t.Fatalf("%d balances for %d accounts", len(balances), len(accounts))
+			}
+			for i, balance := range balances {
+				if balance.Cmp(node.balances[accounts[i]]) != 0 {
+					t.Fatalf("balance %d %s, want %s", i, balance, node.balances[accounts[i]])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/welthee/dobermann/key"
+	"github.com/welthee/dobermann/tokens"
+)
+
+// ErrPermitUnsupported the token has no EIP-2612 DOMAIN_SEPARATOR or nonces, or it rejected the permit
+var ErrPermitUnsupported = errors.New("token does not support permit")
+
+// permitTypeHash keccak256("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)")
+var permitTypeHash = keccak256([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+
+func (t evmTransactor) CreateERC20PermitTx(ctx context.Context, params TxParams) (*types.Transaction, error) {
+	if params.OwnerKeyProvider == nil || params.OwnerKeyProvider.GetAddress() == nil {
+		return nil, fmt.Errorf("%w: owner", ErrNilAddress)
+	}
+	owner := *params.OwnerKeyProvider.GetAddress()
+	spender, err := getSenderAddress(params)
+	if err != nil {
+		return nil, err
+	}
+	value, err := amountWord(params.Amount)
+	if err != nil {
+		return nil, err
+	}
+	deadline := MaxUint256
+	if params.Deadline != nil {
+		deadline = params.Deadline
+	}
+	err = CheckUint256(deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	token := common.HexToAddress(params.TokenAddr)
+	domainSeparator, err := t.callPermitWord(ctx, token, tokens.SelectorDomainSeparator[:])
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := t.callPermitWord(ctx, token, concat(tokens.SelectorNonces[:], common.LeftPadBytes(owner.Bytes(), 32)))
+	if err != nil {
+		return nil, err
+	}
+
+	digest := permitDigest(domainSeparator, owner, *spender, value, nonce, deadline)
+	signature, err := key.SignHash(ctx, params.OwnerKeyProvider, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	data := concat(tokens.SelectorPermit[:],
+		common.LeftPadBytes(owner.Bytes(), 32),
+		common.LeftPadBytes(spender.Bytes(), 32),
+		value, common.LeftPadBytes(deadline.Bytes(), 32),
+		common.LeftPadBytes([]byte{signature[64] + 27}, 32),
+		signature[:32], signature[32:64])
+	params.Wallet = nil
+	tx, err := t.createERC20Call(ctx, params, data)
+	if err != nil && strings.Contains(err.Error(), executionReverted) {
+		// the signature matches the domain of the token, tokens with another permit, e.g. DAI, revert
+		return nil, fmt.Errorf("%w: %s: %w", ErrPermitUnsupported, params.TokenAddr, err)
+	}
+	return tx, err
+}
+
+// permitDigest the EIP-712 digest of the permit signed by the owner, the value and nonce being ABI words
+func permitDigest(domainSeparator []byte, owner common.Address, spender common.Address, value []byte, nonce []byte,
+	deadline *big.Int) common.Hash {
+	structHash := keccak256(concat(permitTypeHash,
+		common.LeftPadBytes(owner.Bytes(), 32),
+		common.LeftPadBytes(spender.Bytes(), 32),
+		value, nonce, common.LeftPadBytes(deadline.Bytes(), 32)))
+	return common.BytesToHash(keccak256(concat([]byte{0x19, 0x01}, domainSeparator, structHash)))
+}
+
+// callPermitWord calls the EIP-2612 view method of the token, ErrPermitUnsupported when it reverts
+// or does not return a 32 bytes word
+func (t evmTransactor) callPermitWord(ctx context.Context, token common.Address, data []byte) ([]byte, error) {
+	result, err := t.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &token,
+		Data: data,
+	}, nil)
+	if err != nil {
+		if strings.Contains(err.Error(), executionReverted) {
+			return nil, fmt.Errorf("%w: %s: %w", ErrPermitUnsupported, token.Hex(), err)
+		}
+		return nil, err
+	}
+	if len(result) != 32 {
+		return nil, fmt.Errorf("%w: %s", ErrPermitUnsupported, token.Hex())
+	}
+	return result, nil
+}
+
+// concat joins the byte slices into a new one
+func concat(parts ...[]byte) []byte {
+	var data []byte
+	for _, part := range parts {
+		data = append(data, part...)
+	}
+	return data
+}
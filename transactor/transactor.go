@@ -5,20 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/client"
 	"github.com/welthee/dobermann/key"
 	"github.com/welthee/dobermann/nonce"
+	"github.com/welthee/dobermann/tokens"
 	"math"
 	"math/big"
-	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"golang.org/x/crypto/sha3"
+	ethparams "github.com/ethereum/go-ethereum/params"
 )
 
+// executionReverted is returned by the nodes when a call reverts
+const executionReverted = "execution reverted"
+
 type TxParams struct {
 	// ERC-20 token address
 	TokenAddr string
@@ -26,85 +32,397 @@ type TxParams struct {
 	SenderKeyProvider key.Provider
 	// receiver of the ERC-20 token
 	ReceiverKeyProvider key.Provider
+	// receiver address, when set it is used instead of ReceiverKeyProvider
+	ReceiverAddress *common.Address
 	// amount sent in wei
 	Amount string
 	// maxPriorityFeePerGas
 	GasTipCapValue *big.Int
 	// maxFeePerGas
 	GasFeeCapValue *big.Int
+	// gas limit, when set the gas estimation is skipped
+	GasLimit uint64
+	// nonce, when set it is used instead of the one given by the nonce provider
+	Nonce *big.Int
+	// contract wallet holding the ERC-20 tokens, when set the transfer is sent through
+	// the wallet using Executor and signed by the sender as the wallet operator
+	Wallet *common.Address
+	// Executor builds the wallet call, required when Wallet is set
+	Executor ExecutorCalldata
+	// owner of the ERC-20 tokens pulled with transferFrom
+	Owner *common.Address
+	// OwnerKeyProvider signs the EIP-2612 permit of the owner, it has to implement key.HashSigner
+	OwnerKeyProvider key.Provider
+	// Deadline the unix time after which the permit can not be used anymore, no deadline when nil
+	Deadline *big.Int
+	// calldata of a native transfer, e.g. a tag identifying the transaction
+	Data []byte
+	// TokenID the ERC-721 token sent by CreateERC721Tx
+	TokenID *big.Int
+	// TokenAmounts the ERC-1155 tokens sent by CreateERC1155BatchTx
+	TokenAmounts []TokenAmount
+}
+
+// TokenAmount the amount of an ERC-1155 token
+type TokenAmount struct {
+	ID     *big.Int
+	Amount *big.Int
 }
 
-// Transactor contains methods needed to send and verify transactions
+var (
+	ErrGasLimitBelowIntrinsic = errors.New("gas limit below intrinsic gas")
+	// ErrNilAddress the key provider of the sender or receiver has no address
+	ErrNilAddress = errors.New("nil address")
+	// ErrBroadcastVetoed the PreBroadcastFunc rejected the transaction
+	ErrBroadcastVetoed = errors.New("broadcast vetoed")
+	// ErrMissingExecutor a Wallet was set without an Executor
+	ErrMissingExecutor = errors.New("wallet executor not set")
+	// ErrSignerTypeMismatch the key provider was created for another signer type than the transactor
+	ErrSignerTypeMismatch = errors.New("signer type mismatch")
+	// ErrInvalidDecimals the token returned no decimals or more than fit in a uint8
+	ErrInvalidDecimals = errors.New("invalid decimals")
+	// ErrNodeMinGasPriceAboveCap the gas price the node accepts is above the max gas fee cap
+	ErrNodeMinGasPriceAboveCap = errors.New("node minimum gas price above max gas fee cap")
+)
+
+// PreBroadcastFunc is invoked right before a transaction is sent, returning an error aborts the broadcast
+type PreBroadcastFunc func(ctx context.Context, tx *types.Transaction) error
+
+// Transactor contains methods needed to send and verify transactions. It is shared by the accounts collected
+// concurrently, so implementations must be safe for concurrent use.
 type Transactor interface {
 	//CreateERC20Tx creates a signed ERC-20 tx using the provided TxParams params
 	CreateERC20Tx(ctx context.Context, params TxParams) (*types.Transaction, error)
+	//EstimateERC20Gas returns the gas limit of the ERC-20 transfer, without signing it
+	EstimateERC20Gas(ctx context.Context, params TxParams) (uint64, error)
+	//CreateERC20ApproveTx creates a signed ERC-20 approve tx allowing the receiver to spend the amount
+	CreateERC20ApproveTx(ctx context.Context, params TxParams) (*types.Transaction, error)
+	//CreateERC20TransferFromTx creates a signed ERC-20 transferFrom tx moving the amount from the owner to the receiver
+	CreateERC20TransferFromTx(ctx context.Context, params TxParams) (*types.Transaction, error)
+	//CreateERC20PermitTx creates a signed EIP-2612 permit tx of the sender, allowing it to spend the amount of the
+	//owner, whose OwnerKeyProvider signs the permit. ErrPermitUnsupported when the token does not implement EIP-2612.
+	CreateERC20PermitTx(ctx context.Context, params TxParams) (*types.Transaction, error)
+	//CreateERC721Tx creates a signed ERC-721 safeTransferFrom tx sending the TokenID of the sender, or of its Wallet
+	//when set, to the receiver
+	CreateERC721Tx(ctx context.Context, params TxParams) (*types.Transaction, error)
+	//ERC721TokensOfOwner returns the ids of the ERC-721 tokens of the owner, ErrNotEnumerable when the collection
+	//does not implement the ERC-721 enumeration extension
+	ERC721TokensOfOwner(ctx context.Context, owner common.Address, collection string) ([]*big.Int, error)
+	//ERC721OwnerOf returns the owner of the ERC-721 token
+	ERC721OwnerOf(ctx context.Context, collection string, tokenID *big.Int) (common.Address, error)
+	//CreateERC1155BatchTx creates a signed ERC-1155 safeBatchTransferFrom tx sending the TokenAmounts of the sender,
+	//or of its Wallet, with empty data
+	CreateERC1155BatchTx(ctx context.Context, params TxParams) (*types.Transaction, error)
 	//CreateTx creates a signed native tx using the provided TxParams params
 	CreateTx(ctx context.Context, params TxParams) (*types.Transaction, error)
-	//Transfer sends transaction to network
+	//Transfer sends transaction to network, the refusals of the node are wrapped with e.g. ErrNonceTooLow
 	Transfer(ctx context.Context, transaction *types.Transaction) error
-	//VerifyTx checks if transaction is mined using the given transaction hash
-	VerifyTx(ctx context.Context, txHash string) (bool, error)
+	//TransferBundle submits the transactions to be included together in the given block, ErrBundleUnsupported
+	//when no BundleSubmitter is configured. The transactions are checked by the PreBroadcastFunc like Transfer.
+	TransferBundle(ctx context.Context, transactions []*types.Transaction, blockNumber uint64) error
+	//VerifyTx waits for the receipt of the transaction using the given transaction hash, the transaction
+	//succeeded only when the receipt Status is types.ReceiptStatusSuccessful. ErrChainStalled when the chain
+	//stalls meanwhile, see WithHeadWatchdog
+	VerifyTx(ctx context.Context, txHash string) (*types.Receipt, error)
+	//VerifyTxs waits with a single polling loop until all the given transactions have the number of
+	//confirmations, returning the state of each of them, including the pending ones when the context is done
+	VerifyTxs(ctx context.Context, hashes []string, confirmations uint64) (map[string]TxState, error)
+	//GetTxReceipt returns the receipt of a mined transaction using the given transaction hash
+	GetTxReceipt(ctx context.Context, txHash string) (*types.Receipt, error)
 	//BalanceAt returns the wei balance of the given account taken from the latest known block
 	BalanceAt(ctx context.Context, accountAddr common.Address) (*big.Int, error)
+	//CodeAt returns the contract code of the given account, empty for externally owned accounts
+	CodeAt(ctx context.Context, accountAddr common.Address) ([]byte, error)
+	//NonceBacklog returns the nonce of the given account in the latest block and the number of its transactions
+	//pending above it, which the next transaction of the account would queue behind
+	NonceBacklog(ctx context.Context, accountAddr common.Address) (uint64, uint64, error)
 	//BalanceOf returns the ERC-20 wei balance of the given account
 	BalanceOf(ctx context.Context, accountAddr common.Address, erc20Address string) (*big.Int, error)
-	//GetGasCapValues retrieves the network's suggested gas price
+	//BalancesOf returns the ERC-20 wei balances of the given accounts in their order, read with a single
+	//Multicall3 call per 500 accounts when WithMulticall is set, otherwise with one call per account
+	BalancesOf(ctx context.Context, accounts []common.Address, erc20Address string) ([]*big.Int, error)
+	//BalanceOfAt returns the ERC-20 wei balance of the given account at the given block, latest when nil
+	BalanceOfAt(ctx context.Context, accountAddr common.Address, erc20Address string, blockNumber *big.Int) (*big.Int, error)
+	//Decimals returns the decimals of the ERC-20 token
+	Decimals(ctx context.Context, erc20Address string) (uint8, error)
+	//Allowance returns the ERC-20 wei amount the spender is allowed to transfer from the owner
+	Allowance(ctx context.Context, owner common.Address, spender common.Address, erc20Address string) (*big.Int, error)
+	//GetGasCapValues retrieves the network's suggested gas price, from the tier of the configured FeeSpeed,
+	//or from the node when the gas tracker fails and the node fee fallback is enabled
 	GetGasCapValues(ctx context.Context) (*big.Int, *big.Int, error)
+	//CallAddressCheck calls the view method with the given signature, e.g. "isWhitelisted(address)", of the
+	//contract with the addresses as arguments and returns whether it returned true, false when the call reverted
+	CallAddressCheck(ctx context.Context, contract common.Address, signature string, args ...common.Address) (bool, error)
 }
 
 type evmTransactor struct {
-	client        *ethclient.Client
-	gasTracker    GasTracker
-	nonceProvider nonce.Provider
+	client               client.Client
+	gasTracker           GasTracker
+	nonceProvider        nonce.Provider
+	confirmationStrategy ConfirmationStrategy
+	maxFeeCapMultiplier  float64
+	gasLimitMultiplier   float64
+	preBroadcast         PreBroadcastFunc
+	gasTipCap            *big.Int
+	maxGasFeeCap         *big.Int
+	signerType           key.SignerType
+	feeSpeed             FeeSpeed
+	nodeFeeFallback      bool
+	nodeGasPriceTTL      time.Duration
+	now                  func() time.Time
+	bundleSubmitter      BundleSubmitter
+	multicall            *common.Address
+	headWatchdog         *HeadWatchdog
+	chainID              *chainID
+	receiptBackoff       ReceiptBackoff
+}
+
+// chainID the chain ID set on the dynamic fee transactions, read once from the node when not configured
+type chainID struct {
+	mu    sync.Mutex
+	value *big.Int
+}
+
+// Option configures optional evmTransactor behaviour
+type Option func(t *evmTransactor)
+
+// WithConfirmationStrategy sets the ConfirmationStrategy used by VerifyTx,
+// by default the node is polled for the receipt
+func WithConfirmationStrategy(strategy ConfirmationStrategy) Option {
+	return func(t *evmTransactor) {
+		if strategy != nil {
+			t.confirmationStrategy = strategy
+		}
+	}
+}
+
+// WithMaxFeeCapMultiplier multiplies the max fee suggested by the gas tracker, giving the fee cap
+// headroom above the current base fee. The tip is never multiplied.
+func WithMaxFeeCapMultiplier(multiplier float64) Option {
+	return func(t *evmTransactor) {
+		if multiplier > 0 {
+			t.maxFeeCapMultiplier = multiplier
+		}
+	}
+}
+
+// WithGasLimitMultiplier multiplies the estimated gas limit of the calls running code, e.g. 1.2 for the tokens whose
+// gas depends on the balances, rebasing or fee-on-transfer ones, which the estimate may fall short of. The plain
+// native transfers and the given gas limits are never multiplied.
+func WithGasLimitMultiplier(multiplier float64) Option {
+	return func(t *evmTransactor) {
+		if multiplier > 0 {
+			t.gasLimitMultiplier = multiplier
+		}
+	}
+}
+
+// WithGasTipCap uses the given wei tip instead of the one suggested by the gas tracker
+func WithGasTipCap(gasTipCap *big.Int) Option {
+	return func(t *evmTransactor) {
+		t.gasTipCap = gasTipCap
+	}
+}
+
+// WithSignerType builds the transactions for the given signer type, key.SignerTypeLondon by default.
+// The key providers have to be created for the same signer type.
+func WithSignerType(signerType key.SignerType) Option {
+	return func(t *evmTransactor) {
+		if signerType != "" {
+			t.signerType = signerType
+		}
+	}
+}
+
+// WithMaxGasFeeCap limits the fee cap to the given wei, the tip is lowered to the fee cap when above it
+func WithMaxGasFeeCap(maxGasFeeCap *big.Int) Option {
+	return func(t *evmTransactor) {
+		t.maxGasFeeCap = maxGasFeeCap
+	}
+}
+
+// WithFeeSpeed takes the fees from the given tier of the gas tracker suggestion, FeeSpeedSafeLow by default
+func WithFeeSpeed(speed FeeSpeed) Option {
+	return func(t *evmTransactor) {
+		if speed != "" {
+			t.feeSpeed = speed
+		}
+	}
+}
+
+// WithNodeFeeFallback sets whether the fees are derived from the node when the gas tracker fails, enabled by default
+func WithNodeFeeFallback(enabled bool) Option {
+	return func(t *evmTransactor) {
+		t.nodeFeeFallback = enabled
+	}
+}
+
+// WithNodeGasPriceTTL sets how long the gas price and tip cap suggested by the node are reused by the transactions
+// created under a context of WithNodeGasPriceCache, 5 seconds by default. With a negative TTL they are read for
+// each transaction.
+func WithNodeGasPriceTTL(ttl time.Duration) Option {
+	return func(t *evmTransactor) {
+		if ttl != 0 {
+			t.nodeGasPriceTTL = ttl
+		}
+	}
+}
+
+// WithReceiptBackoff sets the intervals the default ConfirmationStrategy polls the receipts at,
+// every 10 seconds by default. It has no effect when a ConfirmationStrategy is set.
+func WithReceiptBackoff(backoff ReceiptBackoff) Option {
+	return func(t *evmTransactor) {
+		t.receiptBackoff = backoff
+	}
+}
+
+// WithChainID sets the chain ID of the dynamic fee transactions, read from the node on first use by default
+func WithChainID(id *big.Int) Option {
+	return func(t *evmTransactor) {
+		if id != nil {
+			t.chainID = &chainID{value: new(big.Int).Set(id)}
+		}
+	}
+}
+
+// WithBundleSubmitter sets the BundleSubmitter used by TransferBundle
+func WithBundleSubmitter(submitter BundleSubmitter) Option {
+	return func(t *evmTransactor) {
+		t.bundleSubmitter = submitter
+	}
+}
+
+// WithPreBroadcast sets a hook invoked before every broadcast, which can inspect or veto the transaction
+func WithPreBroadcast(preBroadcast PreBroadcastFunc) Option {
+	return func(t *evmTransactor) {
+		t.preBroadcast = preBroadcast
+	}
+}
+
+// WithMulticall reads the balances of BalancesOf with the Multicall3 contract at the given address,
+// e.g. Multicall3Address, instead of one call per account, which is done when it is nil
+func WithMulticall(address *common.Address) Option {
+	return func(t *evmTransactor) {
+		t.multicall = address
+	}
+}
+
+// WithHeadWatchdog makes VerifyTx and VerifyTxs return ErrChainStalled as soon as the watchdog reports
+// a stall of the chain, instead of waiting until their context is done
+func WithHeadWatchdog(watchdog *HeadWatchdog) Option {
+	return func(t *evmTransactor) {
+		t.headWatchdog = watchdog
+	}
 }
 
 // NewEvmTransactor utility method to create a EVM transactor
-func NewEvmTransactor(client *ethclient.Client, tracker GasTracker, nonceProvider nonce.Provider) (Transactor, error) {
-	return evmTransactor{
-		client:        client,
-		gasTracker:    tracker,
-		nonceProvider: nonceProvider,
-	}, nil
+func NewEvmTransactor(client client.Client, tracker GasTracker, nonceProvider nonce.Provider, opts ...Option) (Transactor, error) {
+	t := evmTransactor{
+		client:              client,
+		gasTracker:          tracker,
+		nonceProvider:       nonceProvider,
+		maxFeeCapMultiplier: 1,
+		gasLimitMultiplier:  1,
+		signerType:          key.SignerTypeLondon,
+		feeSpeed:            FeeSpeedSafeLow,
+		nodeFeeFallback:     true,
+		nodeGasPriceTTL:     defaultNodeGasPriceTTL,
+		now:                 time.Now,
+		chainID:             &chainID{},
+	}
+	for _, opt := range opts {
+		opt(&t)
+	}
+	if t.confirmationStrategy == nil {
+		t.confirmationStrategy = NewBackoffConfirmationStrategy(client, t.receiptBackoff)
+	}
+	_, err := ParseFeeSpeed(string(t.feeSpeed))
+	if err != nil {
+		return nil, err
+	}
+	_, err = key.NewSigner(t.signerType, big.NewInt(1))
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
 
 }
 func (t evmTransactor) Transfer(ctx context.Context, transaction *types.Transaction) error {
-	return t.client.SendTransaction(context.Background(), transaction)
+	if t.preBroadcast != nil {
+		err := t.preBroadcast(ctx, transaction)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrBroadcastVetoed, err)
+		}
+	}
+	return client.ClassifySendError(t.client.SendTransaction(ctx, transaction))
 }
 
 func (t evmTransactor) CreateERC20Tx(ctx context.Context, params TxParams) (*types.Transaction, error) {
-	senderAddress := *params.SenderKeyProvider.GetAddress()
+	receiverAddress, err := getReceiverAddress(params)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ERC20TransferData(*receiverAddress, params.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return t.createERC20Call(ctx, params, data)
+}
 
-	nonce, err := t.nonceProvider.GetNonce(ctx, params.SenderKeyProvider.GetAddress())
+func (t evmTransactor) CreateERC20ApproveTx(ctx context.Context, params TxParams) (*types.Transaction, error) {
+	spenderAddress, err := getReceiverAddress(params)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ERC20ApproveData(*spenderAddress, params.Amount)
 	if err != nil {
 		return nil, err
 	}
-	value := big.NewInt(0)
-	receiverAddress := *params.ReceiverKeyProvider.GetAddress()
-	token := common.HexToAddress(params.TokenAddr)
-	data := getTransactionData(receiverAddress, params.Amount)
+	return t.createERC20Call(ctx, params, data)
+}
 
-	gasLimit, err := t.client.EstimateGas(ctx, ethereum.CallMsg{
-		From: senderAddress,
-		To:   &token,
-		Data: data,
-	})
+func (t evmTransactor) CreateERC20TransferFromTx(ctx context.Context, params TxParams) (*types.Transaction, error) {
+	if params.Owner == nil {
+		return nil, fmt.Errorf("%w: owner", ErrNilAddress)
+	}
+	receiverAddress, err := getReceiverAddress(params)
 	if err != nil {
 		return nil, err
 	}
+	data, err := ERC20TransferFromData(*params.Owner, *receiverAddress, params.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return t.createERC20Call(ctx, params, data)
+}
 
-	feeTx := types.DynamicFeeTx{
-		Nonce:     nonce.Uint64(),
-		GasTipCap: params.GasTipCapValue,
-		GasFeeCap: params.GasFeeCapValue,
-		Gas:       gasLimit,
-		To:        &token,
-		Value:     value,
-		Data:      data,
+// createERC20Call creates a signed tx calling the token with the given calldata,
+// wrapped in the wallet call when a Wallet is set
+func (t evmTransactor) createERC20Call(ctx context.Context, params TxParams, data []byte) (*types.Transaction, error) {
+	msg, err := erc20CallMsg(params, data)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := t.getNonce(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	gasLimit, err := t.getGasLimit(ctx, params, msg)
+	if err != nil {
+		return nil, err
 	}
 
-	tx := types.NewTx(&feeTx)
-	transactOpts := params.SenderKeyProvider.GetTransactOpts()
-	tx, err = transactOpts.Signer(transactOpts.From, tx)
+	gasTipCap, gasFeeCap, err := t.applyNodeMinGasPrice(ctx, params.GasTipCapValue, params.GasFeeCapValue)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.newTx(ctx, nonce.Uint64(), gasTipCap, gasFeeCap, gasLimit, msg.To, big.NewInt(0), msg.Data)
+	if err != nil {
+		return nil, err
+	}
+	tx, err = t.signTx(ctx, params.SenderKeyProvider, tx)
 	if err != nil {
 		return nil, err
 	}
@@ -112,42 +430,92 @@ func (t evmTransactor) CreateERC20Tx(ctx context.Context, params TxParams) (*typ
 	return tx, nil
 }
 
+// erc20CallMsg returns the message calling the token with the given calldata,
+// wrapped in the wallet call when a Wallet is set
+func erc20CallMsg(params TxParams, data []byte) (ethereum.CallMsg, error) {
+	senderAddress, err := getSenderAddress(params)
+	if err != nil {
+		return ethereum.CallMsg{}, err
+	}
+	to := common.HexToAddress(params.TokenAddr)
+	if params.Wallet != nil {
+		if params.Executor == nil {
+			return ethereum.CallMsg{}, ErrMissingExecutor
+		}
+		data, err = params.Executor(*senderAddress, to, big.NewInt(0), data)
+		if err != nil {
+			return ethereum.CallMsg{}, fmt.Errorf("failed to build wallet calldata: %w", err)
+		}
+		to = *params.Wallet
+	}
+	return ethereum.CallMsg{
+		From: *senderAddress,
+		To:   &to,
+		Data: data,
+	}, nil
+}
+
+func (t evmTransactor) EstimateERC20Gas(ctx context.Context, params TxParams) (uint64, error) {
+	receiverAddress, err := getReceiverAddress(params)
+	if err != nil {
+		return 0, err
+	}
+	data, err := ERC20TransferData(*receiverAddress, params.Amount)
+	if err != nil {
+		return 0, err
+	}
+	msg, err := erc20CallMsg(params, data)
+	if err != nil {
+		return 0, err
+	}
+	return t.getGasLimit(ctx, params, msg)
+}
+
 func (t evmTransactor) CreateTx(ctx context.Context, params TxParams) (*types.Transaction, error) {
-	senderAddress := params.SenderKeyProvider.GetAddress()
-	receiverAddress := params.ReceiverKeyProvider.GetAddress()
+	receiverAddress, err := getReceiverAddress(params)
+	if err != nil {
+		return nil, err
+	}
 
-	nonce, err := t.nonceProvider.GetNonce(ctx, senderAddress)
+	// the amount is checked before the nonce is taken, a typo must not cost a nonce nor an RPC call
+	value, err := ParseUint256(params.Amount)
 	if err != nil {
 		return nil, err
 	}
 
-	value := new(big.Int)
-	value.SetString(params.Amount, 10)
+	nonce, err := t.getNonce(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	senderAddress, err := getSenderAddress(params)
+	if err != nil {
+		return nil, err
+	}
 
-	var data []byte
+	data := params.Data
 
-	gasLimit, err := t.client.EstimateGas(ctx, ethereum.CallMsg{
-		To:   receiverAddress,
-		Data: data,
+	// the estimation uses the actual data, as some chains price the calldata differently
+	gasLimit, err := t.getGasLimit(ctx, params, ethereum.CallMsg{
+		From:  *senderAddress,
+		To:    receiverAddress,
+		Value: value,
+		Data:  data,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	feeTx := types.DynamicFeeTx{
-		Nonce:     nonce.Uint64(),
-		GasTipCap: params.GasTipCapValue,
-		GasFeeCap: params.GasFeeCapValue,
-		Gas:       gasLimit,
-		To:        receiverAddress,
-		Value:     value,
-		Data:      data,
+	gasTipCap, gasFeeCap, err := t.applyNodeMinGasPrice(ctx, params.GasTipCapValue, params.GasFeeCapValue)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.newTx(ctx, nonce.Uint64(), gasTipCap, gasFeeCap, gasLimit, receiverAddress, value, data)
+	if err != nil {
+		return nil, err
 	}
 
-	tx := types.NewTx(&feeTx)
-
-	transactOpts := params.SenderKeyProvider.GetTransactOpts()
-	tx, err = transactOpts.Signer(transactOpts.From, tx)
+	tx, err = t.signTx(ctx, params.SenderKeyProvider, tx)
 	if err != nil {
 		return nil, err
 	}
@@ -155,40 +523,75 @@ func (t evmTransactor) CreateTx(ctx context.Context, params TxParams) (*types.Tr
 	return tx, nil
 }
 
-func (t evmTransactor) VerifyTx(ctx context.Context, txHash string) (bool, error) {
-	_, ok := ctx.Deadline()
-	if !ok {
-		return false, errors.New("context deadline not set")
+// newTx returns the unsigned transaction of the configured signer type, a legacy transaction
+// paying the fee cap as gas price for key.SignerTypeEIP155 and a dynamic fee one otherwise.
+// The chain ID of the dynamic fee transactions is set explicitly, so a signer of another chain refuses them.
+func (t evmTransactor) newTx(ctx context.Context, nonce uint64, gasTipCap *big.Int, gasFeeCap *big.Int, gas uint64, to *common.Address, value *big.Int, data []byte) (*types.Transaction, error) {
+	if t.signerType == key.SignerTypeEIP155 {
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: gasFeeCap,
+			Gas:      gas,
+			To:       to,
+			Value:    value,
+			Data:     data,
+		}), nil
 	}
-
-	if txHash == "" {
-		return false, errors.New("tx is empty")
+	chainID, err := t.getChainID(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gas,
+		To:        to,
+		Value:     value,
+		Data:      data,
+	}), nil
+}
 
-	queryTicker := time.NewTicker(10 * time.Second)
-	defer queryTicker.Stop()
-
-	for {
-		receipt, err := t.client.TransactionReceipt(ctx, common.HexToHash(txHash))
-		if receipt != nil {
-			if receipt.Status != 1 {
-				return false, nil
-			}
-			log.Ctx(ctx).Debug().Msgf("found transaction receipt for tx=%s: status=%d", txHash, receipt.Status)
-			return true, nil
-		}
+// getChainID returns the configured chain ID, reading it from the node the first time when not configured
+func (t evmTransactor) getChainID(ctx context.Context) (*big.Int, error) {
+	t.chainID.mu.Lock()
+	defer t.chainID.mu.Unlock()
+	if t.chainID.value == nil {
+		value, err := t.client.ChainID(ctx)
 		if err != nil {
-			log.Ctx(ctx).Warn().Err(err).Str("tx", txHash).Msg("failed to get receipt for tx")
+			return nil, fmt.Errorf("failed to get chain id: %w", err)
 		}
+		t.chainID.value = value
+	}
+	return new(big.Int).Set(t.chainID.value), nil
+}
 
-		select {
-		case <-ctx.Done():
-			log.Ctx(ctx).Warn().Err(ctx.Err()).Str("tx", txHash).Msg("failed to get receipt status")
-			return false, ctx.Err()
-		case <-queryTicker.C:
-		}
+// signTx signs the transaction with the provider, which has to be created for the configured signer type
+func (t evmTransactor) signTx(ctx context.Context, provider key.Provider, tx *types.Transaction) (*types.Transaction, error) {
+	signerType := key.GetSignerType(provider)
+	if signerType != t.signerType {
+		return nil, fmt.Errorf("%w: provider %s, transactor %s", ErrSignerTypeMismatch, signerType, t.signerType)
 	}
+	return key.SignTx(ctx, provider, tx)
+}
 
+func (t evmTransactor) VerifyTx(ctx context.Context, txHash string) (*types.Receipt, error) {
+	_, ok := ctx.Deadline()
+	if !ok {
+		return nil, errors.New("context deadline not set")
+	}
+	var receipt *types.Receipt
+	err := t.headWatchdog.Guard(ctx, func(ctx context.Context) error {
+		var err error
+		receipt, err = t.confirmationStrategy.WaitConfirmed(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (t evmTransactor) GetTxReceipt(ctx context.Context, txHash string) (*types.Receipt, error) {
+	return t.client.TransactionReceipt(ctx, common.HexToHash(txHash))
 }
 
 func (t evmTransactor) BalanceAt(ctx context.Context, accountAddr common.Address) (*big.Int, error) {
@@ -200,6 +603,31 @@ func (t evmTransactor) BalanceAt(ctx context.Context, accountAddr common.Address
 	return balance, nil
 }
 
+func (t evmTransactor) NonceBacklog(ctx context.Context, accountAddr common.Address) (uint64, uint64, error) {
+	latest, err := t.client.NonceAt(ctx, accountAddr, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	pending, err := t.client.PendingNonceAt(ctx, accountAddr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	// a node lagging behind another one of a failover may return a pending nonce below the latest one
+	if pending < latest {
+		return latest, 0, nil
+	}
+	return latest, pending - latest, nil
+}
+
+func (t evmTransactor) CodeAt(ctx context.Context, accountAddr common.Address) ([]byte, error) {
+	code, err := t.client.CodeAt(ctx, accountAddr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code: %w", err)
+	}
+
+	return code, nil
+}
+
 func (t evmTransactor) BalanceOf(ctx context.Context, accountAddr common.Address, erc20Address string) (*big.Int, error) {
 	caller, err := NewIERC20Caller(common.HexToAddress(erc20Address), t.client)
 	if err != nil {
@@ -214,47 +642,307 @@ func (t evmTransactor) BalanceOf(ctx context.Context, accountAddr common.Address
 	return balance, nil
 }
 
+func (t evmTransactor) Decimals(ctx context.Context, erc20Address string) (uint8, error) {
+	token := common.HexToAddress(erc20Address)
+	result, err := t.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &token,
+		Data: tokens.SelectorDecimals[:],
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+	decimals := new(big.Int).SetBytes(result)
+	if len(result) == 0 || !decimals.IsUint64() || decimals.Uint64() > math.MaxUint8 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidDecimals, erc20Address)
+	}
+	return uint8(decimals.Uint64()), nil
+}
+
+func (t evmTransactor) BalanceOfAt(ctx context.Context, accountAddr common.Address, erc20Address string, blockNumber *big.Int) (*big.Int, error) {
+	caller, err := NewIERC20Caller(common.HexToAddress(erc20Address), t.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IERC20Caller: %w", err)
+	}
+	balance, err := caller.BalanceOf(&bind.CallOpts{Context: ctx, BlockNumber: blockNumber}, accountAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+func (t evmTransactor) Allowance(ctx context.Context, owner common.Address, spender common.Address, erc20Address string) (*big.Int, error) {
+	caller, err := NewIERC20Caller(common.HexToAddress(erc20Address), t.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IERC20Caller: %w", err)
+	}
+	allowance, err := caller.Allowance(&bind.CallOpts{Context: ctx}, owner, spender)
+	if err != nil {
+		return nil, err
+	}
+
+	return allowance, nil
+}
+
+func (t evmTransactor) CallAddressCheck(ctx context.Context, contract common.Address, signature string, args ...common.Address) (bool, error) {
+	words := make([][]byte, 0, len(args))
+	for _, arg := range args {
+		words = append(words, common.LeftPadBytes(arg.Bytes(), 32))
+	}
+	result, err := t.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &contract,
+		Data: getCallData(signature, words...),
+	}, nil)
+	if err != nil {
+		if strings.Contains(err.Error(), executionReverted) {
+			return false, nil
+		}
+		return false, err
+	}
+	return new(big.Int).SetBytes(result).Sign() != 0, nil
+}
+
 func (t evmTransactor) GetGasCapValues(ctx context.Context) (*big.Int, *big.Int, error) {
+	gasTipCapValue, gasFeeCapValue, err := t.getTrackerGasCapValues(ctx)
+	if err != nil {
+		if !t.nodeFeeFallback {
+			return nil, nil, err
+		}
+		log.Ctx(ctx).Warn().Err(err).Msg("gas tracker unavailable, falling back to node fee suggestions")
+		gasTipCapValue, gasFeeCapValue, err = t.getNodeGasCapValues(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if t.gasTipCap != nil {
+		gasTipCapValue = new(big.Int).Set(t.gasTipCap)
+	}
+	if t.maxGasFeeCap != nil && gasFeeCapValue.Cmp(t.maxGasFeeCap) > 0 {
+		gasFeeCapValue = new(big.Int).Set(t.maxGasFeeCap)
+	}
+	if gasTipCapValue.Cmp(gasFeeCapValue) > 0 {
+		gasTipCapValue = new(big.Int).Set(gasFeeCapValue)
+	}
+	return gasTipCapValue, gasFeeCapValue, nil
+}
+
+// getTrackerGasCapValues returns the tip and fee cap of the configured tier of the gas tracker suggestion
+func (t evmTransactor) getTrackerGasCapValues(ctx context.Context) (*big.Int, *big.Int, error) {
 	gasTrackerResponse, err := t.gasTracker.GetSuggestedGasPrice(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	gasTipCapValue, ok := new(big.Int).SetString(formatFloat(gasTrackerResponse.SafeLow.MaxPriorityFee, 9), 10)
-	if !ok {
-		return nil, nil, errors.New("invalid gasTipCapValue")
+	maxPriorityFee, maxFee := gasTrackerResponse.Tier(t.feeSpeed)
+	gasTipCapValue, err := GweiToWei(maxPriorityFee)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid gasTipCapValue: %w", err)
 	}
-	gasFeeCapValue, ok := new(big.Int).SetString(formatFloat(gasTrackerResponse.SafeLow.MaxFee, 9), 10)
-	if !ok {
-		return nil, nil, errors.New("invalid gasFeeCapValue")
+	gasFeeCapValue, err := gweiToWeiMultiplied(maxFee, t.maxFeeCapMultiplier)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid gasFeeCapValue: %w", err)
 	}
 	return gasTipCapValue, gasFeeCapValue, nil
 }
 
-func getTransactionData(toAddress common.Address, amountWei string) []byte {
-	transferFnSignature := []byte("transfer(address,uint256)")
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write(transferFnSignature)
-	methodID := hash.Sum(nil)[:4]
+// getNodeGasCapValues returns the tip suggested by the node and a fee cap of twice the latest base fee
+// plus the tip, which stays above the base fee for several full blocks. The legacy transactions of
+// key.SignerTypeEIP155 pay a single gas price, the one suggested by the node, which also works on the
+// chains without base fee.
+func (t evmTransactor) getNodeGasCapValues(ctx context.Context) (*big.Int, *big.Int, error) {
+	if t.signerType == key.SignerTypeEIP155 {
+		gasPrice, err := t.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get node suggested gas price: %w", err)
+		}
+		return gasPrice, new(big.Int).Set(gasPrice), nil
+	}
+	gasTipCapValue, err := t.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get node suggested gas tip cap: %w", err)
+	}
+	header, err := t.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get latest block header: %w", err)
+	}
+	if header.BaseFee == nil {
+		return nil, nil, errors.New("latest block has no base fee")
+	}
+	gasFeeCapValue := new(big.Int).Mul(header.BaseFee, big.NewInt(2))
+	gasFeeCapValue.Add(gasFeeCapValue, gasTipCapValue)
+	return gasTipCapValue, gasFeeCapValue, nil
+}
 
-	paddedAddress := common.LeftPadBytes(toAddress.Bytes(), 32)
+func (t evmTransactor) getNonce(ctx context.Context, params TxParams) (*big.Int, error) {
+	if params.Nonce != nil {
+		return params.Nonce, nil
+	}
+	senderAddress, err := getSenderAddress(params)
+	if err != nil {
+		return nil, err
+	}
+	return t.nonceProvider.GetNonce(ctx, senderAddress)
+}
 
-	amount := new(big.Int)
-	amount.SetString(amountWei, 10)
+// getGasLimit returns the configured gas limit when set, after checking it covers the
+// intrinsic gas of the call, otherwise the gas limit is estimated
+func (t evmTransactor) getGasLimit(ctx context.Context, params TxParams, msg ethereum.CallMsg) (uint64, error) {
+	intrinsicGas := getIntrinsicGas(msg.Data)
+	if params.GasLimit == 0 {
+		gasLimit, err := t.client.EstimateGas(ctx, msg)
+		if err != nil || gasLimit <= intrinsicGas || t.gasLimitMultiplier == 1 {
+			return gasLimit, err
+		}
+		return uint64(math.Ceil(float64(gasLimit) * t.gasLimitMultiplier)), nil
+	}
 
-	paddedAmount := common.LeftPadBytes(amount.Bytes(), 32)
+	if params.GasLimit < intrinsicGas {
+		return 0, fmt.Errorf("%w: %d < %d", ErrGasLimitBelowIntrinsic, params.GasLimit, intrinsicGas)
+	}
+	return params.GasLimit, nil
+}
 
-	var data []byte
-	data = append(data, methodID...)
-	data = append(data, paddedAddress...)
-	data = append(data, paddedAmount...)
-	return data
+// getIntrinsicGas returns the minimum gas needed by a message call with the given data
+func getIntrinsicGas(data []byte) uint64 {
+	gas := ethparams.TxGas
+	for _, b := range data {
+		if b == 0 {
+			gas += ethparams.TxDataZeroGas
+		} else {
+			gas += ethparams.TxDataNonZeroGasEIP2028
+		}
+	}
+	return gas
 }
 
-func formatFloat(num float64, decimal int) string {
-	d := float64(1)
-	if decimal > 0 {
-		d = math.Pow10(decimal)
+// applyNodeMinGasPrice bumps the given caps so they are not below the gas price and tip cap
+// suggested by the node, otherwise the node may silently drop the transaction, failing with
+// ErrNodeMinGasPriceAboveCap when the bump exceeds the configured max gas fee cap.
+// The given caps are used unchanged when the node can not be queried. The tip is not checked for the legacy
+// transactions, whose gas price is the fee cap, the nodes without base fee not suggesting any tip.
+func (t evmTransactor) applyNodeMinGasPrice(ctx context.Context, gasTipCap *big.Int, gasFeeCap *big.Int) (*big.Int, *big.Int, error) {
+	minGasPrice, minGasTipCap, err := t.nodeMinGasPrices(ctx)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to get node suggested gas price")
+		return gasTipCap, gasFeeCap, nil
+	}
+	if minGasTipCap == nil {
+		minGasTipCap = gasTipCap
+	}
+
+	givenGasFeeCap := gasFeeCap
+	if gasFeeCap.Cmp(minGasPrice) < 0 {
+		log.Ctx(ctx).Info().
+			Str("gasFeeCap", gasFeeCap.String()).
+			Str("minGasPrice", minGasPrice.String()).
+			Msg("bumping gas fee cap to node minimum")
+		gasFeeCap = minGasPrice
+	}
+	if gasTipCap.Cmp(minGasTipCap) < 0 {
+		log.Ctx(ctx).Info().
+			Str("gasTipCap", gasTipCap.String()).
+			Str("minGasTipCap", minGasTipCap.String()).
+			Msg("bumping gas tip cap to node minimum")
+		gasTipCap = minGasTipCap
+	}
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		gasFeeCap = gasTipCap
 	}
-	return strconv.FormatFloat(math.Round(num*d), 'f', -1, 64)
+	if t.maxGasFeeCap != nil && gasFeeCap.Cmp(givenGasFeeCap) > 0 && gasFeeCap.Cmp(t.maxGasFeeCap) > 0 {
+		return nil, nil, fmt.Errorf("%w: node minimum %s, max %s", ErrNodeMinGasPriceAboveCap, gasFeeCap, t.maxGasFeeCap)
+	}
+	return gasTipCap, gasFeeCap, nil
+}
+
+type nodeGasPricesKey struct{}
+
+// defaultNodeGasPriceTTL how long the gas price suggested by the node is reused, a few blocks on the fast chains
+const defaultNodeGasPriceTTL = 5 * time.Second
+
+// nodeGasPrices the gas price and tip cap suggested by the node, kept for the transactions of a context
+type nodeGasPrices struct {
+	mu        sync.Mutex
+	gasPrice  *big.Int
+	gasTipCap *big.Int
+	readAt    time.Time
+}
+
+// WithNodeGasPriceCache returns a context under which the gas price and tip cap suggested by the node, which the
+// fees of the created transactions are checked against, are reused for the TTL set by WithNodeGasPriceTTL, e.g. by
+// the transactions of a batch created in a burst, and read again afterwards, as the node minimum moves with the
+// chain during long runs. A failed read is not kept, the next transaction reads them again.
+func WithNodeGasPriceCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nodeGasPricesKey{}, &nodeGasPrices{})
+}
+
+// nodeMinGasPrices returns the gas price and tip cap suggested by the node, from the cache of the context when set.
+// The tip cap is nil for the legacy transactions.
+func (t evmTransactor) nodeMinGasPrices(ctx context.Context) (*big.Int, *big.Int, error) {
+	cache, ok := ctx.Value(nodeGasPricesKey{}).(*nodeGasPrices)
+	if !ok || t.nodeGasPriceTTL < 0 {
+		return t.readNodeMinGasPrices(ctx)
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	now := t.now()
+	if cache.gasPrice == nil || now.Sub(cache.readAt) >= t.nodeGasPriceTTL {
+		gasPrice, gasTipCap, err := t.readNodeMinGasPrices(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		cache.gasPrice, cache.gasTipCap, cache.readAt = gasPrice, gasTipCap, now
+	}
+	return cache.gasPrice, cache.gasTipCap, nil
+}
+
+// readNodeMinGasPrices reads the gas price and tip cap suggested by the node, the tip cap only for the dynamic
+// fee transactions
+func (t evmTransactor) readNodeMinGasPrices(ctx context.Context) (*big.Int, *big.Int, error) {
+	gasPrice, err := t.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if t.signerType == key.SignerTypeEIP155 {
+		return gasPrice, nil, nil
+	}
+	gasTipCap, err := t.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get node suggested gas tip cap: %w", err)
+	}
+	return gasPrice, gasTipCap, nil
+}
+
+func getReceiverAddress(params TxParams) (*common.Address, error) {
+	if params.ReceiverAddress != nil {
+		return params.ReceiverAddress, nil
+	}
+	if params.ReceiverKeyProvider == nil {
+		return nil, errors.New("receiver not set")
+	}
+	receiverAddress := params.ReceiverKeyProvider.GetAddress()
+	if receiverAddress == nil {
+		return nil, fmt.Errorf("%w: receiver", ErrNilAddress)
+	}
+	return receiverAddress, nil
+}
+
+func getSenderAddress(params TxParams) (*common.Address, error) {
+	if params.SenderKeyProvider == nil {
+		return nil, errors.New("sender not set")
+	}
+	senderAddress := params.SenderKeyProvider.GetAddress()
+	if senderAddress == nil {
+		return nil, fmt.Errorf("%w: sender", ErrNilAddress)
+	}
+	return senderAddress, nil
+}
+
+// getCallData encodes the call of the method with the given signature and 32 byte words as arguments
+func getCallData(signature string, words ...[]byte) []byte {
+	data := keccak256([]byte(signature))[:4]
+	for _, word := range words {
+		data = append(data, word...)
+	}
+	return data
 }
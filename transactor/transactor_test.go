@@ -0,0 +1,399 @@
+package transactor
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/welthee/dobermann/client"
+	"github.com/welthee/dobermann/key"
+	"github.com/welthee/dobermann/key/pk"
+	"github.com/welthee/dobermann/nonce"
+)
+
+// fakeClient a node answering the calls used to create transactions, the other calls panic
+type fakeClient struct {
+	client.Client
+	chainID   *big.Int
+	gasPrice  *big.Int
+	gasTipCap *big.Int
+	// baseFee of the latest header, nil for the chains without base fee
+	baseFee *big.Int
+	gas     uint64
+	// calls the number of calls of each method
+	calls map[string]int
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		chainID:   big.NewInt(137),
+		gasPrice:  big.NewInt(30_000_000_000),
+		gasTipCap: big.NewInt(2_000_000_000),
+		baseFee:   big.NewInt(28_000_000_000),
+		gas:       60_000,
+		calls:     map[string]int{},
+	}
+}
+
+func (c *fakeClient) ChainID(ctx context.Context) (*big.Int, error) {
+	c.calls["ChainID"]++
+	return new(big.Int).Set(c.chainID), nil
+}
+
+func (c *fakeClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	c.calls["EstimateGas"]++
+	return c.gas, nil
+}
+
+func (c *fakeClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	c.calls["SuggestGasPrice"]++
+	return new(big.Int).Set(c.gasPrice), nil
+}
+
+func (c *fakeClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	c.calls["SuggestGasTipCap"]++
+	return new(big.Int).Set(c.gasTipCap), nil
+}
+
+func (c *fakeClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.calls["HeaderByNumber"]++
+	return &types.Header{Number: big.NewInt(1), BaseFee: c.baseFee}, nil
+}
+
+// unavailableGasTracker a gas tracker which can not be reached
+type unavailableGasTracker struct{}
+
+func (unavailableGasTracker) GetSuggestedGasPrice(ctx context.Context) (*GasTrackerResponse, error) {
+	return nil, errors.New("gas tracker unavailable")
+}
+
+// newTestTransactor returns a transactor of the node with the nonce 0
+func newTestTransactor(t *testing.T, node client.Client, opts ...Option) evmTransactor {
+	t.Helper()
+	transactor, err := NewEvmTransactor(node, unavailableGasTracker{}, nonce.NewFixedNonceProvider(big.NewInt(0)), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transactor.(evmTransactor)
+}
+
+// newTestKeyProvider returns the key provider of a random private key of the chain
+func newTestKeyProvider(t *testing.T, chainID *big.Int, signerType key.SignerType) key.Provider {
+	t.Helper()
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err := pk.NewPrivateKeyProviderWithSigner(hex.EncodeToString(crypto.FromECDSA(privateKey)), chainID, signerType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+// word the hex ABI word of the value
+func word(value int64) string {
+	return hex.EncodeToString(common32(big.NewInt(value).Bytes()))
+}
+
+func common32(b []byte) []byte {
+	padded := make([]byte, 32)
+	copy(padded[32-len(b):], b)
+	return padded
+}
+
+func TestCreateERC1155BatchTx(t *testing.T) {
+	node := newFakeClient()
+	transactor := newTestTransactor(t, node)
+	sender := newTestKeyProvider(t, node.chainID, key.SignerTypeLondon)
+	receiver := newTestKeyProvider(t, node.chainID, key.SignerTypeLondon)
+
+	tx, err := transactor.CreateERC1155BatchTx(context.Background(), TxParams{
+		TokenAddr:           "0x3333333333333333333333333333333333333333",
+		SenderKeyProvider:   sender,
+		ReceiverKeyProvider: receiver,
+		TokenAmounts: []TokenAmount{
+			{ID: big.NewInt(1), Amount: big.NewInt(10)},
+			{ID: big.NewInt(42), Amount: big.NewInt(7)},
+		},
+		GasTipCapValue: big.NewInt(2_000_000_000),
+		GasFeeCapValue: big.NewInt(60_000_000_000),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// safeBatchTransferFrom(address,address,uint256[],uint256[],bytes) encoded word by word: the head holds the
+	// addresses and the offsets of the dynamic arguments, followed by their length prefixed contents
+	want := "2eb2c2d6" +
+		hex.EncodeToString(common32(sender.GetAddress().Bytes())) +
+		hex.EncodeToString(common32(receiver.GetAddress().Bytes())) +
+		word(0xa0) + word(0x100) + word(0x160) +
+		word(2) + word(1) + word(42) +
+		word(2) + word(10) + word(7) +
+		word(0)
+	if got := hex.EncodeToString(tx.Data()); got != want {
+		t.Fatalf("calldata\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCreateERC1155BatchTxRejectsInvalidAmounts(t *testing.T) {
+	node := newFakeClient()
+	transactor := newTestTransactor(t, node)
+	params := TxParams{
+		TokenAddr:           "0x3333333333333333333333333333333333333333",
+		SenderKeyProvider:   newTestKeyProvider(t, node.chainID, key.SignerTypeLondon),
+		ReceiverKeyProvider: newTestKeyProvider(t, node.chainID, key.SignerTypeLondon),
+	}
+	tests := map[string][]TokenAmount{
+		"none":     nil,
+		"nil id":   {{Amount: big.NewInt(1)}},
+		"negative": {{ID: big.NewInt(1), Amount: big.NewInt(-1)}},
+		"overflow": {{ID: new(big.Int).Add(MaxUint256, big.NewInt(1)), Amount: big.NewInt(1)}},
+	}
+	for name, tokenAmounts := range tests {
+		t.Run(name, func(t *testing.T) {
+			params.TokenAmounts = tokenAmounts
+			_, err := transactor.CreateERC1155BatchTx(context.Background(), params)
+			if err == nil {
+				t.Fatal("invalid token amounts accepted")
+			}
+		})
+	}
+}
+
+func TestCreateERC721Tx(t *testing.T) {
+	node := newFakeClient()
+	transactor := newTestTransactor(t, node)
+	sender := newTestKeyProvider(t, node.chainID, key.SignerTypeLondon)
+	receiver := newTestKeyProvider(t, node.chainID, key.SignerTypeLondon)
+
+	tests := []struct {
+		name    string
+		tokenID *big.Int
+		wantErr error
+	}{
+		{name: "token", tokenID: big.NewInt(42)},
+		{name: "token zero", tokenID: big.NewInt(0)},
+		{name: "no token id", wantErr: ErrMissingTokenID},
+		{name: "overflow", tokenID: new(big.Int).Add(MaxUint256, big.NewInt(1)), wantErr: ErrUint256Overflow},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx, err := transactor.CreateERC721Tx(context.Background(), TxParams{
+				TokenAddr:           "0x3333333333333333333333333333333333333333",
+				SenderKeyProvider:   sender,
+				ReceiverKeyProvider: receiver,
+				TokenID:             test.tokenID,
+				GasTipCapValue:      big.NewInt(2_000_000_000),
+				GasFeeCapValue:      big.NewInt(60_000_000_000),
+			})
+			if !errors.Is(err, test.wantErr) || (test.wantErr == nil && err != nil) {
+				t.Fatalf("error %v, want %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			// safeTransferFrom(address,address,uint256) of the sender to the receiver, without value
+			want := "42842e0e" +
+				hex.EncodeToString(common32(sender.GetAddress().Bytes())) +
+				hex.EncodeToString(common32(receiver.GetAddress().Bytes())) +
+				word(test.tokenID.Int64())
+			if got := hex.EncodeToString(tx.Data()); got != want || tx.Value().Sign() != 0 {
+				t.Fatalf("calldata\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestNodeFeeFallbackTxTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		signerType key.SignerType
+		baseFee    *big.Int
+		txType     uint8
+		gasTipCap  *big.Int
+		gasFeeCap  *big.Int
+		// tipCalls the number of eth_maxPriorityFeePerGas calls
+		tipCalls int
+	}{
+		{
+			// a chain without base fee, the node suggested gas price is the single gas price of the transaction
+			name:       "legacy",
+			signerType: key.SignerTypeEIP155,
+			txType:     types.LegacyTxType,
+			gasTipCap:  big.NewInt(30_000_000_000),
+			gasFeeCap:  big.NewInt(30_000_000_000),
+		},
+		{
+			name:       "dynamic fee",
+			signerType: key.SignerTypeLondon,
+			baseFee:    big.NewInt(28_000_000_000),
+			txType:     types.DynamicFeeTxType,
+			gasTipCap:  big.NewInt(2_000_000_000),
+			gasFeeCap:  big.NewInt(58_000_000_000),
+			tipCalls:   2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := newFakeClient()
+			node.baseFee = test.baseFee
+			transactor := newTestTransactor(t, node, WithSignerType(test.signerType))
+			sender := newTestKeyProvider(t, node.chainID, test.signerType)
+			ctx := WithNodeGasPriceCache(context.Background())
+
+			gasTipCap, gasFeeCap, err := transactor.GetGasCapValues(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx, err := transactor.CreateTx(ctx, TxParams{
+				SenderKeyProvider:   sender,
+				ReceiverKeyProvider: newTestKeyProvider(t, node.chainID, test.signerType),
+				Amount:              "1",
+				GasTipCapValue:      gasTipCap,
+				GasFeeCapValue:      gasFeeCap,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tx.Type() != test.txType {
+				t.Fatalf("tx type %d, want %d", tx.Type(), test.txType)
+			}
+			if tx.GasTipCap().Cmp(test.gasTipCap) != 0 || tx.GasFeeCap().Cmp(test.gasFeeCap) != 0 {
+				t.Fatalf("tip %s, fee cap %s, want %s, %s", tx.GasTipCap(), tx.GasFeeCap(), test.gasTipCap, test.gasFeeCap)
+			}
+			if test.txType == types.LegacyTxType && tx.GasPrice().Cmp(test.gasFeeCap) != 0 {
+				t.Fatalf("gas price %s, want %s", tx.GasPrice(), test.gasFeeCap)
+			}
+			if node.calls["SuggestGasTipCap"] != test.tipCalls {
+				t.Fatalf("%d tip cap calls, want %d", node.calls["SuggestGasTipCap"], test.tipCalls)
+			}
+			signer, err := key.NewSigner(test.signerType, node.chainID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, err := types.Sender(signer, tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if from != *sender.GetAddress() {
+				t.Fatalf("sender %s, want %s", from.Hex(), sender.GetAddress().Hex())
+			}
+		})
+	}
+}
+
+func TestCreateTxChainID(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		// chainIDCalls the number of eth_chainId calls for the two transactions
+		chainIDCalls int
+	}{
+		{name: "configured", opts: []Option{WithChainID(big.NewInt(137))}},
+		{name: "read from the node once", chainIDCalls: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := newFakeClient()
+			transactor := newTestTransactor(t, node, test.opts...)
+			params := TxParams{
+				SenderKeyProvider:   newTestKeyProvider(t, node.chainID, key.SignerTypeLondon),
+				ReceiverKeyProvider: newTestKeyProvider(t, node.chainID, key.SignerTypeLondon),
+				Amount:              "1",
+				GasTipCapValue:      big.NewInt(2_000_000_000),
+				GasFeeCapValue:      big.NewInt(60_000_000_000),
+			}
+			for i := 0; i < 2; i++ {
+				tx, err := transactor.CreateTx(context.Background(), params)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tx.ChainId().Cmp(node.chainID) != 0 {
+					t.Fatalf("chain id %s, want %s", tx.ChainId(), node.chainID)
+				}
+			}
+			if node.calls["ChainID"] != test.chainIDCalls {
+				t.Fatalf("%d chain id calls, want %d", node.calls["ChainID"], test.chainIDCalls)
+			}
+		})
+	}
+}
+
+func TestCreateTxChainIDMismatch(t *testing.T) {
+	node := newFakeClient()
+	transactor := newTestTransactor(t, node, WithChainID(node.chainID))
+	// the key provider signs for another chain than the transactor's
+	_, err := transactor.CreateTx(context.Background(), TxParams{
+		SenderKeyProvider:   newTestKeyProvider(t, big.NewInt(1), key.SignerTypeLondon),
+		ReceiverKeyProvider: newTestKeyProvider(t, node.chainID, key.SignerTypeLondon),
+		Amount:              "1",
+		GasTipCapValue:      big.NewInt(2_000_000_000),
+		GasFeeCapValue:      big.NewInt(60_000_000_000),
+	})
+	if !errors.Is(err, types.ErrInvalidChainId) {
+		t.Fatalf("error %v, want %v", err, types.ErrInvalidChainId)
+	}
+}
+
+func TestNodeMinGasPriceRisesMidRun(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		// elapsed between the two transactions
+		elapsed time.Duration
+		// wantGasFeeCap of the second transaction
+		wantGasFeeCap *big.Int
+		wantReads     int
+	}{
+		{name: "within the ttl", ttl: 5 * time.Second, elapsed: time.Second, wantGasFeeCap: big.NewInt(30_000_000_000), wantReads: 1},
+		{name: "after the ttl", ttl: 5 * time.Second, elapsed: 5 * time.Second, wantGasFeeCap: big.NewInt(45_000_000_000), wantReads: 2},
+		{name: "each transaction", ttl: -1, wantGasFeeCap: big.NewInt(45_000_000_000), wantReads: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := newFakeClient()
+			transactor := newTestTransactor(t, node, WithNodeGasPriceTTL(test.ttl))
+			now := time.Unix(1700000000, 0)
+			transactor.now = func() time.Time { return now }
+			ctx := WithNodeGasPriceCache(context.Background())
+			params := TxParams{
+				SenderKeyProvider:   newTestKeyProvider(t, node.chainID, key.SignerTypeLondon),
+				ReceiverKeyProvider: newTestKeyProvider(t, node.chainID, key.SignerTypeLondon),
+				Amount:              "1",
+				GasTipCapValue:      big.NewInt(2_000_000_000),
+				GasFeeCapValue:      big.NewInt(20_000_000_000),
+			}
+
+			tx, err := transactor.CreateTx(ctx, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tx.GasFeeCap().Cmp(node.gasPrice) != 0 {
+				t.Fatalf("first fee cap %s, want the node minimum %s", tx.GasFeeCap(), node.gasPrice)
+			}
+
+			// the node minimum rises during the run
+			node.gasPrice = big.NewInt(45_000_000_000)
+			now = now.Add(test.elapsed)
+			tx, err = transactor.CreateTx(ctx, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tx.GasFeeCap().Cmp(test.wantGasFeeCap) != 0 {
+				t.Fatalf("second fee cap %s, want %s", tx.GasFeeCap(), test.wantGasFeeCap)
+			}
+			if node.calls["SuggestGasPrice"] != test.wantReads {
+				t.Fatalf("%d gas price reads, want %d", node.calls["SuggestGasPrice"], test.wantReads)
+			}
+		})
+	}
+}
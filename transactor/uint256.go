@@ -0,0 +1,61 @@
+package transactor
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrInvalidAmount the amount is not a non-negative decimal integer
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrUint256Overflow the value does not fit in the 256 bits of an EVM word
+	ErrUint256Overflow = errors.New("value exceeds uint256")
+)
+
+// MaxUint256 the largest value of an EVM word, 2^256 - 1
+var MaxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// ParseUint256 parses the decimal wei amount, refusing negative values and values above MaxUint256
+func ParseUint256(amount string) (*big.Int, error) {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok || len(amount) == 0 || amount[0] == '+' {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	err := CheckUint256(value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// CheckUint256 returns an error when the value is negative or above MaxUint256
+func CheckUint256(value *big.Int) error {
+	if value.Sign() < 0 {
+		return fmt.Errorf("%w: negative %s", ErrInvalidAmount, value)
+	}
+	if value.BitLen() > 256 {
+		return fmt.Errorf("%w: %s", ErrUint256Overflow, value)
+	}
+	return nil
+}
+
+// uint256Word encodes the value as an ABI word, common.LeftPadBytes would return the longer bytes as they are
+func uint256Word(value *big.Int) ([]byte, error) {
+	err := CheckUint256(value)
+	if err != nil {
+		return nil, err
+	}
+	return common.LeftPadBytes(value.Bytes(), 32), nil
+}
+
+// amountWord encodes the decimal wei amount as an ABI word
+func amountWord(amount string) ([]byte, error) {
+	value, err := ParseUint256(amount)
+	if err != nil {
+		return nil, err
+	}
+	return uint256Word(value)
+}
@@ -0,0 +1,98 @@
+package transactor
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// TxState the state of a transaction checked by VerifyTxs
+type TxState string
+
+const (
+	// TxStatePending the transaction is not mined or does not have enough confirmations yet
+	TxStatePending TxState = "pending"
+	// TxStateConfirmed the transaction succeeded and has enough confirmations
+	TxStateConfirmed TxState = "confirmed"
+	// TxStateReverted the transaction reverted and has enough confirmations
+	TxStateReverted TxState = "reverted"
+)
+
+// VerifyTxs polls the receipts of all the unresolved hashes once per tick until each of them
+// has the given number of confirmations or the context is done. The receipts are fetched again
+// on every tick, so a transaction removed by a reorg goes back to pending.
+func (t evmTransactor) VerifyTxs(ctx context.Context, hashes []string, confirmations uint64) (map[string]TxState, error) {
+	var states map[string]TxState
+	err := t.headWatchdog.Guard(ctx, func(ctx context.Context) error {
+		var err error
+		states, err = t.verifyTxs(ctx, hashes, confirmations)
+		return err
+	})
+	return states, err
+}
+
+func (t evmTransactor) verifyTxs(ctx context.Context, hashes []string, confirmations uint64) (map[string]TxState, error) {
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	states := make(map[string]TxState, len(hashes))
+	for _, hash := range hashes {
+		states[hash] = TxStatePending
+	}
+
+	ticker := time.NewTicker(defaultPollInterval)
+	defer ticker.Stop()
+
+	for {
+		pending, err := t.checkTxs(ctx, states, confirmations)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to get block number")
+		}
+		if pending == 0 {
+			return states, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return states, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkTxs updates the state of the pending hashes and returns how many are still pending
+func (t evmTransactor) checkTxs(ctx context.Context, states map[string]TxState, confirmations uint64) (int, error) {
+	head, err := t.client.BlockNumber(ctx)
+	if err != nil {
+		return len(states), err
+	}
+
+	pending := 0
+	for hash, state := range states {
+		if state != TxStatePending {
+			continue
+		}
+		receipt, err := t.client.TransactionReceipt(ctx, common.HexToHash(hash))
+		if receipt == nil || receipt.BlockNumber == nil {
+			if err != nil {
+				log.Ctx(ctx).Debug().Err(err).Str("tx", hash).Msg("receipt not found")
+			}
+			pending++
+			continue
+		}
+		mined := receipt.BlockNumber.Uint64()
+		if head < mined || head-mined+1 < confirmations {
+			pending++
+			continue
+		}
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			states[hash] = TxStateConfirmed
+		} else {
+			states[hash] = TxStateReverted
+		}
+	}
+	return pending, nil
+}
@@ -0,0 +1,213 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/client"
+)
+
+// ErrChainStalled no new block was seen for the stall threshold of the HeadWatchdog
+var ErrChainStalled = errors.New("chain stalled")
+
+// HeadState the head of the chain as last seen by a HeadWatchdog
+type HeadState struct {
+	// Head the latest block number read, zero before the first successful read
+	Head uint64
+	// Since when the Head was first seen, zero while the node can not be read
+	Since time.Time
+	// Stalled no new block was seen for the stall threshold while the node could be read
+	Stalled bool
+	// Outage the latest read of the head failed or the client has no healthy endpoint
+	Outage bool
+}
+
+// HeadWatchdog polls the head of the chain and reports a stall once the node answers but no new block was seen
+// for the stall threshold. A node which can not be read, or a client whose endpoints are all unhealthy, is an
+// outage rather than a stall: the threshold only counts while the head is read successfully.
+type HeadWatchdog struct {
+	client    client.Client
+	threshold time.Duration
+	interval  time.Duration
+
+	mu    sync.Mutex
+	state HeadState
+	// stalled is closed when a stall starts, resumed when it ends, each is replaced once closed
+	stalled chan struct{}
+	resumed chan struct{}
+	closed  bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewHeadWatchdog utility method to create a HeadWatchdog reading the head every pollInterval, a quarter of the
+// threshold when zero. The watchdog has to be closed with Close.
+func NewHeadWatchdog(client client.Client, threshold time.Duration, pollInterval time.Duration) *HeadWatchdog {
+	if pollInterval <= 0 {
+		pollInterval = threshold / 4
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &HeadWatchdog{
+		client:    client,
+		threshold: threshold,
+		interval:  pollInterval,
+		stalled:   make(chan struct{}),
+		resumed:   make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	go w.run()
+	return w
+}
+
+// State returns the head as last seen, the zero state for a nil watchdog
+func (w *HeadWatchdog) State() HeadState {
+	if w == nil {
+		return HeadState{}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state
+}
+
+// Stalled returns a channel closed once the chain is stalled, right away when it already is.
+// The channel of a nil watchdog is never closed.
+func (w *HeadWatchdog) Stalled() <-chan struct{} {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stalled
+}
+
+// Resumed returns a channel closed once the blocks resume after a stall, right away when the chain is not stalled
+func (w *HeadWatchdog) Resumed() <-chan struct{} {
+	if w == nil {
+		return closedChannel()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.state.Stalled {
+		return closedChannel()
+	}
+	return w.resumed
+}
+
+func closedChannel() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+
+// Guard calls wait with a context cancelled once the chain stalls, returning ErrChainStalled instead of the error of
+// wait in that case. It returns ErrChainStalled without calling wait when the chain is already stalled.
+func (w *HeadWatchdog) Guard(ctx context.Context, wait func(ctx context.Context) error) error {
+	if w == nil {
+		return wait(ctx)
+	}
+	stalled := w.Stalled()
+	select {
+	case <-stalled:
+		return w.stallError()
+	default:
+	}
+
+	waitCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func() {
+		select {
+		case <-stalled:
+			cancel(ErrChainStalled)
+		case <-waitCtx.Done():
+		}
+	}()
+	err := wait(waitCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(context.Cause(waitCtx), ErrChainStalled) {
+		return w.stallError()
+	}
+	return err
+}
+
+// Close stops the polling
+func (w *HeadWatchdog) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		w.cancel()
+	}
+	return nil
+}
+
+func (w *HeadWatchdog) stallError() error {
+	state := w.State()
+	return fmt.Errorf("%w: no block after %d since %s", ErrChainStalled, state.Head, state.Since.Format(time.RFC3339))
+}
+
+func (w *HeadWatchdog) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.poll()
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads the head and updates the state, starting or ending a stall
+func (w *HeadWatchdog) poll() {
+	head, err := w.client.BlockNumber(w.ctx)
+	if w.ctx.Err() != nil {
+		return
+	}
+	healthy := client.Healthy(w.client)
+	now := time.Now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil || !healthy {
+		if err != nil {
+			log.Warn().Err(err).Msg("head watchdog failed to get block number")
+		}
+		// the chain may be producing blocks the node can not tell about, the stall is over until it answers
+		w.state.Outage = true
+		w.state.Since = time.Time{}
+		w.resume()
+		return
+	}
+
+	w.state.Outage = false
+	if head != w.state.Head || w.state.Since.IsZero() {
+		w.state.Head = head
+		w.state.Since = now
+		w.resume()
+		return
+	}
+	if !w.state.Stalled && now.Sub(w.state.Since) >= w.threshold {
+		log.Warn().Uint64("head", head).Time("since", w.state.Since).Msg("chain stalled")
+		w.state.Stalled = true
+		close(w.stalled)
+		w.resumed = make(chan struct{})
+	}
+}
+
+// resume ends the stall, once a new head was seen or the node can not be read anymore, the caller holds mu
+func (w *HeadWatchdog) resume() {
+	if !w.state.Stalled {
+		return
+	}
+	log.Info().Uint64("head", w.state.Head).Msg("chain resumed")
+	w.state.Stalled = false
+	close(w.resumed)
+	w.stalled = make(chan struct{})
+}
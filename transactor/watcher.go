@@ -0,0 +1,177 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+	"github.com/welthee/dobermann/client"
+)
+
+// ErrWatcherClosed the ReceiptWatcher was closed while waiting for the receipt
+var ErrWatcherClosed = errors.New("receipt watcher closed")
+
+// ReceiptWatcher is a ConfirmationStrategy shared by all the transactions waiting for a receipt.
+// A single loop polls the node at the given interval, once per distinct transaction hash, and
+// notifies every waiter of that hash, instead of each waiter polling on its own.
+type ReceiptWatcher struct {
+	client  client.Client
+	backoff ReceiptBackoff
+
+	mu      sync.Mutex
+	waiters map[common.Hash][]chan *types.Receipt
+	polls   map[common.Hash]*receiptPoll
+	closed  bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// receiptPoll the backoff of a transaction hash, counted in ticks of the initial interval
+type receiptPoll struct {
+	interval time.Duration
+	ticks    int
+}
+
+// NewReceiptWatcher utility method to create a ReceiptWatcher polling the node at the given interval,
+// 10 seconds when zero. The watcher has to be closed with Close.
+func NewReceiptWatcher(client client.Client, pollInterval time.Duration) *ReceiptWatcher {
+	return NewReceiptWatcherWithBackoff(client, ReceiptBackoff{Initial: pollInterval})
+}
+
+// NewReceiptWatcherWithBackoff utility method to create a ReceiptWatcher polling each transaction hash
+// at the intervals of the backoff, rounded to multiples of its initial interval. The watcher has to be
+// closed with Close.
+func NewReceiptWatcherWithBackoff(client client.Client, backoff ReceiptBackoff) *ReceiptWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &ReceiptWatcher{
+		client:  client,
+		backoff: backoff.withDefaults(),
+		waiters: make(map[common.Hash][]chan *types.Receipt),
+		polls:   make(map[common.Hash]*receiptPoll),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *ReceiptWatcher) WaitConfirmed(ctx context.Context, txHash string) (*types.Receipt, error) {
+	if txHash == "" {
+		return nil, errors.New("tx is empty")
+	}
+	hash := common.HexToHash(txHash)
+	ch := make(chan *types.Receipt, 1)
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil, ErrWatcherClosed
+	}
+	w.waiters[hash] = append(w.waiters[hash], ch)
+	if _, ok := w.polls[hash]; !ok {
+		w.polls[hash] = &receiptPoll{interval: w.backoff.Initial, ticks: 1}
+	}
+	w.mu.Unlock()
+
+	select {
+	case receipt := <-ch:
+		log.Ctx(ctx).Debug().Msgf("found transaction receipt for tx=%s: status=%d", txHash, receipt.Status)
+		return receipt, nil
+	case <-ctx.Done():
+		w.remove(hash, ch)
+		log.Ctx(ctx).Warn().Err(ctx.Err()).Str("tx", txHash).Msg("failed to get receipt status")
+		return nil, ctx.Err()
+	case <-w.done:
+		return nil, ErrWatcherClosed
+	}
+}
+
+// Close stops the polling, the pending waiters return ErrWatcherClosed
+func (w *ReceiptWatcher) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	w.cancel()
+	close(w.done)
+	return nil
+}
+
+func (w *ReceiptWatcher) run() {
+	ticker := time.NewTicker(w.backoff.Initial)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// poll fetches the receipt of every distinct hash waited for which is due and notifies its waiters,
+// the hashes without receipt are polled again after their next backoff interval
+func (w *ReceiptWatcher) poll() {
+	w.mu.Lock()
+	hashes := make([]common.Hash, 0, len(w.waiters))
+	for hash, poll := range w.polls {
+		poll.ticks--
+		if poll.ticks <= 0 {
+			hashes = append(hashes, hash)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, hash := range hashes {
+		receipt, err := w.client.TransactionReceipt(w.ctx, hash)
+		if receipt == nil {
+			if err != nil && w.ctx.Err() == nil {
+				log.Warn().Err(err).Str("tx", hash.Hex()).Msg("failed to get receipt for tx")
+			}
+			w.mu.Lock()
+			if poll, ok := w.polls[hash]; ok {
+				poll.interval = w.backoff.next(poll.interval)
+				poll.ticks = int((poll.interval + w.backoff.Initial/2) / w.backoff.Initial)
+			}
+			w.mu.Unlock()
+			continue
+		}
+
+		w.mu.Lock()
+		for _, ch := range w.waiters[hash] {
+			ch <- receipt
+		}
+		delete(w.waiters, hash)
+		delete(w.polls, hash)
+		w.mu.Unlock()
+	}
+}
+
+func (w *ReceiptWatcher) remove(hash common.Hash, ch chan *types.Receipt) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	waiters := w.waiters[hash]
+	for i, waiter := range waiters {
+		if waiter == ch {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(w.waiters, hash)
+		delete(w.polls, hash)
+		return
+	}
+	w.waiters[hash] = waiters
+}
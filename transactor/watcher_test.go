@@ -0,0 +1,149 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/welthee/dobermann/client"
+)
+
+// receiptClient a node mining each transaction at the given poll of its receipt, counting the polls
+type receiptClient struct {
+	client.Client
+	minedAt map[common.Hash]int
+
+	mu    sync.Mutex
+	polls map[common.Hash]int
+}
+
+func (c *receiptClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.polls[txHash]++
+	if minedAt, ok := c.minedAt[txHash]; ok && c.polls[txHash] >= minedAt {
+		return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful}, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *receiptClient) count(txHash common.Hash) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.polls[txHash]
+}
+
+func TestReceiptWatcher(t *testing.T) {
+	first, second := common.HexToHash("0x01"), common.HexToHash("0x02")
+	tests := []struct {
+		name string
+		// waiters the hash each waiter waits for
+		waiters []common.Hash
+		minedAt map[common.Hash]int
+		// timeout of the context of the waiters, none when zero
+		timeout time.Duration
+		// close the watcher once every hash was polled
+		close bool
+		want  error
+		// polls the exact number of polls of each hash, unchecked when nil
+		polls map[common.Hash]int
+	}{
+		{name: "shared hash", waiters: []common.Hash{first, first, first}, minedAt: map[common.Hash]int{first: 2},
+			polls: map[common.Hash]int{first: 2}},
+		{name: "distinct hashes", waiters: []common.Hash{first, second, second},
+			minedAt: map[common.Hash]int{first: 1, second: 3}, polls: map[common.Hash]int{first: 1, second: 3}},
+		{name: "cancelled waiters", waiters: []common.Hash{first, first}, timeout: 50 * time.Millisecond,
+			want: context.DeadlineExceeded},
+		{name: "closed watcher", waiters: []common.Hash{first, second}, close: true, want: ErrWatcherClosed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := &receiptClient{minedAt: test.minedAt, polls: make(map[common.Hash]int)}
+			// the waiters are all registered well before the first poll
+			w := NewReceiptWatcher(node, 10*time.Millisecond)
+			defer w.Close()
+
+			errs := make(chan error, len(test.waiters))
+			for _, hash := range test.waiters {
+				go func(hash common.Hash) {
+					ctx := context.Background()
+					if test.timeout > 0 {
+						var cancel context.CancelFunc
+						ctx, cancel = context.WithTimeout(ctx, test.timeout)
+						defer cancel()
+					}
+					receipt, err := w.WaitConfirmed(ctx, hash.Hex())
+					if err == nil && receipt.TxHash != hash {
+						err = errors.New("receipt of another transaction")
+					}
+					errs <- err
+				}(hash)
+			}
+			if test.close {
+				for _, hash := range test.waiters {
+					for node.count(hash) == 0 {
+						time.Sleep(time.Millisecond)
+					}
+				}
+				w.Close()
+			}
+			for range test.waiters {
+				if err := <-errs; !errors.Is(err, test.want) || (test.want == nil && err != nil) {
+					t.Fatalf("error %v, want %v", err, test.want)
+				}
+			}
+
+			for hash, want := range test.polls {
+				if got := node.count(hash); got != want {
+					t.Fatalf("%d polls of %s, want %d", got, hash.Hex(), want)
+				}
+			}
+			// the hashes no longer waited for are not polled anymore
+			w.mu.Lock()
+			waiting := len(w.waiters) + len(w.polls)
+			w.mu.Unlock()
+			if waiting != 0 && !test.close {
+				t.Fatalf("%d hashes still polled after their waiters returned", waiting)
+			}
+			if test.close {
+				if _, err := w.WaitConfirmed(context.Background(), first.Hex()); !errors.Is(err, ErrWatcherClosed) {
+					t.Fatalf("error %v after closing, want %v", err, ErrWatcherClosed)
+				}
+			}
+		})
+	}
+}
+
+func TestReceiptBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff ReceiptBackoff
+		// intervals the first intervals following the initial one
+		intervals []time.Duration
+	}{
+		{name: "defaults", intervals: []time.Duration{10 * time.Second, 10 * time.Second}},
+		{name: "constant", backoff: ReceiptBackoff{Initial: time.Second},
+			intervals: []time.Duration{time.Second, time.Second}},
+		{name: "doubling up to the max", backoff: ReceiptBackoff{Initial: time.Second, Multiplier: 2, Max: 5 * time.Second},
+			intervals: []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}},
+		{name: "max below the initial interval", backoff: ReceiptBackoff{Initial: time.Second, Multiplier: 2, Max: time.Millisecond},
+			intervals: []time.Duration{time.Second}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backoff := test.backoff.withDefaults()
+			interval := backoff.Initial
+			for i, want := range test.intervals {
+				interval = backoff.next(interval)
+				if interval != want {
+					t.Fatalf("interval %d %s, want %s", i+1, interval, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package dobermann
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrInvalidUnits the value is not a decimal number with at most the given decimals
+var ErrInvalidUnits = errors.New("invalid units")
+
+// ParseUnits converts the decimal string, e.g. "1.5", to the integer amount with the given decimals,
+// e.g. ParseUnits("1.5", 18) returns 1500000000000000000. Values with more decimals are rejected
+// instead of being rounded. Negative values keep their sign, callers expecting amounts have to reject them.
+func ParseUnits(human string, decimals int) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("%w: negative decimals %d", ErrInvalidUnits, decimals)
+	}
+	value := strings.TrimSpace(human)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	whole, fraction, hasFraction := strings.Cut(value, ".")
+	if whole == "" || (hasFraction && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidUnits, human)
+	}
+	// trailing zeros do not change the value, e.g. "1.50" with 1 decimal
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > decimals {
+		return nil, fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidUnits, human, decimals)
+	}
+
+	amount, _ := new(big.Int).SetString(whole+fraction+strings.Repeat("0", decimals-len(fraction)), 10)
+	if negative {
+		amount.Neg(amount)
+	}
+	return amount, nil
+}
+
+// FormatUnits converts the integer amount to a decimal string with the given decimals, the reverse of
+// ParseUnits, e.g. FormatUnits(1500000000000000000, 18) returns "1.5". The result is exact, with at least one
+// decimal and no trailing zeros, and negative amounts are prefixed with "-". A nil amount is formatted as zero.
+func FormatUnits(wei *big.Int, decimals int) string {
+	if wei == nil {
+		wei = new(big.Int)
+	}
+	if decimals <= 0 {
+		return wei.String()
+	}
+
+	digits := new(big.Int).Abs(wei).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole := digits[:len(digits)-decimals]
+	fraction := strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fraction == "" {
+		fraction = "0"
+	}
+
+	sign := ""
+	if wei.Sign() < 0 {
+		sign = "-"
+	}
+	return sign + whole + "." + fraction
+}
+
+// parseAmount parses the wei amount of a SourceAccount, negative amounts and amounts over uint256 are rejected
+func parseAmount(amount string) (*big.Int, error) {
+	wei, err := ParseUnits(amount, 0)
+	if err != nil || wei.Sign() < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	if wei.BitLen() > 256 {
+		return nil, fmt.Errorf("%w: %q", ErrAmountOverflow, amount)
+	}
+	return wei, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package dobermann
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Wei is a big.Int marshaled to JSON as a decimal string, e.g. "1500000000000000000", so that consumers
+// parsing JSON numbers as float64, like JavaScript, do not lose precision. All the big.Int values written
+// to JSON by this package, e.g. in the RunReport, the FeeQuote and the LedgerEntry, use it.
+// A nil Int is marshaled as null.
+type Wei struct {
+	*big.Int
+}
+
+// NewWei wraps the value, which is not copied
+func NewWei(value *big.Int) Wei {
+	return Wei{Int: value}
+}
+
+// parseWei returns the decimal string as Wei, nil when it is empty or not a valid amount
+func parseWei(value string) *Wei {
+	amount, err := parseAmount(value)
+	if err != nil {
+		return nil
+	}
+	return &Wei{Int: amount}
+}
+
+func (w Wei) MarshalJSON() ([]byte, error) {
+	if w.Int == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(w.Int.String())
+}
+
+// UnmarshalJSON accepts only decimal strings and null, JSON numbers are rejected
+// as they may already have lost precision
+func (w *Wei) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		w.Int = nil
+		return nil
+	}
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return fmt.Errorf("wei must be a decimal string: %w", err)
+	}
+	amount, err := ParseUnits(value, 0)
+	if err != nil {
+		return err
+	}
+	w.Int = amount
+	return nil
+}
+
+func (w Wei) String() string {
+	if w.Int == nil {
+		return "<nil>"
+	}
+	return w.Int.String()
+}